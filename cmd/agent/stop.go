@@ -4,11 +4,9 @@ import (
 	"bufio"
 	"context"
 	"errors"
-	"fmt"
 	"os"
 	"os/signal"
 	"strings"
-	"sync"
 	"syscall"
 
 	"github.com/alecthomas/kong"
@@ -112,116 +110,26 @@ func (c *StopCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 		return errors.New("must supply agents to stop")
 	}
 
-	writer := os.Stdout
-	isTTY := isatty.IsTerminal(writer.Fd())
-
 	total := len(agentIDs)
 	label := "Stopping agents"
 	if total == 1 {
 		label = "Stopping agent"
 	}
 
-	workerCount := int(min(limit, int64(total)))
-
-	work := make(chan string, workerCount)
-	updates := make(chan stopResult, workerCount)
-
-	var wg sync.WaitGroup
-	for i := 0; i < workerCount; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for agentID := range work {
-				if ctx.Err() != nil {
-					updates <- stopResult{id: agentID, err: ctx.Err()}
-					continue
-				}
-				updates <- stopAgent(ctx, agentID, f, c.Force)
-			}
-		}()
-	}
-
-	go func() {
-		for _, id := range agentIDs {
-			select {
-			case <-ctx.Done():
-				close(work)
-				return
-			case work <- id:
-			}
-		}
-		close(work)
-	}()
-
-	go func() {
-		wg.Wait()
-		close(updates)
-	}()
-
-	succeeded := 0
-	failed := 0
-	completed := 0
-	var errorDetails []string
-
-	if !f.Quiet {
-		line := bkIO.ProgressLine(label, completed, total, succeeded, failed, 24)
-		if isTTY {
-			fmt.Fprint(writer, line)
-		} else {
-			fmt.Fprintln(writer, line)
-		}
-	}
-
-	for update := range updates {
-		completed++
-		if update.err != nil {
-			failed++
-			errorDetails = append(errorDetails, fmt.Sprintf("FAILED %s: %v", update.id, update.err))
-		} else {
-			succeeded++
-		}
-
-		if !f.Quiet {
-			line := bkIO.ProgressLine(label, completed, total, succeeded, failed, 24)
-			if isTTY {
-				fmt.Fprintf(writer, "\r%s", line)
-			} else {
-				fmt.Fprintln(writer, line)
-			}
-		}
-	}
-
-	if !f.Quiet && isTTY {
-		fmt.Fprintln(writer)
-	}
-
-	summaryWriter := writer
-	if failed > 0 {
-		summaryWriter = os.Stderr
-	}
-
-	if len(errorDetails) > 0 {
-		fmt.Fprintln(summaryWriter)
-		for _, detail := range errorDetails {
-			fmt.Fprintln(summaryWriter, detail)
-		}
-	}
-
-	if !f.Quiet {
-		agentLabel := pluralize("agent", total)
-		failedLabel := pluralize("agent", failed)
-		if failed > 0 {
-			fmt.Fprintf(summaryWriter, "\nStopped %d of %d %s (%d %s failed)\n", succeeded, total, agentLabel, failed, failedLabel)
-		} else {
-			fmt.Fprintf(summaryWriter, "\nSuccessfully stopped %d of %d %s\n", succeeded, total, agentLabel)
-		}
-	}
-
-	if failed > 0 {
-		return fmt.Errorf("failed to stop %d of %d %s (see above for details)", failed, total, pluralize("agent", total))
-	}
-
-	return nil
+	_, err = bkIO.RunBatch(ctx, bkIO.Batch{
+		Label:  label,
+		Noun:   "agent",
+		Verb:   "stop",
+		Done:   "stopped",
+		Limit:  int(limit),
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+		TTY:    isatty.IsTerminal(os.Stdout.Fd()),
+		Quiet:  f.Quiet,
+	}, agentIDs, func(id string) string { return id }, func(ctx context.Context, id string) error {
+		return stopAgent(ctx, id, f, c.Force).err
+	})
+	return err
 }
 
 type stopResult struct {
@@ -229,13 +137,6 @@ type stopResult struct {
 	err error
 }
 
-func pluralize(word string, count int) string {
-	if count == 1 {
-		return word
-	}
-	return word + "s"
-}
-
 func stopAgent(ctx context.Context, id string, f *factory.Factory, force bool) stopResult {
 	org, agentID := parseAgentArg(id, f.Config)
 	_, err := f.RestAPIClient.Agents.Stop(ctx, org, agentID, force)
@@ -0,0 +1,214 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/artifact"
+	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
+	"github.com/buildkite/cli/v3/internal/build/resolver/options"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkErrors "github.com/buildkite/cli/v3/internal/errors"
+	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+type CatCmd struct {
+	ArtifactID  string `arg:"" optional:"" help:"Artifact ID to print. Use 'bk artifacts list' to find IDs, or --path to select artifacts by path instead."`
+	BuildNumber string `help:"Build number containing the artifact. If omitted, the most recent build on the current branch will be used." short:"b" name:"build"`
+	Pipeline    string `help:"The pipeline containing the artifact. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}. If omitted, it will be resolved using the current directory." short:"p"`
+	JobUUID     string `help:"The job UUID containing the artifact." short:"j" name:"job-uuid"`
+	Path        string `help:"Print the artifacts matching this path. Supports exact matches and glob patterns using * as a wildcard, e.g. --path \"log/rspec*.json\"."`
+	Decompress  bool   `help:"Decompress gzip and zstd content, and print the files in tar archives." short:"d"`
+	Member      string `help:"Print only the members of a tar archive matching this glob, e.g. --member \"*/junit.xml\". Implies --decompress."`
+}
+
+func (c *CatCmd) Help() string {
+	return `
+Print the content of artifacts to standard output without saving them.
+
+Select a single artifact by ID, or any number by --path. When more than one
+artifact (or tar member) is printed, each is preceded by a "==> name <=="
+separator.
+
+Examples:
+  # Print an artifact by ID
+  $ bk artifacts cat 0191727d-b5ce-4576-b37d-477ae0ca830c --build 429
+
+  # Query a JSON report with jq
+  $ bk artifacts cat --build 429 --path coverage/summary.json | jq .total
+
+  # Print a gzipped log, decompressed
+  $ bk artifacts cat --build 429 --path "log/*.log.gz" -d
+
+  # Print one file from a .tar.zst artifact
+  $ bk artifacts cat --build 429 --path dist/reports.tar.zst --member "*/junit.xml"
+`
+}
+
+// validate checks flag combinations that can be rejected without any API
+// calls.
+func (c *CatCmd) validate() error {
+	switch {
+	case c.ArtifactID == "" && c.Path == "":
+		return bkErrors.NewValidationError(nil, "an artifact ID or --path is required", "Use 'bk artifacts list' to find artifact IDs and paths.")
+	case c.ArtifactID != "" && c.Path != "":
+		return bkErrors.NewValidationError(nil, "--path cannot be used when printing a specific artifact by ID", "Omit the artifact ID to select artifacts by path.")
+	}
+	return nil
+}
+
+func (c *CatCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	pipelineRes := pipelineResolver.NewAggregateResolver(
+		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
+		pipelineResolver.ResolveFromConfig(f.Config, pipelineResolver.PickOneWithFactory(f)),
+		pipelineResolver.ResolveFromRepository(f, pipelineResolver.CachedPicker(f.Config, pipelineResolver.PickOneWithFactory(f))),
+	)
+
+	optionsResolver := options.AggregateResolver{
+		options.ResolveBranchFromFlag(""),
+		options.ResolveBranchFromRepository(f.GitRepository),
+	}
+
+	var buildResolvers []buildResolver.BuildResolverFn
+	if c.BuildNumber != "" {
+		buildResolvers = append(buildResolvers, buildResolver.ResolveFromPositionalArgument([]string{c.BuildNumber}, 0, pipelineRes.Resolve, f.Config))
+	}
+	buildResolvers = append(buildResolvers, buildResolver.ResolveBuildWithOpts(f, pipelineRes.Resolve, optionsResolver...))
+
+	buildRes := buildResolver.NewAggregateResolver(buildResolvers...)
+
+	ctx := context.Background()
+	bld, err := buildRes.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	if bld == nil {
+		return bkErrors.NewResourceNotFoundError(nil, "no build found")
+	}
+
+	build := strconv.Itoa(bld.BuildNumber)
+
+	var artifacts []buildkite.Artifact
+	if c.ArtifactID != "" {
+		a, err := findArtifact(ctx, f, bld.Organization, bld.Pipeline, build, c.ArtifactID, c.JobUUID)
+		if err != nil {
+			return err
+		}
+		artifacts = []buildkite.Artifact{*a}
+	} else {
+		artifacts, err = artifact.List(ctx, f.RestAPIClient, bld.Organization, bld.Pipeline, build, c.JobUUID, c.Path, "")
+		if err != nil {
+			return err
+		}
+		if len(artifacts) == 0 {
+			return bkErrors.NewResourceNotFoundError(nil, fmt.Sprintf("no artifacts found matching path '%s' in build #%s", c.Path, build))
+		}
+	}
+
+	return c.catArtifacts(ctx, f, artifacts, os.Stdout)
+}
+
+// catArtifacts writes the content of each artifact to w, with a separator
+// before each one when there is more than one.
+func (c *CatCmd) catArtifacts(ctx context.Context, f *factory.Factory, artifacts []buildkite.Artifact, w io.Writer) error {
+	sep := &separator{w: w, enabled: len(artifacts) > 1}
+	for _, a := range artifacts {
+		if err := c.catArtifact(ctx, f, a, w, sep); err != nil {
+			return fmt.Errorf("printing %s: %w", a.Path, err)
+		}
+	}
+	return nil
+}
+
+func (c *CatCmd) catArtifact(ctx context.Context, f *factory.Factory, a buildkite.Artifact, w io.Writer, sep *separator) error {
+	body := artifact.Open(ctx, f.RestAPIClient, a.DownloadURL)
+	defer body.Close()
+
+	if !c.Decompress && c.Member == "" {
+		if err := sep.write(a.Path); err != nil {
+			return err
+		}
+		_, err := io.Copy(w, body)
+		return err
+	}
+
+	content, err := artifact.Decompress(body)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	isTar, r, err := artifact.IsTar(content)
+	if err != nil {
+		return err
+	}
+	if !isTar {
+		if c.Member != "" {
+			return fmt.Errorf("--member was given, but the artifact is not a tar archive")
+		}
+		if err := sep.write(a.Path); err != nil {
+			return err
+		}
+		_, err := io.Copy(w, r)
+		return err
+	}
+
+	// Members are always labelled unless only one was asked for by name.
+	sep.enabled = sep.enabled || c.Member == "" || strings.ContainsAny(c.Member, `*?[\`)
+	n, err := artifact.WriteTarMembers(w, r, c.Member, func(name string) error {
+		return sep.write(a.Path + ":" + name)
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 && c.Member != "" {
+		return fmt.Errorf("no tar members match %q", c.Member)
+	}
+	return nil
+}
+
+// separator writes "==> name <==" headers between pieces of output, like
+// head and tail do for multiple files.
+type separator struct {
+	w       io.Writer
+	enabled bool
+	written bool
+}
+
+func (s *separator) write(name string) error {
+	if !s.enabled {
+		return nil
+	}
+	prefix := ""
+	if s.written {
+		prefix = "\n"
+	}
+	s.written = true
+	_, err := fmt.Fprintf(s.w, "%s==> %s <==\n", prefix, name)
+	return err
+}
@@ -0,0 +1,89 @@
+package artifacts
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	bkErrors "github.com/buildkite/cli/v3/internal/errors"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestCatCmdValidate(t *testing.T) {
+	t.Parallel()
+
+	if err := (&CatCmd{}).validate(); !errors.Is(err, bkErrors.ErrValidation) {
+		t.Errorf("expected an artifact ID or --path to be required, got %v", err)
+	}
+	if err := (&CatCmd{ArtifactID: "a", Path: "*.log"}).validate(); !errors.Is(err, bkErrors.ErrValidation) {
+		t.Errorf("expected an artifact ID and --path to be rejected, got %v", err)
+	}
+	if err := (&CatCmd{Path: "*.log"}).validate(); err != nil {
+		t.Errorf("expected --path alone to be accepted, got %v", err)
+	}
+}
+
+func TestCatArtifacts(t *testing.T) {
+	t.Parallel()
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	tw := tar.NewWriter(gw)
+	for _, m := range []struct{ name, body string }{{"out/a.txt", "member a\n"}, {"out/b.txt", "member b\n"}} {
+		_ = tw.WriteHeader(&tar.Header{Name: m.name, Mode: 0o644, Size: int64(len(m.body)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(m.body))
+	}
+	_ = tw.Close()
+	_ = gw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/one":
+			_, _ = w.Write([]byte("first\n"))
+		case "/two":
+			_, _ = w.Write([]byte("second\n"))
+		case "/bundle":
+			_, _ = w.Write(gz.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	f := newArtifactsTestFactory(t, server.URL)
+
+	one := buildkite.Artifact{Path: "one.txt", DownloadURL: server.URL + "/one"}
+	two := buildkite.Artifact{Path: "two.txt", DownloadURL: server.URL + "/two"}
+	bundle := buildkite.Artifact{Path: "bundle.tar.gz", DownloadURL: server.URL + "/bundle"}
+
+	tests := []struct {
+		name      string
+		cmd       CatCmd
+		artifacts []buildkite.Artifact
+		want      string
+	}{
+		{"single artifact has no separator", CatCmd{}, []buildkite.Artifact{one}, "first\n"},
+		{"several artifacts are separated", CatCmd{}, []buildkite.Artifact{one, two}, "==> one.txt <==\nfirst\n\n==> two.txt <==\nsecond\n"},
+		{"tar members are separated", CatCmd{Decompress: true}, []buildkite.Artifact{bundle}, "==> bundle.tar.gz:out/a.txt <==\nmember a\n\n==> bundle.tar.gz:out/b.txt <==\nmember b\n"},
+		{"a named member is printed alone", CatCmd{Member: "out/b.txt"}, []buildkite.Artifact{bundle}, "member b\n"},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		if err := tt.cmd.catArtifacts(context.Background(), f, tt.artifacts, &out); err != nil {
+			t.Fatalf("%s: catArtifacts() error = %v", tt.name, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, out.String(), tt.want)
+		}
+	}
+
+	cmd := CatCmd{Member: "out/a.txt"}
+	if err := cmd.catArtifacts(context.Background(), f, []buildkite.Artifact{one}, &strings.Builder{}); err == nil || !strings.Contains(err.Error(), "not a tar archive") {
+		t.Errorf("expected --member on a plain artifact to fail, got %v", err)
+	}
+}
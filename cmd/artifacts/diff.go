@@ -0,0 +1,230 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/artifact"
+	"github.com/buildkite/cli/v3/internal/build"
+	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	"github.com/buildkite/cli/v3/pkg/output"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+// maxContentDiffSize is the largest artifact whose content --content will
+// download and compare.
+const maxContentDiffSize = 1 << 20
+
+type DiffCmd struct {
+	BuildA   string `arg:"" help:"The build to compare from. This can be a build number, {pipeline slug}/{build number}, or a build URL."`
+	BuildB   string `arg:"" help:"The build to compare to."`
+	Pipeline string `help:"The pipeline containing the builds. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}. If omitted, it will be resolved using the current directory." short:"p"`
+	Path     string `help:"Only compare artifacts matching this path. Supports exact matches and glob patterns using * as a wildcard, e.g. --path \"coverage/*\"."`
+	Content  bool   `help:"Download changed text artifacts (up to 1MB) and show a unified diff of their content."`
+	output.OutputFlags
+}
+
+func (c *DiffCmd) Help() string {
+	return `
+Compare the artifacts of two builds.
+
+Artifacts are matched by path and reported as added, removed, or changed.
+An artifact has changed when its SHA-1 checksum differs, or its size when a
+checksum isn't available.
+
+Examples:
+  # Compare the artifacts of two builds
+  $ bk artifacts diff 428 429
+
+  # Compare builds of a specific pipeline
+  $ bk artifacts diff 428 429 -p monolith
+
+  # Show what changed in the coverage reports
+  $ bk artifacts diff 428 429 --path "coverage/*" --content
+
+  # Output the comparison as JSON
+  $ bk artifacts diff 428 429 -o json
+`
+}
+
+func (c *DiffCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+	f.NoPager = f.NoPager || globals.DisablePager()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	format := output.ResolveFormat(c.Output, f.Config.OutputFormat())
+
+	pipelineRes := pipelineResolver.NewAggregateResolver(
+		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
+		pipelineResolver.ResolveFromConfig(f.Config, pipelineResolver.PickOneWithFactory(f)),
+		pipelineResolver.ResolveFromRepository(f, pipelineResolver.CachedPicker(f.Config, pipelineResolver.PickOneWithFactory(f))),
+	)
+
+	ctx := context.Background()
+	args := []string{c.BuildA, c.BuildB}
+	var builds [2]*build.Build
+	for i := range builds {
+		builds[i], err = buildResolver.ResolveFromPositionalArgument(args, i, pipelineRes.Resolve, f.Config)(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	var diff artifact.Diff
+	var skipped map[string]string
+	if err = bkIO.SpinWhile(f, "Comparing artifacts", func() error {
+		diff, skipped, err = c.compare(ctx, f, builds[0], builds[1])
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if format != output.FormatText {
+		return output.Write(os.Stdout, diff, format)
+	}
+
+	writer, cleanup := bkIO.Pager(f.NoPager, f.Config.Pager())
+	defer func() { _ = cleanup() }()
+
+	fmt.Fprintf(writer, "Comparing artifacts of %s and %s\n\n", builds[0].Label(nil), builds[1].Label(builds[0]))
+	writeArtifactDiff(writer, diff, skipped)
+	return nil
+}
+
+// compare lists the artifacts of both builds and compares them, diffing the
+// content of changed artifacts when --content is set. skipped holds the
+// reason a changed artifact's content wasn't diffed, by path.
+func (c *DiffCmd) compare(ctx context.Context, f *factory.Factory, a, b *build.Build) (artifact.Diff, map[string]string, error) {
+	from, err := artifact.List(ctx, f.RestAPIClient, a.Organization, a.Pipeline, strconv.Itoa(a.BuildNumber), "", c.Path, "")
+	if err != nil {
+		return artifact.Diff{}, nil, fmt.Errorf("listing artifacts of build #%d: %w", a.BuildNumber, err)
+	}
+	to, err := artifact.List(ctx, f.RestAPIClient, b.Organization, b.Pipeline, strconv.Itoa(b.BuildNumber), "", c.Path, "")
+	if err != nil {
+		return artifact.Diff{}, nil, fmt.Errorf("listing artifacts of build #%d: %w", b.BuildNumber, err)
+	}
+
+	diff := artifact.Compare(from, to)
+	if !c.Content || len(diff.Changed) == 0 {
+		return diff, nil, nil
+	}
+
+	dir, err := os.MkdirTemp("", "bk-artifacts-diff-")
+	if err != nil {
+		return diff, nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	skipped := make(map[string]string)
+	for i := range diff.Changed {
+		ch := &diff.Changed[i]
+		text, reason, err := diffContent(ctx, f, *ch, dir, i)
+		if err != nil {
+			return diff, nil, fmt.Errorf("comparing %s: %w", ch.Path, err)
+		}
+		if reason != "" {
+			skipped[ch.Path] = reason
+		}
+		ch.Diff = text
+	}
+	return diff, skipped, nil
+}
+
+// diffContent downloads both versions of a changed artifact into dir and
+// returns a unified diff of them, or the reason it couldn't.
+func diffContent(ctx context.Context, f *factory.Factory, ch artifact.Change, dir string, index int) (string, string, error) {
+	versions := []*buildkite.Artifact{ch.Old, ch.New}
+	for _, a := range versions {
+		if a.State != "" && a.State != "finished" {
+			return "", "not finished uploading", nil
+		}
+		if a.FileSize > maxContentDiffSize {
+			return "", "too large to compare", nil
+		}
+	}
+
+	var content [2][]byte
+	for i, a := range versions {
+		dest := filepath.Join(dir, fmt.Sprintf("%d-%d", index, i))
+		if err := artifact.DownloadToFile(ctx, f.RestAPIClient, a.DownloadURL, dest); err != nil {
+			return "", "", err
+		}
+		data, err := os.ReadFile(dest)
+		if err != nil {
+			return "", "", err
+		}
+		if !artifact.IsText(data) {
+			return "", "binary content", nil
+		}
+		content[i] = data
+	}
+
+	return artifact.UnifiedDiff("a/"+ch.Path, "b/"+ch.Path, string(content[0]), string(content[1])), "", nil
+}
+
+func writeArtifactDiff(w io.Writer, diff artifact.Diff, skipped map[string]string) {
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed) == 0 {
+		fmt.Fprintf(w, "No differences (%d artifacts unchanged).\n", diff.Unchanged)
+		return
+	}
+
+	if len(diff.Added) > 0 {
+		fmt.Fprintf(w, "Added (%d):\n", len(diff.Added))
+		for _, ch := range diff.Added {
+			fmt.Fprintf(w, "  + %s (%s)\n", ch.Path, artifact.FormatBytes(ch.New.FileSize))
+		}
+	}
+	if len(diff.Removed) > 0 {
+		fmt.Fprintf(w, "Removed (%d):\n", len(diff.Removed))
+		for _, ch := range diff.Removed {
+			fmt.Fprintf(w, "  - %s (%s)\n", ch.Path, artifact.FormatBytes(ch.Old.FileSize))
+		}
+	}
+	if len(diff.Changed) > 0 {
+		fmt.Fprintf(w, "Changed (%d):\n", len(diff.Changed))
+		for _, ch := range diff.Changed {
+			fmt.Fprintf(w, "  ~ %s (%s -> %s", ch.Path, artifact.FormatBytes(ch.Old.FileSize), artifact.FormatBytes(ch.New.FileSize))
+			if ch.Old.SHA1 != "" && ch.New.SHA1 != "" {
+				fmt.Fprintf(w, ", sha1 %s -> %s", shortSHA1(ch.Old.SHA1), shortSHA1(ch.New.SHA1))
+			}
+			fmt.Fprintln(w, ")")
+		}
+	}
+	fmt.Fprintf(w, "Unchanged: %d\n", diff.Unchanged)
+
+	for _, ch := range diff.Changed {
+		switch {
+		case ch.Diff != "":
+			fmt.Fprintf(w, "\n%s", ch.Diff)
+		case skipped[ch.Path] != "":
+			fmt.Fprintf(w, "\nContent of %s not compared: %s\n", ch.Path, skipped[ch.Path])
+		}
+	}
+}
+
+func shortSHA1(sha1 string) string {
+	if len(sha1) > 7 {
+		return sha1[:7]
+	}
+	return sha1
+}
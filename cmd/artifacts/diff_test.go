@@ -0,0 +1,87 @@
+package artifacts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/cli/v3/internal/artifact"
+	"github.com/buildkite/cli/v3/internal/build"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestDiffCmdCompare(t *testing.T) {
+	t.Parallel()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/organizations/acme/pipelines/app/builds/1/artifacts":
+			writeArtifactsPage(t, w, []buildkite.Artifact{
+				{Path: "report.txt", SHA1: "a1", FileSize: 4, State: "finished", DownloadURL: server.URL + "/content/old-report"},
+				{Path: "image.png", SHA1: "b1", FileSize: 3, State: "finished", DownloadURL: server.URL + "/content/old-image"},
+				{Path: "gone.txt", SHA1: "c1", FileSize: 1, State: "finished"},
+			}, "")
+		case "/v2/organizations/acme/pipelines/app/builds/2/artifacts":
+			writeArtifactsPage(t, w, []buildkite.Artifact{
+				{Path: "report.txt", SHA1: "a2", FileSize: 4, State: "finished", DownloadURL: server.URL + "/content/new-report"},
+				{Path: "image.png", SHA1: "b2", FileSize: 3, State: "finished", DownloadURL: server.URL + "/content/new-image"},
+				{Path: "new.txt", SHA1: "d1", FileSize: 2, State: "finished"},
+			}, "")
+		case "/content/old-report":
+			_, _ = w.Write([]byte("ok\n"))
+		case "/content/new-report":
+			_, _ = w.Write([]byte("no\n"))
+		case "/content/old-image", "/content/new-image":
+			_, _ = w.Write([]byte{0x89, 0, 1})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	f := newArtifactsTestFactory(t, server.URL)
+
+	cmd := DiffCmd{Content: true}
+	a := &build.Build{Organization: "acme", Pipeline: "app", BuildNumber: 1}
+	b := &build.Build{Organization: "acme", Pipeline: "app", BuildNumber: 2}
+	diff, skipped, err := cmd.compare(context.Background(), f, a, b)
+	if err != nil {
+		t.Fatalf("compare() error = %v", err)
+	}
+
+	var out strings.Builder
+	writeArtifactDiff(&out, diff, skipped)
+
+	want := `Added (1):
+  + new.txt (2B)
+Removed (1):
+  - gone.txt (1B)
+Changed (2):
+  ~ image.png (3B -> 3B, sha1 b1 -> b2)
+  ~ report.txt (4B -> 4B, sha1 a1 -> a2)
+Unchanged: 0
+
+Content of image.png not compared: binary content
+
+--- a/report.txt
++++ b/report.txt
+@@ -1 +1 @@
+-ok
++no
+`
+	if out.String() != want {
+		t.Errorf("writeArtifactDiff() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestWriteArtifactDiffNoDifferences(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	writeArtifactDiff(&out, artifact.Diff{Unchanged: 3}, nil)
+	if got, want := out.String(), "No differences (3 artifacts unchanged).\n"; got != want {
+		t.Errorf("writeArtifactDiff() = %q, want %q", got, want)
+	}
+}
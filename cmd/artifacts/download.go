@@ -56,6 +56,10 @@ Examples:
   # Filter artifacts to download by path or state
   $ bk artifacts download --build 429 --path "log/rspec*.json"
   $ bk artifacts download --build 429 --state finished
+
+Downloads are checked against the SHA-1 checksum Buildkite records for each
+artifact. Files that already match are skipped, and an interrupted download
+resumes where it left off when the command is run again.
 `
 }
 
@@ -132,6 +136,7 @@ func (c *DownloadCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error
 
 func (c *DownloadCmd) downloadOne(ctx context.Context, f *factory.Factory, org, pipeline, build string) error {
 	var filename string
+	var outcome artifact.DownloadOutcome
 
 	if err := bkIO.SpinWhile(f, "Downloading artifact", func() error {
 		a, findErr := findArtifact(ctx, f, org, pipeline, build, c.ArtifactID, c.JobUUID)
@@ -139,13 +144,13 @@ func (c *DownloadCmd) downloadOne(ctx context.Context, f *factory.Factory, org,
 			return findErr
 		}
 		var dlErr error
-		filename, dlErr = downloadArtifact(ctx, f, a)
+		filename, outcome, dlErr = downloadArtifact(ctx, f, a)
 		return dlErr
 	}); err != nil {
 		return err
 	}
 
-	fmt.Printf("Downloaded: %s\n", filename)
+	writeDownloadOutcome(os.Stdout, filename, outcome)
 	return nil
 }
 
@@ -170,18 +175,35 @@ func (c *DownloadCmd) downloadAll(ctx context.Context, f *factory.Factory, org,
 		return err
 	}
 
-	for i := range artifacts {
-		a := &artifacts[i]
+	// Keep going after a failure so one bad artifact doesn't stop the rest;
+	// rerunning resumes partial downloads and skips completed ones.
+	var report artifact.DownloadReport
+	for _, a := range artifacts {
 		destPath := filepath.Join(directory, filepath.FromSlash(a.Path))
-		if err := artifact.DownloadToFile(ctx, f.RestAPIClient, a.DownloadURL, destPath); err != nil {
-			return err
+		outcome, err := artifact.Download(ctx, f.RestAPIClient, a, destPath)
+		report.Add(a.Path, outcome, err)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed: %s: %v\n", a.Path, err)
+			continue
 		}
-
-		fmt.Printf("Downloaded: %s\n", a.Path)
+		writeDownloadOutcome(os.Stdout, a.Path, outcome)
 	}
 
-	fmt.Printf("Downloaded %d artifacts to: %s\n", len(artifacts), directory)
-	return nil
+	fmt.Printf("Downloaded %d artifacts to: %s\n", len(artifacts)-len(report.Failed), directory)
+	report.Write(os.Stdout)
+	return report.Err()
+}
+
+// writeDownloadOutcome reports what happened to a single artifact.
+func writeDownloadOutcome(w io.Writer, path string, outcome artifact.DownloadOutcome) {
+	switch outcome {
+	case artifact.Skipped:
+		fmt.Fprintf(w, "Skipped: %s (already up to date)\n", path)
+	case artifact.Verified:
+		fmt.Fprintf(w, "Downloaded: %s (checksum verified)\n", path)
+	default:
+		fmt.Fprintf(w, "Downloaded: %s\n", path)
+	}
 }
 
 func findArtifact(ctx context.Context, f *factory.Factory, org, pipeline, build, artifactID, jobUUID string) (*buildkite.Artifact, error) {
@@ -207,12 +229,13 @@ func findArtifact(ctx context.Context, f *factory.Factory, org, pipeline, build,
 	return nil, bkErrors.NewResourceNotFoundError(nil, fmt.Sprintf("no artifact found with ID %s in build #%s", artifactID, build))
 }
 
-func downloadArtifact(ctx context.Context, f *factory.Factory, a *buildkite.Artifact) (string, error) {
+func downloadArtifact(ctx context.Context, f *factory.Factory, a *buildkite.Artifact) (string, artifact.DownloadOutcome, error) {
 	destPath := filepath.FromSlash(a.Path)
-	if err := artifact.DownloadToFile(ctx, f.RestAPIClient, a.DownloadURL, destPath); err != nil {
-		return "", err
+	outcome, err := artifact.Download(ctx, f.RestAPIClient, *a, destPath)
+	if err != nil {
+		return "", outcome, err
 	}
-	return destPath, nil
+	return destPath, outcome, nil
 }
 
 // writeNoArtifactsMessage prints a "no artifacts" message tailored to the
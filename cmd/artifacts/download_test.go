@@ -155,7 +155,7 @@ func TestDownloadArtifactUsesArtifactPathAsDest(t *testing.T) {
 	f := newArtifactsTestFactory(t, server.URL)
 	art := &buildkite.Artifact{Path: "logs/rspec.json", DownloadURL: server.URL}
 
-	dest, err := downloadArtifact(context.Background(), f, art)
+	dest, _, err := downloadArtifact(context.Background(), f, art)
 	if err != nil {
 		t.Fatalf("downloadArtifact() error = %v", err)
 	}
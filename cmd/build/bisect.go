@@ -0,0 +1,338 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/build/bisect"
+	"github.com/buildkite/cli/v3/internal/build/watch"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkErrors "github.com/buildkite/cli/v3/internal/errors"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// errStepDecided stops watching a build once the step being bisected on has
+// finished.
+var errStepDecided = errors.New("step decided")
+
+type BisectCmd struct {
+	Good     string `help:"A build number, commit SHA or git ref that is known to be good." required:""`
+	Bad      string `help:"A build number, commit SHA or git ref that is known to be bad." required:""`
+	Pipeline string `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
+	Branch   string `help:"The branch to create builds on. Defaults to the pipeline's default branch." short:"b"`
+	StepKey  string `help:"Judge each commit by the jobs of this step alone, instead of the whole build." name:"step-key"`
+	NoCreate bool   `help:"Only use existing builds; skip commits that haven't been built." name:"no-create"`
+	Interval int    `help:"Polling interval in seconds while waiting for builds" default:"10"`
+}
+
+func (c *BisectCmd) Help() string {
+	return `Find the commit that broke a pipeline.
+
+The commits between --good and --bad are read from the local git repository,
+following the first parent of each commit back from the bad one. They are
+then bisected: the commit in the middle is tested, and the half that must
+contain the first bad commit is kept, until only one commit is left.
+
+To test a commit, an existing build of it is reused if there is one, or a new
+build is created and watched until it finishes. Commits whose builds are
+canceled or otherwise inconclusive are skipped.
+
+With --step-key, a commit is judged by that step's jobs alone. Builds created
+by the bisection are canceled as soon as the step has finished.
+
+Examples:
+  # Find which commit between two builds broke main
+  $ bk build bisect --good 1201 --bad 1207
+
+  # Bisect between two commits, judged on the tests alone
+  $ bk build bisect --good v1.4.0 --bad main --step-key tests
+
+  # Only use builds that already exist
+  $ bk build bisect --good 1a2b3c4 --bad 9f8e7d6 --no-create`
+}
+
+func (c *BisectCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	if f.GitRepository == nil {
+		return bkErrors.NewValidationError(nil, "not in a git repository", "Run bk build bisect from a clone of the pipeline's repository.")
+	}
+	if c.Interval < 1 {
+		return fmt.Errorf("--interval must be at least 1 second (requested: %d)", c.Interval)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pipelineRes := pipelineResolver.NewAggregateResolver(
+		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
+		pipelineResolver.ResolveFromConfig(f.Config, pipelineResolver.PickOneWithFactory(f)),
+		pipelineResolver.ResolveFromRepository(f, pipelineResolver.CachedPicker(f.Config, pipelineResolver.PickOneWithFactory(f))),
+	)
+	p, err := pipelineRes.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	b := &bisector{
+		f:        f,
+		out:      os.Stdout,
+		org:      p.Org,
+		pipeline: p.Name,
+		branch:   c.Branch,
+		stepKey:  c.StepKey,
+		noCreate: c.NoCreate,
+		interval: time.Duration(c.Interval) * time.Second,
+	}
+
+	good, err := b.resolvePoint(ctx, c.Good)
+	if err != nil {
+		return err
+	}
+	bad, err := b.resolvePoint(ctx, c.Bad)
+	if err != nil {
+		return err
+	}
+
+	return b.run(ctx, good, bad)
+}
+
+// bisector tests commits by building them on a pipeline.
+type bisector struct {
+	f        *factory.Factory
+	out      io.Writer
+	org      string
+	pipeline string
+	branch   string
+	stepKey  string
+	noCreate bool
+	interval time.Duration
+}
+
+// resolvePoint resolves --good or --bad to a commit in the local
+// repository. Short numbers are taken to be build numbers.
+func (b *bisector) resolvePoint(ctx context.Context, value string) (plumbing.Hash, error) {
+	revision := value
+	if _, err := strconv.Atoi(value); err == nil && len(value) < 7 {
+		bld, _, err := b.f.RestAPIClient.Builds.Get(ctx, b.org, b.pipeline, value, &buildkite.BuildGetOptions{
+			BuildsListOptions: buildkite.BuildsListOptions{ExcludeJobs: true, ExcludePipeline: true},
+		})
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("fetching build #%s: %w", value, err)
+		}
+		revision = bld.Commit
+		if b.branch == "" {
+			b.branch = bld.Branch
+		}
+	}
+
+	hash, err := b.f.GitRepository.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return plumbing.ZeroHash, bkErrors.NewValidationError(err, fmt.Sprintf("could not resolve %q in the local repository", revision), "Fetch the latest commits with git fetch and try again.")
+	}
+	return *hash, nil
+}
+
+func (b *bisector) run(ctx context.Context, good, bad plumbing.Hash) error {
+	commits, err := bisect.Commits(b.f.GitRepository, good, bad)
+	if err != nil {
+		return err
+	}
+
+	subjects := make(map[string]string, len(commits))
+	hashes := make([]string, 0, len(commits))
+	for _, c := range commits {
+		hashes = append(hashes, c.Hash.String())
+		subjects[c.Hash.String()] = strings.SplitN(c.Message, "\n", 2)[0]
+	}
+
+	bs := bisect.New(hashes)
+	n, steps := bs.Remaining()
+	fmt.Fprintf(b.out, "Bisecting %d commits between %s (good) and %s (bad), about %d builds\n\n", n, shortSHA(good.String()), shortSHA(bad.String()), steps)
+
+	buildURLs := make(map[string]string)
+	for {
+		commit, ok := bs.Next()
+		if !ok {
+			break
+		}
+		fmt.Fprintf(b.out, "%s %s\n", shortSHA(commit), subjects[commit])
+
+		outcome, url, err := b.test(ctx, commit)
+		if err != nil {
+			return err
+		}
+		if url != "" {
+			buildURLs[commit] = url
+		}
+		bs.Mark(commit, outcome)
+
+		n, steps := bs.Remaining()
+		fmt.Fprintf(b.out, "  %s; %d commits left, about %d builds\n", outcome, n, steps)
+	}
+
+	result := bs.Result()
+	if len(result) == 1 {
+		commit := result[0]
+		fmt.Fprintf(b.out, "\nFirst bad commit: %s %s\n", commit, subjects[commit])
+		if url, ok := buildURLs[commit]; ok {
+			fmt.Fprintf(b.out, "Build: %s\n", url)
+		}
+		return nil
+	}
+
+	fmt.Fprintf(b.out, "\nSome commits couldn't be tested. The first bad commit is one of:\n")
+	for _, commit := range result {
+		fmt.Fprintf(b.out, "  %s %s\n", shortSHA(commit), subjects[commit])
+	}
+	return nil
+}
+
+// test builds commit, reusing an existing build where there is one, and
+// returns what the build says about it and the build's URL.
+func (b *bisector) test(ctx context.Context, commit string) (bisect.Outcome, string, error) {
+	existing, err := b.existingBuild(ctx, commit)
+	if err != nil {
+		return bisect.Skip, "", err
+	}
+
+	created := false
+	var bld buildkite.Build
+	switch {
+	case existing != nil:
+		bld = *existing
+		fmt.Fprintf(b.out, "  using build #%d\n", bld.Number)
+	case b.noCreate:
+		fmt.Fprintln(b.out, "  no build found")
+		return bisect.Skip, "", nil
+	default:
+		bld, err = b.createBuild(ctx, commit)
+		if err != nil {
+			return bisect.Skip, "", err
+		}
+		created = true
+		fmt.Fprintf(b.out, "  created build #%d: %s\n", bld.Number, bld.WebURL)
+	}
+
+	if !bisect.Decided(bld, b.stepKey) {
+		number, url := bld.Number, bld.WebURL
+		if err := bkIO.SpinWhile(b.f, fmt.Sprintf("Waiting for build #%d", number), func() error {
+			bld, err = b.watch(ctx, number)
+			return err
+		}); err != nil {
+			if created {
+				b.cancelBuild(ctx, number)
+			}
+			return bisect.Skip, url, err
+		}
+	}
+
+	if created && bld.FinishedAt == nil {
+		b.cancelBuild(ctx, bld.Number)
+	}
+
+	return bisect.Judge(bld, b.stepKey), bld.WebURL, nil
+}
+
+// cancelBuild cancels a build the bisection created. It does so even once
+// ctx is canceled, e.g. by Ctrl-C, so that the build isn't left running.
+func (b *bisector) cancelBuild(ctx context.Context, number int) {
+	if _, err := b.f.RestAPIClient.Builds.Cancel(context.WithoutCancel(ctx), b.org, b.pipeline, strconv.Itoa(number)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not cancel build #%d: %v\n", number, err)
+	}
+}
+
+// existingBuild returns the most recent build of commit that says whether
+// it's good or bad or, failing that, one that's still running. It returns
+// nil if there are neither.
+func (b *bisector) existingBuild(ctx context.Context, commit string) (*buildkite.Build, error) {
+	builds, _, err := b.f.RestAPIClient.Builds.ListByPipeline(ctx, b.org, b.pipeline, &buildkite.BuildsListOptions{
+		Commit:          commit,
+		ExcludePipeline: true,
+		ListOptions:     buildkite.ListOptions{PerPage: 10},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing builds of %s: %w", shortSHA(commit), err)
+	}
+
+	var running *buildkite.Build
+	for i := range builds {
+		if bisect.Decided(builds[i], b.stepKey) {
+			if bisect.Judge(builds[i], b.stepKey) != bisect.Skip {
+				return &builds[i], nil
+			}
+			continue
+		}
+		if running == nil && builds[i].FinishedAt == nil {
+			running = &builds[i]
+		}
+	}
+	return running, nil
+}
+
+func (b *bisector) createBuild(ctx context.Context, commit string) (buildkite.Build, error) {
+	if b.branch == "" {
+		p, _, err := b.f.RestAPIClient.Pipelines.Get(ctx, b.org, b.pipeline)
+		if err != nil {
+			return buildkite.Build{}, bkErrors.WrapAPIError(err, "fetching pipeline")
+		}
+		b.branch = p.DefaultBranch
+	}
+
+	bld, _, err := b.f.RestAPIClient.Builds.Create(ctx, b.org, b.pipeline, buildkite.CreateBuild{
+		Commit:  commit,
+		Branch:  b.branch,
+		Message: fmt.Sprintf("Bisect: testing %s", shortSHA(commit)),
+	})
+	if err != nil {
+		return buildkite.Build{}, bkErrors.WrapAPIError(err, "creating build")
+	}
+	return bld, nil
+}
+
+// watch polls a build until it has an outcome for the step key, or until it
+// finishes.
+func (b *bisector) watch(ctx context.Context, number int) (buildkite.Build, error) {
+	bld, err := watch.WatchBuild(ctx, b.f.RestAPIClient, b.org, b.pipeline, number, b.interval, func(bld buildkite.Build) error {
+		if b.stepKey != "" && bisect.Decided(bld, b.stepKey) {
+			return errStepDecided
+		}
+		return nil
+	})
+	if errors.Is(err, errStepDecided) {
+		return bld, nil
+	}
+	return bld, err
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
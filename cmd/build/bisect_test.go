@@ -0,0 +1,179 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// linearRepo returns an in-memory repository with one commit per message,
+// each the parent of the next, and their hashes.
+func linearRepo(t *testing.T, messages ...string) (*git.Repository, []plumbing.Hash) {
+	t.Helper()
+
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hashes []plumbing.Hash
+	for i, msg := range messages {
+		sig := object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(int64(i), 0)}
+		c := &object.Commit{Author: sig, Committer: sig, Message: msg + "\n"}
+		if len(hashes) > 0 {
+			c.ParentHashes = []plumbing.Hash{hashes[len(hashes)-1]}
+		}
+		obj := repo.Storer.NewEncodedObject()
+		if err := c.Encode(obj); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := repo.Storer.SetEncodedObject(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, hash)
+	}
+	return repo, hashes
+}
+
+func TestBisectorRun(t *testing.T) {
+	t.Parallel()
+
+	repo, hashes := linearRepo(t, "good", "fine", "broken", "still broken")
+	finished := buildkite.NewTimestamp(time.Now())
+
+	var mu sync.Mutex
+	var created []buildkite.CreateBuild
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/organizations/acme/pipelines/app/builds":
+			var builds []buildkite.Build
+			if r.URL.Query().Get("commit") == hashes[1].String() {
+				builds = []buildkite.Build{
+					{Number: 10, State: "canceled", FinishedAt: finished},
+					{Number: 11, State: "passed", FinishedAt: finished, WebURL: "https://buildkite.com/acme/app/builds/11"},
+				}
+			}
+			_ = json.NewEncoder(w).Encode(builds)
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/organizations/acme/pipelines/app/builds":
+			var cb buildkite.CreateBuild
+			_ = json.NewDecoder(r.Body).Decode(&cb)
+			mu.Lock()
+			created = append(created, cb)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(buildkite.Build{Number: 20, State: "scheduled", WebURL: "https://buildkite.com/acme/app/builds/20"})
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/organizations/acme/pipelines/app/builds/20":
+			_ = json.NewEncoder(w).Encode(buildkite.Build{Number: 20, State: "failed", FinishedAt: finished, WebURL: "https://buildkite.com/acme/app/builds/20"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	f := newBuildTestFactory(t, server.URL)
+	f.GitRepository = repo
+
+	var out strings.Builder
+	b := &bisector{f: f, out: &out, org: "acme", pipeline: "app", branch: "main", interval: time.Millisecond}
+	if err := b.run(context.Background(), hashes[0], hashes[3]); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	if len(created) != 1 || created[0].Commit != hashes[2].String() || created[0].Branch != "main" {
+		t.Errorf("created builds = %+v, want one build of %s on main", created, hashes[2])
+	}
+	got := out.String()
+	for _, want := range []string{
+		"Bisecting 3 commits between " + hashes[0].String()[:7] + " (good) and " + hashes[3].String()[:7] + " (bad), about 2 builds\n",
+		"  using build #11\n  good; 2 commits left, about 1 builds\n",
+		"  created build #20: https://buildkite.com/acme/app/builds/20\n  bad; 1 commits left, about 0 builds\n",
+		"First bad commit: " + hashes[2].String() + " broken\nBuild: https://buildkite.com/acme/app/builds/20\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("run() output =\n%s\nwant it to contain %q", got, want)
+		}
+	}
+}
+
+func TestBisectorRunNoCreate(t *testing.T) {
+	t.Parallel()
+
+	repo, hashes := linearRepo(t, "good", "untested", "bad")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	f := newBuildTestFactory(t, server.URL)
+	f.GitRepository = repo
+
+	var out strings.Builder
+	b := &bisector{f: f, out: &out, org: "acme", pipeline: "app", noCreate: true, interval: time.Millisecond}
+	if err := b.run(context.Background(), hashes[0], hashes[2]); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "The first bad commit is one of:\n  "+hashes[1].String()[:7]+" untested\n  "+hashes[2].String()[:7]+" bad\n") {
+		t.Errorf("run() output =\n%s\nwant both commits as candidates", out.String())
+	}
+}
+
+func TestBisectorCancelsCreatedBuildWhenInterrupted(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var canceled []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/organizations/acme/pipelines/app/builds":
+			_, _ = w.Write([]byte("[]"))
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/organizations/acme/pipelines/app/builds":
+			_ = json.NewEncoder(w).Encode(buildkite.Build{Number: 20, State: "scheduled"})
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/organizations/acme/pipelines/app/builds/20":
+			// Interrupted while watching the build.
+			cancel()
+			_ = json.NewEncoder(w).Encode(buildkite.Build{Number: 20, State: "running"})
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/organizations/acme/pipelines/app/builds/20/cancel":
+			mu.Lock()
+			canceled = append(canceled, r.URL.Path)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(buildkite.Build{Number: 20, State: "canceling"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	b := &bisector{f: newBuildTestFactory(t, server.URL), out: io.Discard, org: "acme", pipeline: "app", branch: "main", interval: time.Millisecond}
+	if _, _, err := b.test(ctx, "abc123"); err == nil {
+		t.Fatal("test() error = nil, want the interruption")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(canceled) != 1 {
+		t.Errorf("cancel requests = %v, want the created build canceled", canceled)
+	}
+}
@@ -3,32 +3,70 @@ package build
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/alecthomas/kong"
 	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
 	"github.com/buildkite/cli/v3/internal/cli"
+	bkErrors "github.com/buildkite/cli/v3/internal/errors"
 	bkIO "github.com/buildkite/cli/v3/internal/io"
 	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
 	"github.com/buildkite/cli/v3/internal/util"
 	"github.com/buildkite/cli/v3/pkg/cmd/factory"
 	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	"github.com/buildkite/cli/v3/pkg/output"
 	buildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/mattn/go-isatty"
 )
 
+// cancelWorkerLimit caps the number of builds canceled at once.
+const cancelWorkerLimit = 5
+
+// cancelableStates are the build states that can be canceled, and those
+// matched by default when canceling by filter.
+var cancelableStates = []string{"scheduled", "running", "blocked", "failing"}
+
 type CancelCmd struct {
-	BuildNumber string `arg:"" help:"Build number to cancel"`
-	Pipeline    string `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
-	Web         bool   `help:"Open the build in a web browser after it has been cancelled." short:"w"`
+	BuildNumber   string   `arg:"" optional:"" help:"Build number to cancel (omit to cancel the builds matching the filters)"`
+	Pipeline      string   `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p" xor:"pipelines"`
+	AllPipelines  bool     `help:"Cancel matching builds in every pipeline of the organization." name:"all-pipelines" xor:"pipelines"`
+	Branch        []string `help:"Cancel builds on this branch. Supports * and ? wildcards, e.g. feature/*" short:"b"`
+	State         []string `help:"Cancel builds in this state: scheduled, running, blocked or failing (default: all of them)"`
+	Creator       string   `help:"Cancel builds created by this user (email address or user ID)"`
+	CreatedBefore string   `help:"Cancel builds created more than this long ago (e.g. 24h, 30m)" name:"created-before"`
+	Limit         int      `help:"Maximum number of builds to cancel" default:"100"`
+	DryRun        bool     `help:"List the builds that would be canceled without canceling them." name:"dry-run"`
+	Web           bool     `help:"Open the build in a web browser after it has been cancelled." short:"w"`
 }
 
 func (c *CancelCmd) Help() string {
-	return `
+	return `Cancel a build, or every build matching a set of filters.
+
+Without a build number, the scheduled, running, blocked and failing builds
+matching --branch, --state, --creator and --created-before are canceled, in
+the current pipeline or, with --all-pipelines, across the organization. The
+matching builds are listed and confirmed before any are canceled; use
+--dry-run to only list them. Builds are canceled several at a time and the
+result for each is printed as it finishes.
+
 Examples:
   # Cancel a build by number
   $ bk build cancel 123 --pipeline my-pipeline
 
   # Cancel a build and open in browser
-  $ bk build cancel 123 -pipeline my-pipeline --web`
+  $ bk build cancel 123 -pipeline my-pipeline --web
+
+  # See which builds on feature branches older than a day would be canceled
+  $ bk build cancel --branch "feature/*" --created-before 24h --dry-run
+
+  # Cancel every scheduled build by alice, in any pipeline
+  $ bk build cancel --all-pipelines --state scheduled --creator alice@company.com`
 }
 
 func (c *CancelCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
@@ -45,7 +83,16 @@ func (c *CancelCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 		return err
 	}
 
-	ctx := context.Background()
+	filtered := c.AllPipelines || len(c.Branch) > 0 || len(c.State) > 0 || c.Creator != "" || c.CreatedBefore != ""
+	if c.BuildNumber != "" && (filtered || c.DryRun) {
+		return bkErrors.NewValidationError(nil, "a build number cannot be combined with filters or --dry-run", "Give either a build number, or filters to cancel the builds matching them.")
+	}
+	if c.BuildNumber == "" && !filtered {
+		return bkErrors.NewValidationError(nil, "no build to cancel", "Give a build number, or filters such as --branch or --state to cancel several builds.")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	pipelineRes := pipelineResolver.NewAggregateResolver(
 		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
@@ -53,6 +100,10 @@ func (c *CancelCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 		pipelineResolver.ResolveFromRepository(f, pipelineResolver.CachedPicker(f.Config, pipelineResolver.PickOneWithFactory(f))),
 	)
 
+	if c.BuildNumber == "" {
+		return c.cancelMatching(ctx, f, pipelineRes)
+	}
+
 	args := []string{c.BuildNumber}
 	buildRes := buildResolver.NewAggregateResolver(
 		buildResolver.ResolveFromPositionalArgument(args, 0, pipelineRes.Resolve, f.Config),
@@ -75,6 +126,166 @@ func (c *CancelCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 	return cancelBuild(ctx, bld.Organization, bld.Pipeline, fmt.Sprint(bld.BuildNumber), c.Web, f)
 }
 
+// cancelMatching cancels the builds matching c's filters, using the same
+// filtering as bk build list.
+func (c *CancelCmd) cancelMatching(ctx context.Context, f *factory.Factory, pipelineRes pipelineResolver.AggregateResolver) error {
+	if c.Limit < 1 || c.Limit > maxBuildLimit {
+		return fmt.Errorf("--limit must be between 1 and %d (requested: %d)", maxBuildLimit, c.Limit)
+	}
+	for _, state := range c.State {
+		if !slices.Contains(cancelableStates, strings.ToLower(state)) {
+			return bkErrors.NewValidationError(nil, fmt.Sprintf("builds in the %q state cannot be canceled", state), "Use one of: "+strings.Join(cancelableStates, ", "))
+		}
+	}
+
+	list := &ListCmd{
+		Until:   c.CreatedBefore,
+		State:   c.State,
+		Branch:  c.Branch,
+		Creator: c.Creator,
+		Limit:   c.Limit,
+	}
+	if len(list.State) == 0 {
+		list.State = cancelableStates
+	}
+
+	org := f.Config.OrganizationSlug()
+	pipeline := ""
+	if !c.AllPipelines {
+		p, err := pipelineRes.Resolve(ctx)
+		if err != nil {
+			return err
+		}
+		org, pipeline = p.Org, p.Name
+		list.Pipeline = p.Org + "/" + p.Name
+	}
+
+	if list.Creator != "" && isValidEmail(list.Creator) {
+		var err error
+		if err = bkIO.SpinWhile(f, "Looking up user", func() error {
+			list.Creator, err = resolveCreatorEmailToUserID(ctx, f, c.Creator)
+			return err
+		}); err != nil {
+			return fmt.Errorf("failed to resolve creator email: %w", err)
+		}
+	}
+
+	listOpts, err := list.buildListOptions()
+	if err != nil {
+		return err
+	}
+	listOpts.ExcludeJobs = true
+	// Builds listed across the organization need their pipeline to be
+	// canceled.
+	listOpts.ExcludePipeline = !c.AllPipelines
+
+	// Fetch as structured output, so fetchBuilds neither prints the builds
+	// nor stops to ask whether to keep paging.
+	builds, err := list.fetchBuilds(ctx, f, org, listOpts, output.FormatJSON, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list builds: %w", err)
+	}
+
+	targets := make([]cancelTarget, 0, len(builds))
+	for _, b := range builds {
+		t := cancelTarget{pipeline: pipeline, build: b}
+		if b.Pipeline != nil && b.Pipeline.Slug != "" {
+			t.pipeline = b.Pipeline.Slug
+		}
+		targets = append(targets, t)
+	}
+
+	if len(targets) == 0 {
+		fmt.Println("No builds found matching the specified criteria.")
+		return nil
+	}
+
+	writeCancelTargets(os.Stdout, targets)
+	if len(targets) == c.Limit {
+		fmt.Printf("\nOnly the first %d matching builds are listed; raise --limit to cancel more.\n", c.Limit)
+	}
+
+	if c.DryRun {
+		fmt.Printf("\nDry run: %d %s would be canceled.\n", len(targets), bkIO.Pluralize("build", len(targets)))
+		return nil
+	}
+
+	confirmed, err := bkIO.Confirm(f, fmt.Sprintf("Cancel %d %s", len(targets), bkIO.Pluralize("build", len(targets))))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return nil
+	}
+
+	return cancelBuilds(ctx, f, org, targets, os.Stdout, isatty.IsTerminal(os.Stdout.Fd()))
+}
+
+// cancelTarget is a build to cancel and the slug of its pipeline.
+type cancelTarget struct {
+	pipeline string
+	build    buildkite.Build
+}
+
+func (t cancelTarget) String() string {
+	return fmt.Sprintf("%s #%d", t.pipeline, t.build.Number)
+}
+
+func writeCancelTargets(w io.Writer, targets []cancelTarget) {
+	rows := make([][]string, 0, len(targets))
+	for _, t := range targets {
+		created := "-"
+		if t.build.CreatedAt != nil {
+			created = t.build.CreatedAt.Format(time.RFC3339)
+		}
+		rows = append(rows, []string{
+			t.pipeline,
+			fmt.Sprintf("%d", t.build.Number),
+			t.build.State,
+			output.ValueOrDash(t.build.Branch),
+			created,
+			truncateBuildMessage(singleLineBuildMessage(t.build.Message)),
+		})
+	}
+	fmt.Fprint(w, output.Table(
+		[]string{"Pipeline", "Number", "State", "Branch", "Created", "Message"},
+		rows,
+		map[string]string{"pipeline": "bold", "number": "bold", "state": "bold", "created": "dim", "message": "italic"},
+	))
+}
+
+// cancelBuilds cancels targets, several at a time, writing progress to w as
+// each finishes. It returns an error if any couldn't be canceled, or if ctx
+// was canceled before all of them were.
+func cancelBuilds(ctx context.Context, f *factory.Factory, org string, targets []cancelTarget, w io.Writer, tty bool) error {
+	label := "Canceling builds"
+	if len(targets) == 1 {
+		label = "Canceling build"
+	}
+
+	started, err := bkIO.RunBatch(ctx, bkIO.Batch{
+		Label:  label,
+		Noun:   "build",
+		Verb:   "cancel",
+		Done:   "canceled",
+		Limit:  cancelWorkerLimit,
+		Out:    w,
+		ErrOut: os.Stderr,
+		TTY:    tty,
+		Quiet:  f.Quiet,
+	}, targets, cancelTarget.String, func(ctx context.Context, t cancelTarget) error {
+		_, err := f.RestAPIClient.Builds.Cancel(ctx, org, t.pipeline, fmt.Sprint(t.build.Number))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if started < len(targets) {
+		return fmt.Errorf("interrupted before canceling %d of %d %s", len(targets)-started, len(targets), bkIO.Pluralize("build", len(targets)))
+	}
+	return nil
+}
+
 func cancelBuild(ctx context.Context, org string, pipeline string, buildId string, web bool, f *factory.Factory) error {
 	var build buildkite.Build
 	if err := bkIO.SpinWhile(f, fmt.Sprintf("Cancelling build #%s from pipeline %s", buildId, pipeline), func() error {
@@ -0,0 +1,84 @@
+package build
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestCancelBuilds(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var canceled []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		if r.URL.Path == "/v2/organizations/acme/pipelines/web/builds/7/cancel" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message":"Build can't be canceled because it's already finished"}`))
+			return
+		}
+		mu.Lock()
+		canceled = append(canceled, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"state":"canceling"}`))
+	}))
+	defer server.Close()
+
+	f := newBuildTestFactory(t, server.URL)
+	f.Quiet = false
+	targets := []cancelTarget{
+		{pipeline: "app", build: buildkite.Build{Number: 1}},
+		{pipeline: "app", build: buildkite.Build{Number: 2}},
+		{pipeline: "web", build: buildkite.Build{Number: 7}},
+	}
+
+	var out strings.Builder
+	err := cancelBuilds(context.Background(), f, "acme", targets, &out, false)
+	if err == nil || !strings.Contains(err.Error(), "failed to cancel 1 of 3 builds") {
+		t.Fatalf("cancelBuilds() error = %v, want 1 of 3 failed", err)
+	}
+
+	slices.Sort(canceled)
+	if want := []string{"/v2/organizations/acme/pipelines/app/builds/1/cancel", "/v2/organizations/acme/pipelines/app/builds/2/cancel"}; !slices.Equal(canceled, want) {
+		t.Errorf("canceled = %v, want %v", canceled, want)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if want := "Canceling builds [████████████████████████] 100% 3/3 succeeded:2 failed:1"; len(lines) != 4 || lines[3] != want {
+		t.Errorf("cancelBuilds() output =\n%s\nwant 4 progress lines ending with %q", out.String(), want)
+	}
+}
+
+func TestCancelBuildsSucceeded(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"state":"canceling"}`))
+	}))
+	defer server.Close()
+
+	f := newBuildTestFactory(t, server.URL)
+	f.Quiet = false
+
+	var out strings.Builder
+	if err := cancelBuilds(context.Background(), f, "acme", []cancelTarget{{pipeline: "app", build: buildkite.Build{Number: 3}}}, &out, true); err != nil {
+		t.Fatalf("cancelBuilds() error = %v", err)
+	}
+	want := "Canceling build [░░░░░░░░░░░░░░░░░░░░░░░░]   0% 0/1 succeeded:0 failed:0" +
+		"\rCanceling build [████████████████████████] 100% 1/1 succeeded:1 failed:0\n" +
+		"\nSuccessfully canceled 1 of 1 build\n"
+	if out.String() != want {
+		t.Errorf("cancelBuilds() output = %q, want %q", out.String(), want)
+	}
+}
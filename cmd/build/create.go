@@ -5,7 +5,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/buildkite/cli/v3/internal/cli"
@@ -19,16 +22,19 @@ import (
 )
 
 type CreateCmd struct {
-	Message             string   `help:"Description of the build. If left blank, the commit message will be used once the build starts." short:"m"`
-	Commit              string   `help:"The commit to build." short:"c" default:"HEAD"`
-	Branch              string   `help:"The branch to build. Defaults to the default branch of the pipeline." short:"b"`
-	Author              string   `help:"Author of the build. Supports: \"Name <email>\", \"email@domain.com\", \"Full Name\", or \"username\"" short:"a"`
-	Web                 bool     `help:"Open the build in a web browser after it has been created." short:"w"`
-	Pipeline            string   `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
-	Env                 []string `help:"Set environment variables for the build (KEY=VALUE)" short:"e" sep:"none"`
-	Metadata            []string `help:"Set metadata for the build (KEY=VALUE)" short:"M" sep:"none"`
-	IgnoreBranchFilters bool     `help:"Ignore branch filters for the pipeline" short:"i"`
-	EnvFile             string   `help:"Set the environment variables for the build via an environment file" short:"f"`
+	Message             string        `help:"Description of the build. If left blank, the commit message will be used once the build starts." short:"m"`
+	Commit              string        `help:"The commit to build." short:"c" default:"HEAD"`
+	Branch              string        `help:"The branch to build. Defaults to the default branch of the pipeline." short:"b"`
+	Author              string        `help:"Author of the build. Supports: \"Name <email>\", \"email@domain.com\", \"Full Name\", or \"username\"" short:"a"`
+	Web                 bool          `help:"Open the build in a web browser after it has been created." short:"w"`
+	Pipeline            string        `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
+	Env                 []string      `help:"Set environment variables for the build (KEY=VALUE)" short:"e" sep:"none"`
+	Metadata            []string      `help:"Set metadata for the build (KEY=VALUE)" short:"M" sep:"none"`
+	IgnoreBranchFilters bool          `help:"Ignore branch filters for the pipeline" short:"i"`
+	EnvFile             string        `help:"Set the environment variables for the build via an environment file" short:"f"`
+	Wait                bool          `help:"Wait for the build to finish and exit with a status reflecting its outcome, as bk build watch does."`
+	Interval            int           `help:"Polling interval in seconds when waiting" default:"1"`
+	Timeout             time.Duration `help:"Stop waiting after this long (e.g. 30m) and exit with the timed out status. Requires --wait."`
 }
 
 func (c *CreateCmd) Help() string {
@@ -42,7 +48,10 @@ Examples:
   $ bk build create -e "FOO=BAR" -e "BAR=BAZ"
 
   # Create a new build with metadata
-  $ bk build create -M "key=value" -M "foo=bar"`
+  $ bk build create -M "key=value" -M "foo=bar"
+
+  # Create a new build and wait for it to finish, failing if the build fails
+  $ bk build create --wait --timeout 30m`
 }
 
 func (c *CreateCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
@@ -60,7 +69,15 @@ func (c *CreateCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 		return err
 	}
 
-	ctx := context.Background()
+	if c.Timeout > 0 && !c.Wait {
+		return bkErrors.NewValidationError(nil, "--timeout can only be used with --wait")
+	}
+	if c.Wait && c.Interval < 1 {
+		return bkErrors.NewValidationError(nil, "--interval must be at least 1 second")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	resolvers := resolver.NewAggregateResolver(
 		resolver.ResolveFromFlag(c.Pipeline, f.Config),
@@ -132,7 +149,18 @@ func (c *CreateCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 		}
 	}
 
-	return createBuild(ctx, resolvedPipeline.Org, resolvedPipeline.Name, f, c.Message, c.Commit, c.Branch, c.Web, envMap, metaDataMap, c.IgnoreBranchFilters, c.Author)
+	build, err := createBuild(ctx, resolvedPipeline.Org, resolvedPipeline.Name, f, c.Message, c.Commit, c.Branch, c.Web, envMap, metaDataMap, c.IgnoreBranchFilters, c.Author)
+	if err != nil || !c.Wait {
+		return err
+	}
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	return waitForBuild(ctx, f, nil, resolvedPipeline.Org, resolvedPipeline.Name, build.Number, time.Duration(c.Interval)*time.Second, c.Timeout)
 }
 
 func parseAuthor(author string) buildkite.Author {
@@ -166,7 +194,7 @@ func parseAuthor(author string) buildkite.Author {
 	return buildkite.Author{Username: author}
 }
 
-func createBuild(ctx context.Context, org string, pipeline string, f *factory.Factory, message string, commit string, branch string, web bool, env map[string]string, metaData map[string]string, ignoreBranchFilters bool, author string) error {
+func createBuild(ctx context.Context, org string, pipeline string, f *factory.Factory, message string, commit string, branch string, web bool, env map[string]string, metaData map[string]string, ignoreBranchFilters bool, author string) (buildkite.Build, error) {
 	var build buildkite.Build
 	if err := bkIO.SpinWhile(f, fmt.Sprintf("Starting new build for %s", pipeline), func() error {
 		branch = strings.TrimSpace(branch)
@@ -205,11 +233,11 @@ func createBuild(ctx context.Context, org string, pipeline string, f *factory.Fa
 		}
 		return nil
 	}); err != nil {
-		return err
+		return build, err
 	}
 
 	if build.WebURL == "" {
-		return bkErrors.NewAPIError(
+		return build, bkErrors.NewAPIError(
 			nil,
 			"build was created but no URL was returned",
 			"This may be due to an API version mismatch",
@@ -219,10 +247,10 @@ func createBuild(ctx context.Context, org string, pipeline string, f *factory.Fa
 	fmt.Printf("%s\n", renderResult(fmt.Sprintf("Build created: %s", build.WebURL)))
 
 	if err := util.OpenInWebBrowser(web, build.WebURL); err != nil {
-		return bkErrors.NewInternalError(err, "failed to open web browser")
+		return build, bkErrors.NewInternalError(err, "failed to open web browser")
 	}
 
-	return nil
+	return build, nil
 }
 
 func renderResult(result string) string {
@@ -0,0 +1,254 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Khan/genqlient/graphql"
+	"github.com/alecthomas/kong"
+	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
+	"github.com/buildkite/cli/v3/internal/build/resolver/options"
+	"github.com/buildkite/cli/v3/internal/build/timeline"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkGraphQL "github.com/buildkite/cli/v3/internal/graphql"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	"github.com/buildkite/cli/v3/internal/pipeline"
+	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	"github.com/buildkite/cli/v3/pkg/output"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxCriticalPathBuilds caps --builds.
+const maxCriticalPathBuilds = 100
+
+type CriticalPathCmd struct {
+	BuildNumber string `arg:"" optional:"" help:"Build number to analyse (omit for most recent build)"`
+	Pipeline    string `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
+	Branch      string `help:"Filter builds to this branch." short:"b"`
+	User        string `help:"Filter builds to this user. You can use name or email." short:"u" xor:"userfilter"`
+	Mine        bool   `help:"Filter builds to only my user." xor:"userfilter"`
+	Builds      int    `help:"Analyse the last N finished builds of the pipeline (on --branch, if given) and report the steps most often on the critical path." default:"0"`
+	output.OutputFlags
+}
+
+func (c *CriticalPathCmd) Help() string {
+	return `Find the chain of jobs that determined how long a build took.
+
+Working back from the job that finished last, each job on the critical path
+is the one the next was waiting for. The time along the path is split into
+waiting for agents, running, and time outside jobs (e.g. block steps).
+Steps on the path that waited a long time for an agent, or ran for a large
+share of the build, are flagged as candidates for more agents or more
+parallelism.
+
+Dependencies are taken from each step's depends_on and from retries. For
+steps without depends_on, they are inferred from wait steps and from when
+each job became runnable relative to when others finished.
+
+With --builds N, the last N finished builds are analysed instead, and the
+steps are listed by how often they were on the critical path.
+
+Examples:
+  # Critical path of the most recent build on the current branch
+  $ bk build critical-path
+
+  # Critical path of build 429, as JSON
+  $ bk build critical-path 429 --pipeline my-pipeline -o json
+
+  # Steps that are consistently critical over the last 30 builds on main
+  $ bk build critical-path --builds 30 -b main`
+}
+
+func (c *CriticalPathCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+	f.NoPager = f.NoPager || globals.DisablePager()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	format := output.ResolveFormat(c.Output, f.Config.OutputFormat())
+
+	if c.Builds < 0 || c.Builds > maxCriticalPathBuilds {
+		return fmt.Errorf("--builds must be between 0 and %d (requested: %d)", maxCriticalPathBuilds, c.Builds)
+	}
+	if c.Builds > 0 && c.BuildNumber != "" {
+		return fmt.Errorf("a build number cannot be combined with --builds")
+	}
+
+	ctx := context.Background()
+
+	pipelineRes := pipelineResolver.NewAggregateResolver(
+		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
+		pipelineResolver.ResolveFromConfig(f.Config, pipelineResolver.PickOneWithFactory(f)),
+		pipelineResolver.ResolveFromRepository(f, pipelineResolver.CachedPicker(f.Config, pipelineResolver.PickOneWithFactory(f))),
+	)
+
+	if c.Builds > 0 {
+		p, err := pipelineRes.Resolve(ctx)
+		if err != nil {
+			return err
+		}
+		// finishedBuilds shows its own progress as it pages.
+		builds, err := finishedBuilds(ctx, f, p, c.Branch, c.Builds)
+		if err != nil {
+			return err
+		}
+
+		timelines := make([]timeline.Timeline, len(builds))
+		for i, b := range builds {
+			timelines[i] = timeline.New(b, time.Now())
+		}
+		if err = bkIO.SpinWhile(f, "Loading step dependencies", func() error {
+			return addStepDependencies(ctx, f, p.Org, p.Name, timelines)
+		}); err != nil {
+			return err
+		}
+
+		paths := make([]timeline.CriticalPath, 0, len(timelines))
+		for _, t := range timelines {
+			paths = append(paths, t.CriticalPath())
+		}
+		steps := timeline.CriticalSteps(paths)
+
+		if format != output.FormatText {
+			return output.Write(os.Stdout, steps, format)
+		}
+		writer, cleanup := bkIO.Pager(f.NoPager, f.Config.Pager())
+		defer func() { _ = cleanup() }()
+		return timeline.WriteCriticalSteps(writer, steps)
+	}
+
+	optionsResolver := options.AggregateResolver{
+		options.ResolveBranchFromFlag(c.Branch),
+		options.ResolveBranchFromRepository(f.GitRepository),
+	}.WithResolverWhen(
+		c.User != "",
+		options.ResolveUserFromFlag(c.User),
+	).WithResolverWhen(
+		c.Mine || c.User == "",
+		options.ResolveCurrentUser(ctx, f),
+	)
+
+	args := []string{}
+	if c.BuildNumber != "" {
+		args = []string{c.BuildNumber}
+	}
+	buildRes := buildResolver.NewAggregateResolver(
+		buildResolver.ResolveFromPositionalArgument(args, 0, pipelineRes.Resolve, f.Config),
+		buildResolver.ResolveBuildWithOpts(f, pipelineRes.Resolve, optionsResolver...),
+	)
+
+	bld, err := buildRes.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	if bld == nil {
+		fmt.Println("No build found.")
+		return nil
+	}
+
+	var t timeline.Timeline
+	if err = bkIO.SpinWhile(f, "Loading build", func() error {
+		b, _, err := f.RestAPIClient.Builds.Get(ctx, bld.Organization, bld.Pipeline, fmt.Sprint(bld.BuildNumber), &buildkite.BuildGetOptions{
+			BuildsListOptions: buildkite.BuildsListOptions{ExcludePipeline: true, IncludeRetriedJobs: true},
+		})
+		if err != nil {
+			return err
+		}
+		t = timeline.New(b, time.Now())
+		return addStepDependencies(ctx, f, bld.Organization, bld.Pipeline, []timeline.Timeline{t})
+	}); err != nil {
+		return err
+	}
+
+	path := t.CriticalPath()
+
+	if format != output.FormatText {
+		return output.Write(os.Stdout, path, format)
+	}
+	writer, cleanup := bkIO.Pager(f.NoPager, f.Config.Pager())
+	defer func() { _ = cleanup() }()
+	return timeline.WriteCriticalPath(writer, path)
+}
+
+// finishedBuilds returns the last n passed or failed builds of p, on branch
+// if it isn't empty, with their jobs.
+func finishedBuilds(ctx context.Context, f *factory.Factory, p *pipeline.Pipeline, branch string, n int) ([]buildkite.Build, error) {
+	list := ListCmd{State: []string{"passed", "failed"}, Limit: n}
+	if branch != "" {
+		list.Branch = []string{branch}
+	}
+	return list.fetchLastBuilds(ctx, f, p, true)
+}
+
+// addStepDependencies records the depends_on of each timeline's jobs, looked
+// up with the GraphQL API, for their critical paths to follow.
+func addStepDependencies(ctx context.Context, f *factory.Factory, org, pipeline string, timelines []timeline.Timeline) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(downloadWorkerLimit)
+	for i := range timelines {
+		g.Go(func() error {
+			deps, err := stepDependencies(gctx, f.GraphQLClient, fmt.Sprintf("%s/%s/%d", org, pipeline, timelines[i].Build))
+			if err != nil {
+				return fmt.Errorf("fetching the step dependencies of build #%d: %w", timelines[i].Build, err)
+			}
+			timelines[i].SetDependencies(deps)
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// stepDependencies returns the step of each command job of the build with
+// the given slug, and the steps that step depends on, by job ID.
+func stepDependencies(ctx context.Context, client graphql.Client, slug string) (map[string]timeline.Dependencies, error) {
+	deps := make(map[string]timeline.Dependencies)
+	var cursor *string
+	for {
+		resp, err := bkGraphQL.GetBuildStepDependencies(ctx, client, slug, cursor)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Build == nil || resp.Build.Jobs == nil {
+			return deps, nil
+		}
+
+		for _, edge := range resp.Build.Jobs.Edges {
+			if edge == nil || edge.Node == nil {
+				continue
+			}
+			job, ok := (*edge.Node).(*bkGraphQL.GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand)
+			if !ok || job.Step == nil {
+				continue
+			}
+			d := timeline.Dependencies{Step: job.Step.Uuid}
+			if job.Step.Dependencies != nil {
+				for _, dep := range job.Step.Dependencies.Edges {
+					if dep != nil && dep.Node != nil && dep.Node.Key != nil {
+						d.DependsOn = append(d.DependsOn, *dep.Node.Key)
+					}
+				}
+			}
+			deps[job.Uuid] = d
+		}
+
+		pageInfo := resp.Build.Jobs.PageInfo
+		if pageInfo == nil || !pageInfo.HasNextPage {
+			return deps, nil
+		}
+		cursor = pageInfo.EndCursor
+	}
+}
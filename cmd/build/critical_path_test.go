@@ -0,0 +1,90 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Khan/genqlient/graphql"
+	"github.com/buildkite/cli/v3/internal/pipeline"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestFinishedBuilds(t *testing.T) {
+	t.Parallel()
+
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/organizations/acme/pipelines/monolith/builds" {
+			t.Errorf("unexpected request path %s", r.URL.Path)
+		}
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]buildkite.Build{{Number: 12}, {Number: 11}})
+	}))
+	defer server.Close()
+
+	f := newBuildTestFactory(t, server.URL)
+	builds, err := finishedBuilds(context.Background(), f, &pipeline.Pipeline{Org: "acme", Name: "monolith"}, "", 3)
+	if err != nil {
+		t.Fatalf("finishedBuilds() error = %v", err)
+	}
+	if len(builds) != 2 || builds[0].Number != 12 || builds[1].Number != 11 {
+		t.Errorf("unexpected builds: %+v", builds)
+	}
+	if len(queries) != 1 || !strings.Contains(queries[0], "state") || !strings.Contains(queries[0], "include_retried_jobs=true") || strings.Contains(queries[0], "branch") {
+		t.Errorf("unexpected list queries: %v", queries)
+	}
+}
+
+func TestStepDependencies(t *testing.T) {
+	t.Parallel()
+
+	var slugs, cursors []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding GraphQL request: %v", err)
+		}
+		slugs = append(slugs, req.Variables["slug"])
+		cursors = append(cursors, req.Variables["cursor"])
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.Variables["cursor"] == nil {
+			_, _ = w.Write([]byte(`{"data": {"build": {"jobs": {
+				"edges": [
+					{"node": {"__typename": "JobTypeCommand", "uuid": "job-test", "step": {"uuid": "step-test", "dependencies": {"edges": [{"node": {"key": "compile"}}]}}}},
+					{"node": {"__typename": "JobTypeWait"}}
+				],
+				"pageInfo": {"hasNextPage": true, "endCursor": "page-2"}
+			}}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"build": {"jobs": {
+			"edges": [
+				{"node": {"__typename": "JobTypeCommand", "uuid": "job-compile", "step": {"uuid": "step-compile", "dependencies": {"edges": []}}}}
+			],
+			"pageInfo": {"hasNextPage": false}
+		}}}}`))
+	}))
+	defer server.Close()
+
+	deps, err := stepDependencies(context.Background(), graphql.NewClient(server.URL, server.Client()), "acme/monolith/42")
+	if err != nil {
+		t.Fatalf("stepDependencies() error = %v", err)
+	}
+	if d := deps["job-test"]; d.Step != "step-test" || len(d.DependsOn) != 1 || d.DependsOn[0] != "compile" {
+		t.Errorf("deps[job-test] = %+v, want step-test depending on compile", d)
+	}
+	if d, ok := deps["job-compile"]; !ok || d.Step != "step-compile" || len(d.DependsOn) != 0 {
+		t.Errorf("deps[job-compile] = %+v, want step-compile with no dependencies", d)
+	}
+	if len(deps) != 2 || len(slugs) != 2 || slugs[0] != "acme/monolith/42" || cursors[1] != "page-2" {
+		t.Errorf("got %d jobs from requests for %v with cursors %v, want 2 jobs over two pages", len(deps), slugs, cursors)
+	}
+}
@@ -0,0 +1,138 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/build"
+	buildDiff "github.com/buildkite/cli/v3/internal/build/diff"
+	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
+	"github.com/buildkite/cli/v3/internal/build/view"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	"github.com/buildkite/cli/v3/pkg/output"
+)
+
+type DiffCmd struct {
+	BuildA   string `arg:"" help:"The build to compare from. This can be a build number, {pipeline slug}/{build number}, or a build URL."`
+	BuildB   string `arg:"" help:"The build to compare to."`
+	Pipeline string `help:"The pipeline containing the builds. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
+	output.OutputFlags
+}
+
+func (c *DiffCmd) Help() string {
+	return `Compare two builds side by side.
+
+Reports what changed from the first build to the second: the state, commit,
+branch, message and duration of the builds, their environment and meta-data,
+the jobs added or removed, and the jobs whose state, agent or queue changed or
+whose duration changed noticeably (by at least 10s and 20%). Jobs are matched
+by step key, or by label for steps without one. Artifacts are compared as
+bk artifacts diff does. Credentials in environment and meta-data values are
+masked, as with bk build logs --redact.
+
+Examples:
+  # What changed between yesterday's green build and today's red one
+  $ bk build diff 428 431
+
+  # Compare builds of a specific pipeline
+  $ bk build diff 428 431 -p monolith
+
+  # Output the comparison as JSON
+  $ bk build diff 428 431 -o json`
+}
+
+func (c *DiffCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+	f.NoPager = f.NoPager || globals.DisablePager()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	format := output.ResolveFormat(c.Output, f.Config.OutputFormat())
+
+	pipelineRes := pipelineResolver.NewAggregateResolver(
+		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
+		pipelineResolver.ResolveFromConfig(f.Config, pipelineResolver.PickOneWithFactory(f)),
+		pipelineResolver.ResolveFromRepository(f, pipelineResolver.CachedPicker(f.Config, pipelineResolver.PickOneWithFactory(f))),
+	)
+
+	ctx := context.Background()
+	args := []string{c.BuildA, c.BuildB}
+	var builds [2]*build.Build
+	for i := range builds {
+		builds[i], err = buildResolver.ResolveFromPositionalArgument(args, i, pipelineRes.Resolve, f.Config)(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	var d buildDiff.Diff
+	if err = bkIO.SpinWhile(f, "Comparing builds", func() error {
+		d, err = compareBuilds(ctx, f, builds[0], builds[1])
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if format != output.FormatText {
+		return output.Write(os.Stdout, d, format)
+	}
+
+	writer, cleanup := bkIO.Pager(f.NoPager, f.Config.Pager())
+	defer func() { _ = cleanup() }()
+
+	fmt.Fprintf(writer, "Comparing builds %s and %s\n", builds[0].Label(nil), builds[1].Label(builds[0]))
+	return buildDiff.Write(writer, d)
+}
+
+// compareBuilds fetches both builds, with their jobs and artifacts, as
+// bk build view does, and compares them.
+func compareBuilds(ctx context.Context, f *factory.Factory, a, b *build.Build) (buildDiff.Diff, error) {
+	fetcher := &ViewCmd{}
+	from, fromArtifacts, _, err := fetcher.fetchBuildDetails(ctx, f, view.ViewOptions{Organization: a.Organization, Pipeline: a.Pipeline, BuildNumber: a.BuildNumber})
+	if err != nil {
+		return buildDiff.Diff{}, fmt.Errorf("fetching build #%d: %w", a.BuildNumber, err)
+	}
+	to, toArtifacts, _, err := fetcher.fetchBuildDetails(ctx, f, view.ViewOptions{Organization: b.Organization, Pipeline: b.Pipeline, BuildNumber: b.BuildNumber})
+	if err != nil {
+		return buildDiff.Diff{}, fmt.Errorf("fetching build #%d: %w", b.BuildNumber, err)
+	}
+	d := buildDiff.Compare(from, to, fromArtifacts, toArtifacts, time.Now())
+
+	r, err := newLogRedactor(f, true)
+	if err != nil {
+		return buildDiff.Diff{}, err
+	}
+	redactChanges(r, d.Env)
+	redactChanges(r, d.MetaData)
+	return d, nil
+}
+
+// redactChanges masks credentials in the values of changed environment
+// variables or meta-data, as bk build export --redact does.
+func redactChanges(r *internaljob.Redactor, changes []buildDiff.Change) {
+	for i, c := range changes {
+		if c.From != "" {
+			changes[i].From = r.RedactVar(c.Name, c.From)
+		}
+		if c.To != "" {
+			changes[i].To = r.RedactVar(c.Name, c.To)
+		}
+	}
+}
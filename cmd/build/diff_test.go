@@ -0,0 +1,85 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/cli/v3/internal/build"
+	"github.com/buildkite/cli/v3/internal/config"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/spf13/afero"
+)
+
+func TestCompareBuilds(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	for number, b := range map[string]buildkite.Build{
+		"428": {Number: 428, State: "passed", Commit: "aaa", Env: map[string]any{"GITHUB_TOKEN": "ghp_" + strings.Repeat("a", 36)}, Jobs: []buildkite.Job{{Type: "script", StepKey: "tests", State: "passed"}}},
+		"431": {Number: 431, State: "failed", Commit: "bbb", Env: map[string]any{"GITHUB_TOKEN": "ghp_" + strings.Repeat("b", 36)}, MetaData: map[string]string{"db_password": "x8#Kq2!vLp9z"}, Jobs: []buildkite.Job{{Type: "script", StepKey: "tests", State: "failed"}}},
+	} {
+		base := "/v2/organizations/acme/pipelines/app/builds/" + number
+		mux.HandleFunc(base, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(b)
+		})
+		mux.HandleFunc(base+"/artifacts", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode([]buildkite.Artifact{{Path: "report-" + number + ".xml"}})
+		})
+		mux.HandleFunc(base+"/annotations", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte("[]"))
+		})
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	f := newBuildTestFactory(t, server.URL)
+	f.Config = config.New(afero.NewMemMapFs(), nil)
+	d, err := compareBuilds(context.Background(), f,
+		&build.Build{Organization: "acme", Pipeline: "app", BuildNumber: 428},
+		&build.Build{Organization: "acme", Pipeline: "app", BuildNumber: 431})
+	if err != nil {
+		t.Fatalf("compareBuilds() error = %v", err)
+	}
+
+	if d.From.Number != 428 || d.To.Number != 431 {
+		t.Errorf("compared builds #%d and #%d, want #428 and #431", d.From.Number, d.To.Number)
+	}
+	if len(d.Jobs.Changed) != 1 || !d.Jobs.Changed[0].Has("state") {
+		t.Errorf("Jobs.Changed = %+v, want the tests job's state", d.Jobs.Changed)
+	}
+	if len(d.Env) != 1 || d.Env[0].From != internaljob.Redacted || d.Env[0].To != internaljob.Redacted {
+		t.Errorf("Env = %+v, want GITHUB_TOKEN changed with both values redacted", d.Env)
+	}
+	if len(d.MetaData) != 1 || d.MetaData[0].To != internaljob.Redacted {
+		t.Errorf("MetaData = %+v, want db_password added and redacted", d.MetaData)
+	}
+	if len(d.Artifacts.Added) != 1 || d.Artifacts.Added[0].Path != "report-431.xml" {
+		t.Errorf("Artifacts.Added = %+v, want report-431.xml", d.Artifacts.Added)
+	}
+}
+
+func TestBuildLabel(t *testing.T) {
+	t.Parallel()
+
+	a := &build.Build{Organization: "acme", Pipeline: "app", BuildNumber: 1}
+	b := &build.Build{Organization: "acme", Pipeline: "app", BuildNumber: 2}
+	c := &build.Build{Organization: "acme", Pipeline: "web", BuildNumber: 3}
+
+	if got := a.Label(nil); got != "acme/app #1" {
+		t.Errorf("a.Label(nil) = %q", got)
+	}
+	if got := b.Label(a); got != "#2" {
+		t.Errorf("b.Label(a) = %q", got)
+	}
+	if got := c.Label(a); got != "acme/web #3" {
+		t.Errorf("c.Label(a) = %q", got)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/buildkite/cli/v3/internal/build/resolver/options"
 	"github.com/buildkite/cli/v3/internal/cli"
 	bkIO "github.com/buildkite/cli/v3/internal/io"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
 	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
 	"github.com/buildkite/cli/v3/pkg/cmd/factory"
 	"github.com/buildkite/cli/v3/pkg/cmd/validation"
@@ -36,6 +37,7 @@ type DownloadCmd struct {
 	Mine           bool   `help:"Filter builds to only my user." short:"m" xor:"userfilter"`
 	ArtifactsPath  string `help:"Filter artifacts by path. Supports exact matches and glob patterns using * as a wildcard, e.g. --artifacts-path \"log/rspec*.json\"."`
 	ArtifactsState string `help:"Filter artifacts to download by state. Must be one of: new, finished, error, deleted, expired."`
+	Redact         bool   `help:"Mask credentials in the downloaded job logs, as with bk build logs --redact."`
 }
 
 func (c *DownloadCmd) Help() string {
@@ -59,6 +61,13 @@ Examples:
   # Filter artifacts to download by path or state
   $ bk build download --pipeline my-pipeline --artifacts-path "log/rspec*.json"
   $ bk build download --pipeline my-pipeline --artifacts-state finished
+
+  # Mask credentials in the downloaded job logs
+  $ bk build download 123 --pipeline my-pipeline --redact
+
+Artifacts are checked against the SHA-1 checksum Buildkite records for each
+one. Rerunning a download skips artifacts that already match and resumes any
+that were interrupted.
 `
 }
 
@@ -76,6 +85,11 @@ func (c *DownloadCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error
 		return err
 	}
 
+	redactor, err := newLogRedactor(f, c.Redact)
+	if err != nil {
+		return err
+	}
+
 	ctx := context.Background()
 
 	// we find the pipeline based on the following rules:
@@ -125,9 +139,10 @@ func (c *DownloadCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error
 	var (
 		dir             string
 		artifactMatches int
+		report          artifact.DownloadReport
 	)
 	if err = bkIO.SpinWhile(f, "Downloading build resources", func() error {
-		dir, artifactMatches, err = download(ctx, bld, c.ArtifactsPath, c.ArtifactsState, f)
+		dir, artifactMatches, err = download(ctx, bld, c.ArtifactsPath, c.ArtifactsState, redactor, &report, f)
 		return err
 	}); err != nil {
 		return err
@@ -140,8 +155,11 @@ func (c *DownloadCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error
 	}
 
 	fmt.Printf("Downloaded build to: %s\n", dir)
+	if artifactMatches > 0 {
+		report.Write(os.Stdout)
+	}
 
-	return nil
+	return report.Err()
 }
 
 // warnUnmatchedArtifactFilter writes a stderr warning when --artifacts-path
@@ -162,8 +180,17 @@ func warnUnmatchedArtifactFilter(w io.Writer, path, state string) {
 
 // download returns the destination directory and the number of artifacts the
 // (optional) filter matched. A zero count with a filter set is not itself an
-// error — the caller decides how to surface it.
-func download(ctx context.Context, bld *build.Build, artifactsPath, artifactsState string, f *factory.Factory) (string, int, error) {
+// error — the caller decides how to surface it. Job logs are redacted when
+// redactor is non-nil.
+//
+// Artifacts are verified against their checksums, and those already
+// downloaded are skipped. Failed artifact downloads don't stop the others;
+// the outcome of each is recorded in report when it is non-nil.
+func download(ctx context.Context, bld *build.Build, artifactsPath, artifactsState string, redactor *internaljob.Redactor, report *artifact.DownloadReport, f *factory.Factory) (string, int, error) {
+	if report == nil {
+		report = &artifact.DownloadReport{}
+	}
+
 	// Jobs are needed for log downloads, but the pipeline payload is unused.
 	getOpts := &buildkite.BuildGetOptions{
 		BuildsListOptions: buildkite.BuildsListOptions{ExcludePipeline: true},
@@ -203,7 +230,11 @@ func download(ctx context.Context, bld *build.Build, artifactsPath, artifactsSta
 			if err != nil {
 				return err
 			}
-			return os.WriteFile(filepath.Join(directory, jobID), []byte(log.Content), 0o644)
+			content := log.Content
+			if redactor != nil {
+				content = redactor.Redact(content)
+			}
+			return os.WriteFile(filepath.Join(directory, jobID), []byte(content), 0o644)
 		})
 	}
 
@@ -213,7 +244,9 @@ func download(ctx context.Context, bld *build.Build, artifactsPath, artifactsSta
 			// This is UX-observable and separate from `bk artifacts download`,
 			// which mirrors the artifact's own directory structure.
 			dest := filepath.Join(directory, fmt.Sprintf("artifact-%s-%s", a.ID, a.Filename))
-			return artifact.DownloadToFile(ctx, f.RestAPIClient, a.DownloadURL, dest)
+			outcome, err := artifact.Download(ctx, f.RestAPIClient, a, dest)
+			report.Add(a.Path, outcome, err)
+			return nil
 		})
 	}
 
@@ -91,7 +91,7 @@ func TestDownloadPassesArtifactFiltersToAPI(t *testing.T) {
 	t.Chdir(t.TempDir())
 
 	bld := &build.Build{Organization: "acme", Pipeline: "monolith", BuildNumber: 429}
-	dir, matches, err := download(context.Background(), bld, "log/rspec*.json", "Finished", newBuildTestFactory(t, server.URL))
+	dir, matches, err := download(context.Background(), bld, "log/rspec*.json", "Finished", nil, nil, newBuildTestFactory(t, server.URL))
 	if err != nil {
 		t.Fatalf("download() error = %v", err)
 	}
@@ -122,7 +122,7 @@ func TestDownloadNoFiltersOmitsQueryParams(t *testing.T) {
 	t.Chdir(t.TempDir())
 
 	bld := &build.Build{Organization: "acme", Pipeline: "monolith", BuildNumber: 429}
-	if _, _, err := download(context.Background(), bld, "", "", newBuildTestFactory(t, server.URL)); err != nil {
+	if _, _, err := download(context.Background(), bld, "", "", nil, nil, newBuildTestFactory(t, server.URL)); err != nil {
 		t.Fatalf("download() error = %v", err)
 	}
 
@@ -157,7 +157,7 @@ func TestDownloadWritesArtifactsWithFlatNames(t *testing.T) {
 	t.Chdir(t.TempDir())
 
 	bld := &build.Build{Organization: "acme", Pipeline: "monolith", BuildNumber: 429}
-	dir, matches, err := download(context.Background(), bld, "", "", newBuildTestFactory(t, server.URL))
+	dir, matches, err := download(context.Background(), bld, "", "", nil, nil, newBuildTestFactory(t, server.URL))
 	if err != nil {
 		t.Fatalf("download() error = %v", err)
 	}
@@ -313,7 +313,7 @@ func TestDownloadCapsArtifactConcurrency(t *testing.T) {
 	t.Chdir(t.TempDir())
 
 	bld := &build.Build{Organization: "acme", Pipeline: "monolith", BuildNumber: 429}
-	_, matches, err := download(context.Background(), bld, "", "", newBuildTestFactory(t, server.URL))
+	_, matches, err := download(context.Background(), bld, "", "", nil, nil, newBuildTestFactory(t, server.URL))
 	if err != nil {
 		t.Fatalf("download() error = %v", err)
 	}
@@ -340,7 +340,7 @@ func TestDownloadReturnsZeroMatchesWhenFilterExcludesAll(t *testing.T) {
 	t.Chdir(t.TempDir())
 
 	bld := &build.Build{Organization: "acme", Pipeline: "monolith", BuildNumber: 429}
-	dir, matches, err := download(context.Background(), bld, "does-not-exist/*", "", newBuildTestFactory(t, server.URL))
+	dir, matches, err := download(context.Background(), bld, "does-not-exist/*", "", nil, nil, newBuildTestFactory(t, server.URL))
 	if err != nil {
 		t.Fatalf("download() error = %v", err)
 	}
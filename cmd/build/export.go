@@ -0,0 +1,365 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/artifact"
+	"github.com/buildkite/cli/v3/internal/build"
+	"github.com/buildkite/cli/v3/internal/build/archive"
+	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
+	"github.com/buildkite/cli/v3/internal/build/resolver/options"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
+	internalpreflight "github.com/buildkite/cli/v3/internal/preflight"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"golang.org/x/sync/errgroup"
+)
+
+type ExportCmd struct {
+	BuildNumber      string `arg:"" optional:"" help:"Build number to export (omit for most recent build)"`
+	Pipeline         string `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
+	Branch           string `help:"Filter builds to this branch." short:"b"`
+	User             string `help:"Filter builds to this user. You can use name or email." short:"u" xor:"userfilter"`
+	Mine             bool   `help:"Filter builds to only my user." xor:"userfilter"`
+	Output           string `help:"File to write the archive to. Defaults to <pipeline>-<build number>.tar.gz." short:"o" type:"path"`
+	IncludeArtifacts bool   `help:"Include the artifact files themselves, not just the artifact listing." name:"include-artifacts"`
+	Redact           bool   `help:"Mask credentials in the archived job logs, environment, meta-data and annotations, as with bk build logs --redact."`
+}
+
+func (c *ExportCmd) Help() string {
+	return `Export a build to a portable archive that can be browsed offline.
+
+The archive is a gzipped tar holding the build's JSON, every job log,
+annotations, the artifact listing and the Test Engine summary, so the build can
+be kept after its logs and artifacts expire. View it again, without API access,
+with bk build view --from-archive.
+
+Examples:
+  # Export the most recent build on the current branch
+  $ bk build export
+
+  # Export build 429 to a chosen file
+  $ bk build export 429 -p monolith -o incident-1234.tar.gz
+
+  # Include the artifact files as well as their listing
+  $ bk build export 429 --include-artifacts
+
+  # Mask credentials in the archived logs, environment, meta-data and annotations
+  $ bk build export 429 --redact
+
+  # Browse the archive later
+  $ bk build view --from-archive incident-1234.tar.gz`
+}
+
+func (c *ExportCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	redactor, err := newLogRedactor(f, c.Redact)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	pipelineRes := pipelineResolver.NewAggregateResolver(
+		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
+		pipelineResolver.ResolveFromConfig(f.Config, pipelineResolver.PickOneWithFactory(f)),
+		pipelineResolver.ResolveFromRepository(f, pipelineResolver.CachedPicker(f.Config, pipelineResolver.PickOneWithFactory(f))),
+	)
+
+	optionsResolver := options.AggregateResolver{
+		options.ResolveBranchFromFlag(c.Branch),
+		options.ResolveBranchFromRepository(f.GitRepository),
+	}.WithResolverWhen(
+		c.User != "",
+		options.ResolveUserFromFlag(c.User),
+	).WithResolverWhen(
+		c.Mine || c.User == "",
+		options.ResolveCurrentUser(ctx, f),
+	)
+
+	args := []string{}
+	if c.BuildNumber != "" {
+		args = []string{c.BuildNumber}
+	}
+	buildRes := buildResolver.NewAggregateResolver(
+		buildResolver.ResolveFromPositionalArgument(args, 0, pipelineRes.Resolve, f.Config),
+		buildResolver.ResolveBuildWithOpts(f, pipelineRes.Resolve, optionsResolver...),
+	)
+
+	bld, err := buildRes.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	if bld == nil {
+		fmt.Println("No build found.")
+		return nil
+	}
+
+	dest := c.Output
+	if dest == "" {
+		dest = fmt.Sprintf("%s-%d.tar.gz", bld.Pipeline, bld.BuildNumber)
+	}
+
+	var warnings []string
+	if err = bkIO.SpinWhile(f, "Exporting build", func() error {
+		warnings, err = writeArchiveFile(dest, func(w io.Writer) ([]string, error) {
+			return exportBuild(ctx, f, bld, w, exportOptions{includeArtifacts: c.IncludeArtifacts, redactor: redactor})
+		})
+		return err
+	}); err != nil {
+		return err
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	fmt.Printf("Exported %s/%s #%d to: %s\n", bld.Organization, bld.Pipeline, bld.BuildNumber, dest)
+	return nil
+}
+
+// writeArchiveFile calls write with a temporary file next to dest, then
+// renames it into place, so a failed export never leaves a partial archive.
+func writeArchiveFile(dest string, write func(io.Writer) ([]string, error)) ([]string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".bk-export-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	warnings, err := write(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return warnings, os.Rename(tmp.Name(), dest)
+}
+
+type exportOptions struct {
+	includeArtifacts bool
+	redactor         *internaljob.Redactor
+}
+
+// exportBuild fetches everything about a build and writes it to w as an
+// archive. Missing Test Engine data and artifact files that can no longer be
+// downloaded don't fail the export; they are returned as warnings.
+func exportBuild(ctx context.Context, f *factory.Factory, bld *build.Build, w io.Writer, opts exportOptions) ([]string, error) {
+	number := fmt.Sprint(bld.BuildNumber)
+	// The pipeline, with its env and configuration, is left out: the
+	// manifest records which pipeline the build belongs to.
+	b, _, err := f.RestAPIClient.Builds.Get(ctx, bld.Organization, bld.Pipeline, number, &buildkite.BuildGetOptions{
+		BuildsListOptions: buildkite.BuildsListOptions{ExcludePipeline: true},
+		IncludeTestEngine: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		annotations []buildkite.Annotation
+		artifacts   []buildkite.Artifact
+		tests       *internalpreflight.SummaryResult
+		logs        = make(map[string]string)
+		warnings    []string
+		mu          sync.Mutex
+	)
+	warn := func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(downloadWorkerLimit)
+	g.Go(func() error {
+		var err error
+		annotations, _, err = f.RestAPIClient.Annotations.ListByBuild(gctx, bld.Organization, bld.Pipeline, number, nil)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		artifacts, err = artifact.List(gctx, f.RestAPIClient, bld.Organization, bld.Pipeline, number, "", "", "")
+		return err
+	})
+	if b.TestEngine != nil && len(b.TestEngine.Runs) > 0 {
+		g.Go(func() error {
+			summary, err := internalpreflight.NewRunSummaryService(f.RestAPIClient).Get(gctx, bld.Organization, b.ID, &internalpreflight.RunSummaryGetOptions{IncludeFailures: true})
+			if err != nil {
+				warn("could not fetch the Test Engine summary: %v", err)
+				return nil
+			}
+			result := summary.SummaryResult()
+			tests = &result
+			return nil
+		})
+	}
+	for _, j := range b.Jobs {
+		// Only script (command) jobs have logs.
+		if j.Type != "script" {
+			continue
+		}
+		g.Go(func() error {
+			log, _, err := f.RestAPIClient.Jobs.GetJobLog(gctx, bld.Organization, bld.Pipeline, b.ID, j.ID)
+			if err != nil {
+				return fmt.Errorf("fetching log for job %s: %w", jobLogLabel(j), err)
+			}
+			content := log.Content
+			if opts.redactor != nil {
+				content = opts.redactor.Redact(content)
+			}
+			mu.Lock()
+			logs[j.ID] = content
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	if opts.redactor != nil {
+		redactExport(opts.redactor, &b, annotations)
+	}
+
+	aw := archive.NewWriter(w)
+	manifest := archive.Manifest{
+		FormatVersion:     archive.FormatVersion,
+		ExportedAt:        time.Now().UTC(),
+		Organization:      bld.Organization,
+		Pipeline:          bld.Pipeline,
+		BuildNumber:       bld.BuildNumber,
+		IncludesArtifacts: opts.includeArtifacts,
+		Redacted:          opts.redactor != nil,
+	}
+	if err := aw.WriteJSON(archive.ManifestFile, manifest); err != nil {
+		return nil, err
+	}
+	if err := aw.WriteJSON(archive.BuildFile, b); err != nil {
+		return nil, err
+	}
+	if err := aw.WriteJSON(archive.AnnotationsFile, annotations); err != nil {
+		return nil, err
+	}
+	if err := aw.WriteJSON(archive.ArtifactsFile, artifacts); err != nil {
+		return nil, err
+	}
+	if tests != nil {
+		if err := aw.WriteJSON(archive.TestsFile, tests); err != nil {
+			return nil, err
+		}
+	}
+	for _, j := range b.Jobs {
+		if content, ok := logs[j.ID]; ok {
+			if err := aw.WriteFile(archive.LogFile(j.ID), []byte(content)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if opts.includeArtifacts {
+		if err := exportArtifactFiles(ctx, f, aw, artifacts, warn); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := aw.Close(); err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+// redactExport masks credentials in the parts of a build besides its logs
+// that commonly hold them: the build's environment and meta-data, and
+// annotation bodies.
+func redactExport(r *internaljob.Redactor, b *buildkite.Build, annotations []buildkite.Annotation) {
+	for k, v := range b.Env {
+		if s, ok := v.(string); ok {
+			b.Env[k] = r.RedactVar(k, s)
+		}
+	}
+	for k, v := range b.MetaData {
+		b.MetaData[k] = r.RedactVar(k, v)
+	}
+	for i := range annotations {
+		annotations[i].BodyHTML = r.Redact(annotations[i].BodyHTML)
+	}
+}
+
+// exportArtifactFiles downloads artifacts concurrently into a temporary
+// directory, then adds each one to the archive.
+func exportArtifactFiles(ctx context.Context, f *factory.Factory, aw *archive.Writer, artifacts []buildkite.Artifact, warn func(string, ...any)) error {
+	dir, err := os.MkdirTemp("", "bk-export-artifacts-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	downloaded := make([]bool, len(artifacts))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(downloadWorkerLimit)
+	for i, a := range artifacts {
+		if a.State != "finished" {
+			warn("skipped artifact %s: its state is %s", a.Path, a.State)
+			continue
+		}
+		g.Go(func() error {
+			if err := artifact.DownloadToFile(gctx, f.RestAPIClient, a.DownloadURL, filepath.Join(dir, a.ID)); err != nil {
+				warn("could not download artifact %s: %v", a.Path, err)
+				return nil
+			}
+			downloaded[i] = true
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	for i, a := range artifacts {
+		if !downloaded[i] {
+			continue
+		}
+		if err := addArtifactFile(aw, a, filepath.Join(dir, a.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addArtifactFile(aw *archive.Writer, a buildkite.Artifact, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	return aw.WriteFrom(archive.ArtifactFile(a), info.Size(), file)
+}
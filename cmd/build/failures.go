@@ -0,0 +1,581 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alecthomas/kong"
+	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
+	"github.com/buildkite/cli/v3/internal/build/resolver/options"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	"github.com/buildkite/cli/v3/internal/job/classify"
+	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
+	internalpreflight "github.com/buildkite/cli/v3/internal/preflight"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/mcncl/terminal-to-llm/digest"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// minJobLogTokens is the smallest share of --max-tokens a job's digest
+	// is cut down to; below that, the log is left out instead.
+	minJobLogTokens = 100
+
+	// maxBacktraceLines caps the backtrace printed for each test failure.
+	maxBacktraceLines = 10
+)
+
+type FailuresCmd struct {
+	BuildNumber string   `arg:"" optional:"" help:"Build number to report on (omit for most recent build)"`
+	Pipeline    string   `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
+	Branch      string   `help:"Filter builds to this branch." short:"b"`
+	User        string   `help:"Filter builds to this user. You can use name or email." short:"u" xor:"userfilter"`
+	Mine        bool     `help:"Filter builds to only my user." xor:"userfilter"`
+	Format      string   `help:"Report format: markdown or json" enum:"markdown,json" default:"markdown"`
+	MaxTokens   int      `help:"Hard ceiling on the estimated token count of the whole report, shared between jobs (0 = unlimited)" name:"max-tokens"`
+	NoWindow    bool     `help:"Disable failure-focused windowing of job logs (keep all lines)" name:"no-window"`
+	NoTests     bool     `help:"Don't include Test Engine failures" name:"no-tests"`
+	Redact      bool     `help:"Mask credentials in job logs and test failures, as with bk build logs --redact"`
+	Rules       []string `help:"Extra ruleset files for classifying failures, on top of the built-in rules and the repository's .buildkite/classify.yaml"`
+}
+
+func (c *FailuresCmd) Help() string {
+	return `Report every failed job in a build, for people and LLMs.
+
+The logs of all failed, timed out and soft failed jobs are fetched in
+parallel and digested the same way as bk job log --agent: ANSI codes are
+removed, repeated output is collapsed, and each log is windowed around its
+failure. Each job is labelled with the likely cause of its failure, as with
+bk job log --classify. Test failures recorded by Test Engine for the build
+are included too.
+
+With --max-tokens, the whole report is kept under an estimated token budget.
+Small logs are included in full and the rest of the budget is split evenly
+between the larger ones. If the budget is too small for that, logs are left
+out, then test failures, then jobs, and the report says how many.
+
+Examples:
+  # Failures in the most recent build on the current branch
+  $ bk build failures
+
+  # Failures in build 429, as JSON
+  $ bk build failures 429 --pipeline my-pipeline --format json
+
+  # A report that fits in an LLM's context window, with credentials masked
+  $ bk build failures 429 --max-tokens 20000 --redact`
+}
+
+func (c *FailuresCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+	f.NoPager = f.NoPager || globals.DisablePager()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	if c.MaxTokens < 0 {
+		return fmt.Errorf("--max-tokens must not be negative (requested: %d)", c.MaxTokens)
+	}
+
+	ctx := context.Background()
+
+	pipelineRes := pipelineResolver.NewAggregateResolver(
+		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
+		pipelineResolver.ResolveFromConfig(f.Config, pipelineResolver.PickOneWithFactory(f)),
+		pipelineResolver.ResolveFromRepository(f, pipelineResolver.CachedPicker(f.Config, pipelineResolver.PickOneWithFactory(f))),
+	)
+
+	optionsResolver := options.AggregateResolver{
+		options.ResolveBranchFromFlag(c.Branch),
+		options.ResolveBranchFromRepository(f.GitRepository),
+	}.WithResolverWhen(
+		c.User != "",
+		options.ResolveUserFromFlag(c.User),
+	).WithResolverWhen(
+		c.Mine || c.User == "",
+		options.ResolveCurrentUser(ctx, f),
+	)
+
+	args := []string{}
+	if c.BuildNumber != "" {
+		args = []string{c.BuildNumber}
+	}
+	buildRes := buildResolver.NewAggregateResolver(
+		buildResolver.ResolveFromPositionalArgument(args, 0, pipelineRes.Resolve, f.Config),
+		buildResolver.ResolveBuildWithOpts(f, pipelineRes.Resolve, optionsResolver...),
+	)
+
+	bld, err := buildRes.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	if bld == nil {
+		fmt.Println("No build found.")
+		return nil
+	}
+
+	var report failureReport
+	if err = bkIO.SpinWhile(f, "Fetching failed jobs", func() error {
+		b, _, err := f.RestAPIClient.Builds.Get(ctx, bld.Organization, bld.Pipeline, fmt.Sprint(bld.BuildNumber), &buildkite.BuildGetOptions{
+			IncludeTestEngine: true,
+			BuildsListOptions: buildkite.BuildsListOptions{ExcludePipeline: true},
+		})
+		if err != nil {
+			return err
+		}
+		report, err = c.buildReport(ctx, f, bld.Organization, bld.Pipeline, b)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	for _, w := range report.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+
+	if c.Format == "json" {
+		_, err = io.WriteString(os.Stdout, renderFailureReport(report, c.Format))
+		return err
+	}
+
+	writer, cleanup := bkIO.Pager(f.NoPager, f.Config.Pager())
+	defer func() { _ = cleanup() }()
+
+	return writeFailureReportMarkdown(writer, report)
+}
+
+// failureReport is the output of bk build failures.
+type failureReport struct {
+	Organization string                                 `json:"organization"`
+	Pipeline     string                                 `json:"pipeline"`
+	Number       int                                    `json:"number"`
+	State        string                                 `json:"state"`
+	Branch       string                                 `json:"branch"`
+	Commit       string                                 `json:"commit"`
+	Message      string                                 `json:"message"`
+	WebURL       string                                 `json:"web_url"`
+	Jobs         []failedJob                            `json:"jobs"`
+	TestFailures []internalpreflight.SummaryTestFailure `json:"test_failures"`
+	MaxTokens    int                                    `json:"max_tokens,omitempty"`
+	Warnings     []string                               `json:"warnings,omitempty"`
+
+	// OmittedJobs and OmittedTestFailures count what was left out to fit
+	// --max-tokens.
+	OmittedJobs         int `json:"omitted_jobs,omitempty"`
+	OmittedTestFailures int `json:"omitted_test_failures,omitempty"`
+}
+
+// failedJob is a failed job and the digest of its log.
+type failedJob struct {
+	ID         string           `json:"id"`
+	Label      string           `json:"label"`
+	StepKey    string           `json:"step_key,omitempty"`
+	State      string           `json:"state"`
+	SoftFailed bool             `json:"soft_failed"`
+	ExitStatus *int             `json:"exit_status"`
+	WebURL     string           `json:"web_url"`
+	Cause      *classify.Result `json:"cause,omitempty"`
+	Log        string           `json:"log"`
+	LogOmitted bool             `json:"log_omitted,omitempty"`
+
+	// raw is the log with timestamps removed, kept so the digest can be
+	// redone under a tighter token limit.
+	raw string
+}
+
+// isFailedJob reports whether j is a command job that failed, timed out or
+// soft failed.
+func isFailedJob(j buildkite.Job) bool {
+	if j.Type != "script" {
+		return false
+	}
+	return j.State == "failed" || j.State == "timed_out" || j.SoftFailed
+}
+
+// buildReport collects the failed jobs of b, digests their logs, and adds the
+// build's Test Engine failures.
+func (c *FailuresCmd) buildReport(ctx context.Context, f *factory.Factory, org, pipeline string, b buildkite.Build) (failureReport, error) {
+	report := failureReport{
+		Organization: org,
+		Pipeline:     pipeline,
+		Number:       b.Number,
+		State:        b.State,
+		Branch:       b.Branch,
+		Commit:       b.Commit,
+		Message:      singleLineBuildMessage(b.Message),
+		WebURL:       b.WebURL,
+		Jobs:         []failedJob{},
+		TestFailures: []internalpreflight.SummaryTestFailure{},
+		MaxTokens:    c.MaxTokens,
+	}
+
+	rules, err := classify.LoadForRepository(f.GitRepository, c.Rules...)
+	if err != nil {
+		return failureReport{}, err
+	}
+	redactor, err := newLogRedactor(f, c.Redact)
+	if err != nil {
+		return failureReport{}, err
+	}
+
+	var jobs []buildkite.Job
+	for _, j := range b.Jobs {
+		if !isFailedJob(j) {
+			continue
+		}
+		jobs = append(jobs, j)
+		report.Jobs = append(report.Jobs, failedJob{
+			ID:         j.ID,
+			Label:      jobLogLabel(j),
+			StepKey:    j.StepKey,
+			State:      j.State,
+			SoftFailed: j.SoftFailed,
+			ExitStatus: j.ExitStatus,
+			WebURL:     j.WebURL,
+		})
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(downloadWorkerLimit)
+	for i := range report.Jobs {
+		job := &report.Jobs[i]
+		g.Go(func() error {
+			log, _, err := f.RestAPIClient.Jobs.GetJobLog(gctx, org, pipeline, b.ID, job.ID)
+			if err != nil {
+				return fmt.Errorf("fetching log for job %s: %w", job.Label, err)
+			}
+			content := log.Content
+			if redactor != nil {
+				content = redactor.Redact(content)
+			}
+			job.raw = internaljob.StripTimestamps(content)
+			job.Log = c.digest(job.raw, 0)
+			if causes := rules.ClassifyJob(jobs[i], internaljob.LogLineTexts(internaljob.SplitLogLines(content))); len(causes) > 0 {
+				job.Cause = &causes[0]
+			}
+			return nil
+		})
+	}
+
+	var tests internalpreflight.SummaryResult
+	var testsErr error
+	if !c.NoTests && b.TestEngine != nil && len(b.TestEngine.Runs) > 0 {
+		g.Go(func() error {
+			summary, err := internalpreflight.NewRunSummaryService(f.RestAPIClient).Get(gctx, org, b.ID, &internalpreflight.RunSummaryGetOptions{
+				Result:          "^failed",
+				State:           "enabled",
+				IncludeFailures: true,
+			})
+			if err != nil {
+				// Test Engine data is a bonus; the job logs are still worth
+				// reporting without it.
+				testsErr = err
+				return nil
+			}
+			tests = summary.SummaryResult()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return failureReport{}, err
+	}
+	if testsErr != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("could not fetch Test Engine failures: %v", testsErr))
+	}
+	report.TestFailures = append(report.TestFailures, tests.Tests.Failures...)
+	redactTestFailures(report.TestFailures, redactor)
+
+	if c.MaxTokens > 0 {
+		c.fitToBudget(&report)
+	}
+	return report, nil
+}
+
+// redactTestFailures masks secrets in the messages and backtraces of Test
+// Engine failures, which often echo the values a test was given. It does
+// nothing when r is nil.
+func redactTestFailures(failures []internalpreflight.SummaryTestFailure, r *internaljob.Redactor) {
+	if r == nil {
+		return
+	}
+	for i := range failures {
+		t := &failures[i]
+		t.Message = r.Redact(t.Message)
+		t.FailureReason = r.Redact(t.FailureReason)
+		for _, d := range t.FailureDetail {
+			for j, line := range d.Backtrace {
+				d.Backtrace[j] = r.Redact(line)
+			}
+			for j, line := range d.Expanded {
+				d.Expanded[j] = r.Redact(line)
+			}
+		}
+	}
+}
+
+func (c *FailuresCmd) digest(content string, maxTokens int) string {
+	opt := digest.Default()
+	opt.Format = digest.ParseFormat("plain")
+	opt.MaxTokens = maxTokens
+	opt.Window = !c.NoWindow
+	return strings.TrimRight(digest.Process([]byte(content), opt), "\n")
+}
+
+// fitToBudget cuts the report down until its estimated size, in the chosen
+// format, is within --max-tokens. Everything but the logs is measured first
+// and the rest of the budget is shared between the logs. If the report is
+// still too big, logs are dropped, largest first, then test failures and
+// finally jobs, from the end.
+func (c *FailuresCmd) fitToBudget(report *failureReport) {
+	fits := func() bool {
+		return estimateTokens(renderFailureReport(*report, c.Format)) <= c.MaxTokens
+	}
+	if fits() {
+		return
+	}
+
+	logs := make([]string, len(report.Jobs))
+	sizes := make([]int, len(report.Jobs))
+	for i := range report.Jobs {
+		logs[i] = report.Jobs[i].Log
+		sizes[i] = estimateTokens(logs[i])
+		report.Jobs[i].Log = ""
+	}
+	overhead := estimateTokens(renderFailureReport(*report, c.Format))
+
+	limits := allocateTokenBudget(sizes, c.MaxTokens-overhead)
+	for i := range report.Jobs {
+		switch {
+		case limits[i] == 0 && sizes[i] > 0:
+			report.Jobs[i].LogOmitted = true
+		case limits[i] < sizes[i]:
+			report.Jobs[i].Log = c.fitLog(report.Jobs[i].raw, limits[i])
+		default:
+			report.Jobs[i].Log = logs[i]
+		}
+	}
+
+	// The estimates above leave out how each format wraps the logs (JSON
+	// escaping, Markdown fences), so check the whole report again.
+	for !fits() {
+		largest := -1
+		for i, j := range report.Jobs {
+			if j.Log != "" && (largest < 0 || len(j.Log) > len(report.Jobs[largest].Log)) {
+				largest = i
+			}
+		}
+		if largest < 0 {
+			break
+		}
+		report.Jobs[largest].Log = ""
+		report.Jobs[largest].LogOmitted = true
+	}
+	for !fits() && len(report.TestFailures) > 0 {
+		report.TestFailures = report.TestFailures[:len(report.TestFailures)-1]
+		report.OmittedTestFailures++
+	}
+	for !fits() && len(report.Jobs) > 0 {
+		report.Jobs = report.Jobs[:len(report.Jobs)-1]
+		report.OmittedJobs++
+	}
+}
+
+// fitLog digests raw under a limit of tokens. If the digest is still too
+// big, only its end is kept, since that's where failures are usually
+// reported.
+func (c *FailuresCmd) fitLog(raw string, tokens int) string {
+	log := c.digest(raw, tokens)
+	if estimateTokens(log) <= tokens {
+		return log
+	}
+	const marker = "…\n"
+	keep := tokens*4 - len(marker)
+	if keep <= 0 {
+		return ""
+	}
+	cut := len(log) - keep
+	for cut < len(log) && !utf8.RuneStart(log[cut]) {
+		cut++
+	}
+	return marker + log[cut:]
+}
+
+// estimateTokens estimates the number of tokens in s, at the usual four
+// characters per token.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// allocateTokenBudget shares budget between items of the given sizes,
+// giving out no more than budget in all. Items smaller than an even share
+// keep their size, and what they leave over is shared between the rest.
+// When an even share would be under minJobLogTokens, items get
+// minJobLogTokens each while the budget lasts, and nothing after that.
+func allocateTokenBudget(sizes []int, budget int) []int {
+	limits := make([]int, len(sizes))
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return sizes[order[a]] < sizes[order[b]] })
+
+	remaining := max(budget, 0)
+	for k, i := range order {
+		share := remaining / (len(order) - k)
+		switch {
+		case sizes[i] <= share:
+			limits[i] = sizes[i]
+		case share >= minJobLogTokens:
+			limits[i] = share
+		case remaining >= minJobLogTokens:
+			limits[i] = minJobLogTokens
+		}
+		remaining -= limits[i]
+	}
+	return limits
+}
+
+// renderFailureReport returns the report as it's written in format.
+func renderFailureReport(r failureReport, format string) string {
+	var b strings.Builder
+	if format == "json" {
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(r)
+	} else {
+		_ = writeFailureReportMarkdown(&b, r)
+	}
+	return b.String()
+}
+
+// writeFailureReportMarkdown writes the report as Markdown.
+func writeFailureReportMarkdown(w io.Writer, r failureReport) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Failures in %s/%s #%d\n\n", r.Organization, r.Pipeline, r.Number)
+	fmt.Fprintf(&b, "- **State:** %s\n", r.State)
+	if r.Message != "" {
+		fmt.Fprintf(&b, "- **Message:** %s\n", r.Message)
+	}
+	fmt.Fprintf(&b, "- **Branch:** %s\n", r.Branch)
+	fmt.Fprintf(&b, "- **Commit:** %s\n", r.Commit)
+	if r.WebURL != "" {
+		fmt.Fprintf(&b, "- **URL:** %s\n", r.WebURL)
+	}
+
+	fmt.Fprintf(&b, "\n## Failed jobs (%d)\n", len(r.Jobs)+r.OmittedJobs)
+	if len(r.Jobs)+r.OmittedJobs == 0 {
+		b.WriteString("\nNo jobs failed.\n")
+	}
+	for _, j := range r.Jobs {
+		state := j.State
+		if j.SoftFailed {
+			state = "soft failed"
+		}
+		if j.ExitStatus != nil {
+			state += fmt.Sprintf(", exit status %d", *j.ExitStatus)
+		}
+		fmt.Fprintf(&b, "\n### %s (%s)\n\n", j.Label, state)
+		if j.WebURL != "" {
+			fmt.Fprintf(&b, "%s\n\n", j.WebURL)
+		}
+		if j.Cause != nil {
+			fmt.Fprintf(&b, "**Likely cause:** %s\n\n", j.Cause.Label)
+			for _, e := range j.Cause.Evidence {
+				if e.Line > 0 {
+					fmt.Fprintf(&b, "- line %d: `%s`\n", e.Line, strings.ReplaceAll(strings.TrimSpace(e.Text), "`", "'"))
+				} else {
+					fmt.Fprintf(&b, "- %s\n", e.Text)
+				}
+			}
+			b.WriteString("\n")
+		}
+		if j.LogOmitted {
+			b.WriteString("_Log omitted to fit --max-tokens._\n")
+			continue
+		}
+		fence := codeFence(j.Log)
+		fmt.Fprintf(&b, "%s\n%s\n%s\n", fence, j.Log, fence)
+	}
+	if r.OmittedJobs > 0 {
+		fmt.Fprintf(&b, "\n_%d more failed %s omitted to fit --max-tokens._\n", r.OmittedJobs, bkIO.Pluralize("job", r.OmittedJobs))
+	}
+
+	if len(r.TestFailures)+r.OmittedTestFailures > 0 {
+		fmt.Fprintf(&b, "\n## Test failures (%d)\n", len(r.TestFailures)+r.OmittedTestFailures)
+		for _, t := range r.TestFailures {
+			suite := t.SuiteName
+			if suite == "" {
+				suite = t.SuiteSlug
+			}
+			fmt.Fprintf(&b, "\n### %s\n\n", t.Name)
+			if suite != "" {
+				fmt.Fprintf(&b, "- **Suite:** %s\n", suite)
+			}
+			if t.Location != "" {
+				fmt.Fprintf(&b, "- **Location:** %s\n", t.Location)
+			}
+			if t.Message != "" {
+				fmt.Fprintf(&b, "- **Message:** %s\n", strings.TrimSpace(t.Message))
+			}
+			if backtrace := testFailureBacktrace(t); len(backtrace) > 0 {
+				text := strings.Join(backtrace, "\n")
+				fence := codeFence(text)
+				fmt.Fprintf(&b, "\n%s\n%s\n%s\n", fence, text, fence)
+			}
+		}
+		if r.OmittedTestFailures > 0 {
+			fmt.Fprintf(&b, "\n_%d more test %s omitted to fit --max-tokens._\n", r.OmittedTestFailures, bkIO.Pluralize("failure", r.OmittedTestFailures))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// testFailureBacktrace returns the first lines of a test failure's
+// backtrace.
+func testFailureBacktrace(t internalpreflight.SummaryTestFailure) []string {
+	var lines []string
+	for _, d := range t.FailureDetail {
+		lines = append(lines, d.Expanded...)
+		lines = append(lines, d.Backtrace...)
+	}
+	if len(lines) > maxBacktraceLines {
+		lines = append(lines[:maxBacktraceLines], "…")
+	}
+	return lines
+}
+
+// codeFence returns a backtick fence longer than any run of backticks in s,
+// so that log output can't close the code block early.
+func codeFence(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
@@ -0,0 +1,206 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/cli/v3/internal/config"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	internalpreflight "github.com/buildkite/cli/v3/internal/preflight"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/spf13/afero"
+)
+
+func TestFailuresBuildReport(t *testing.T) {
+	t.Parallel()
+
+	exit := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/organizations/acme/pipelines/monolith/builds/build-uuid/jobs/test/log":
+			_ = json.NewEncoder(w).Encode(buildkite.JobLog{Content: "\x1b_bk;t=1700000000000\x07FAIL TestThing\n"})
+		case "/v2/organizations/acme/pipelines/monolith/builds/build-uuid/jobs/lint/log":
+			_ = json.NewEncoder(w).Encode(buildkite.JobLog{Content: "lint warning\n"})
+		case "/v2/analytics/organizations/acme/builds/build-uuid/preflight/v1":
+			if got := r.URL.Query().Get("result"); got != "^failed" {
+				t.Errorf("expected failed tests to be requested, got result=%q", got)
+			}
+			_, _ = w.Write([]byte(`{"tests":{"runs":{},"failures":[{"run_id":"run-1","suite_name":"Unit","name":"TestThing","location":"thing_test.go:12","failure_reason":"expected 1, got 2"}]}}`))
+		default:
+			t.Errorf("unexpected request path %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	b := buildkite.Build{
+		ID:     "build-uuid",
+		Number: 429,
+		State:  "failed",
+		Jobs: []buildkite.Job{
+			{ID: "build", Type: "script", Name: "Build", State: "passed"},
+			{ID: "test", Type: "script", Name: "Test", State: "failed", ExitStatus: &exit},
+			{ID: "lint", Type: "script", Name: "Lint", State: "passed", SoftFailed: true},
+			{ID: "wait", Type: "waiter"},
+		},
+		TestEngine: &buildkite.TestEngineProperty{Runs: []buildkite.TestEngineRun{{ID: "run-1"}}},
+	}
+
+	cmd := &FailuresCmd{}
+	report, err := cmd.buildReport(context.Background(), newBuildTestFactory(t, server.URL), "acme", "monolith", b)
+	if err != nil {
+		t.Fatalf("buildReport() error = %v", err)
+	}
+
+	if len(report.Jobs) != 2 || report.Jobs[0].ID != "test" || report.Jobs[1].ID != "lint" {
+		t.Fatalf("expected the failed and soft failed jobs, got %+v", report.Jobs)
+	}
+	if report.Jobs[0].Log != "FAIL TestThing" {
+		t.Errorf("expected the log without timestamps, got %q", report.Jobs[0].Log)
+	}
+	if c := report.Jobs[0].Cause; c == nil || c.Category != "test" {
+		t.Errorf("expected the failed job to be classified as a test failure, got %+v", c)
+	}
+	if len(report.TestFailures) != 1 || report.TestFailures[0].Name != "TestThing" {
+		t.Errorf("expected the Test Engine failure, got %+v", report.TestFailures)
+	}
+
+	var out strings.Builder
+	if err := writeFailureReportMarkdown(&out, report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Failures in acme/monolith #429",
+		"## Failed jobs (2)",
+		"### Test (failed, exit status 1)",
+		"### Lint (soft failed)",
+		"**Likely cause:** test assertion failure",
+		"```\nFAIL TestThing\n```",
+		"## Test failures (1)",
+		"- **Location:** thing_test.go:12",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestFailuresBuildReportRedactsTestFailures(t *testing.T) {
+	t.Parallel()
+
+	token := "ghp_" + strings.Repeat("a", 36)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/organizations/acme/pipelines/monolith/builds/build-uuid/jobs/test/log":
+			_ = json.NewEncoder(w).Encode(buildkite.JobLog{Content: "FAIL TestLogin\n"})
+		case "/v2/analytics/organizations/acme/builds/build-uuid/preflight/v1":
+			_, _ = fmt.Fprintf(w, `{"tests":{"runs":{},"failures":[{"run_id":"run-1","name":"TestLogin","failure_reason":"login with %[1]s failed","failure_detail":[{"backtrace":["login_test.go:8: token %[1]s"],"expanded":["got 401 for %[1]s"]}]}]}}`, token)
+		default:
+			t.Errorf("unexpected request path %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	b := buildkite.Build{
+		ID:         "build-uuid",
+		Number:     429,
+		State:      "failed",
+		Jobs:       []buildkite.Job{{ID: "test", Type: "script", Name: "Test", State: "failed"}},
+		TestEngine: &buildkite.TestEngineProperty{Runs: []buildkite.TestEngineRun{{ID: "run-1"}}},
+	}
+
+	f := newBuildTestFactory(t, server.URL)
+	f.Config = config.New(afero.NewMemMapFs(), nil)
+	cmd := &FailuresCmd{Redact: true}
+	report, err := cmd.buildReport(context.Background(), f, "acme", "monolith", b)
+	if err != nil {
+		t.Fatalf("buildReport() error = %v", err)
+	}
+
+	if out := renderFailureReport(report, "json"); strings.Contains(out, token) {
+		t.Errorf("expected the token to be redacted, got:\n%s", out)
+	}
+	if got := report.TestFailures[0].Message; got != "login with "+internaljob.Redacted+" failed" {
+		t.Errorf("message = %q", got)
+	}
+}
+
+func TestAllocateTokenBudget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		sizes  []int
+		budget int
+		want   []int
+	}{
+		{"everything fits", []int{100, 200}, 1000, []int{100, 200}},
+		{"small logs leave room for large ones", []int{5000, 200, 3000}, 2200, []int{1000, 200, 1000}},
+		{"floor per job while the budget lasts", []int{5000, 5000, 5000}, 250, []int{minJobLogTokens, minJobLogTokens, 0}},
+		{"too small for any log", []int{5000, 5000}, 50, []int{0, 0}},
+		{"negative budget", []int{500}, -10, []int{0}},
+	}
+	for _, tt := range tests {
+		if got := allocateTokenBudget(tt.sizes, tt.budget); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: allocateTokenBudget(%v, %d) = %v, want %v", tt.name, tt.sizes, tt.budget, got, tt.want)
+		}
+	}
+}
+
+func TestFitToBudget(t *testing.T) {
+	t.Parallel()
+
+	newReport := func() failureReport {
+		r := failureReport{Organization: "acme", Pipeline: "monolith", Number: 429, State: "failed"}
+		for i := range 40 {
+			log := strings.Repeat(fmt.Sprintf("line %d of a long log with \"quotes\"\n", i), 200)
+			r.Jobs = append(r.Jobs, failedJob{ID: fmt.Sprint(i), Label: fmt.Sprintf("Test %d", i), State: "failed", Log: log, raw: log})
+		}
+		for i := range 100 {
+			r.TestFailures = append(r.TestFailures, internalpreflight.SummaryTestFailure{Name: fmt.Sprintf("TestThing%d", i), Message: strings.Repeat("expected 1, got 2 ", 10)})
+		}
+		return r
+	}
+
+	for _, format := range []string{"markdown", "json"} {
+		for _, maxTokens := range []int{200, 2000, 20000} {
+			cmd := &FailuresCmd{Format: format, MaxTokens: maxTokens}
+			r := newReport()
+			cmd.fitToBudget(&r)
+			if got := estimateTokens(renderFailureReport(r, format)); got > maxTokens {
+				t.Errorf("%s with --max-tokens %d: report is %d tokens", format, maxTokens, got)
+			}
+			if len(r.Jobs)+r.OmittedJobs != 40 || len(r.TestFailures)+r.OmittedTestFailures != 100 {
+				t.Errorf("%s with --max-tokens %d: lost count of jobs or test failures: %d+%d, %d+%d", format, maxTokens, len(r.Jobs), r.OmittedJobs, len(r.TestFailures), r.OmittedTestFailures)
+			}
+		}
+	}
+
+	// A generous budget keeps everything.
+	cmd := &FailuresCmd{Format: "markdown", MaxTokens: 1_000_000}
+	r := newReport()
+	cmd.fitToBudget(&r)
+	if r.OmittedJobs != 0 || r.OmittedTestFailures != 0 || r.Jobs[0].LogOmitted {
+		t.Errorf("expected nothing omitted under a generous budget")
+	}
+}
+
+func TestCodeFence(t *testing.T) {
+	t.Parallel()
+
+	if got := codeFence("plain"); got != "```" {
+		t.Errorf("codeFence(plain) = %q", got)
+	}
+	if got := codeFence("has ```` inside"); got != "`````" {
+		t.Errorf("codeFence with backticks = %q", got)
+	}
+}
@@ -0,0 +1,351 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/build"
+	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
+	"github.com/buildkite/cli/v3/internal/build/resolver/options"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	"github.com/buildkite/cli/v3/internal/pipeline"
+	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxGrepBuilds caps --builds, since every job log of every build is
+// downloaded.
+const maxGrepBuilds = 100
+
+type GrepCmd struct {
+	Pattern     string   `arg:"" help:"Regular expression to search job logs for"`
+	BuildNumber string   `arg:"" optional:"" help:"Build number to search (omit for most recent build)"`
+	Pipeline    string   `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
+	Branch      string   `help:"Filter builds to this branch." short:"b"`
+	User        string   `help:"Filter builds to this user. You can use name or email." short:"u" xor:"userfilter"`
+	Mine        bool     `help:"Filter builds to only my user." xor:"userfilter"`
+	Builds      int      `help:"Search the last N builds of the pipeline (on --branch, if given) instead of a single build." default:"0"`
+	State       []string `help:"Only search jobs in these states, e.g. failed,timed_out." short:"s" sep:","`
+	StepKey     []string `help:"Only search jobs for these step keys." name:"step-key" sep:","`
+	Context     int      `help:"Print this many lines of context around each match." short:"C" default:"0"`
+	IgnoreCase  bool     `help:"Match case-insensitively." short:"i"`
+	JSON        bool     `help:"Print one JSON object per match (JSONL)."`
+	Redact      bool     `help:"Mask credentials in job logs before searching them, as with bk build logs --redact."`
+}
+
+func (c *GrepCmd) Help() string {
+	return `Search every job log in a build for a regular expression.
+
+Job logs are fetched in parallel. Each match is printed as
+LABEL:LINE:TEXT, where LABEL is the job's label and LINE the line number in
+its log; context lines use - in place of :. Timestamps and ANSI escape codes
+are removed before matching.
+
+With --builds N, the last N builds of the pipeline are searched instead, and
+each match is also prefixed with its build number. With --json, one JSON
+object is written per match.
+
+Examples:
+  # Find which job printed an error in the most recent build
+  $ bk build grep "connection refused"
+
+  # Search build 429, with two lines of context
+  $ bk build grep -C 2 "panic:" 429 --pipeline my-pipeline
+
+  # Search the failed jobs of the last 20 builds on main
+  $ bk build grep -i "timeout" --builds 20 -b main -s failed
+
+  # Matches as JSON, one per line
+  $ bk build grep "FAIL" 429 --json | jq -r .job_label`
+}
+
+func (c *GrepCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+	f.NoPager = f.NoPager || globals.DisablePager()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	if c.Builds < 0 || c.Builds > maxGrepBuilds {
+		return fmt.Errorf("--builds must be between 0 and %d (requested: %d)", maxGrepBuilds, c.Builds)
+	}
+	if c.Builds > 0 && c.BuildNumber != "" {
+		return fmt.Errorf("a build number cannot be combined with --builds")
+	}
+	if c.Context < 0 {
+		return fmt.Errorf("--context cannot be negative (requested: %d)", c.Context)
+	}
+
+	pattern := c.Pattern
+	if c.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	redactor, err := newLogRedactor(f, c.Redact)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	pipelineRes := pipelineResolver.NewAggregateResolver(
+		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
+		pipelineResolver.ResolveFromConfig(f.Config, pipelineResolver.PickOneWithFactory(f)),
+		pipelineResolver.ResolveFromRepository(f, pipelineResolver.CachedPicker(f.Config, pipelineResolver.PickOneWithFactory(f))),
+	)
+
+	var builds []grepBuild
+	if c.Builds > 0 {
+		p, err := pipelineRes.Resolve(ctx)
+		if err != nil {
+			return err
+		}
+		// recentBuilds shows its own progress as it pages.
+		builds, err = recentBuilds(ctx, f, p, c.Branch, c.Builds)
+		if err != nil {
+			return err
+		}
+	} else {
+		optionsResolver := options.AggregateResolver{
+			options.ResolveBranchFromFlag(c.Branch),
+			options.ResolveBranchFromRepository(f.GitRepository),
+		}.WithResolverWhen(
+			c.User != "",
+			options.ResolveUserFromFlag(c.User),
+		).WithResolverWhen(
+			c.Mine || c.User == "",
+			options.ResolveCurrentUser(ctx, f),
+		)
+
+		args := []string{}
+		if c.BuildNumber != "" {
+			args = []string{c.BuildNumber}
+		}
+		buildRes := buildResolver.NewAggregateResolver(
+			buildResolver.ResolveFromPositionalArgument(args, 0, pipelineRes.Resolve, f.Config),
+			buildResolver.ResolveBuildWithOpts(f, pipelineRes.Resolve, optionsResolver...),
+		)
+
+		bld, err := buildRes.Resolve(ctx)
+		if err != nil {
+			return err
+		}
+		if bld == nil {
+			fmt.Println("No build found.")
+			return nil
+		}
+		builds = []grepBuild{{organization: bld.Organization, pipeline: bld.Pipeline, number: bld.BuildNumber}}
+	}
+
+	var w io.Writer = os.Stdout
+	if !c.JSON {
+		writer, cleanup := bkIO.Pager(f.NoPager, f.Config.Pager())
+		defer func() { _ = cleanup() }()
+		w = writer
+	}
+
+	filter := jobLogFilter{states: c.State, stepKeys: c.StepKey}
+	total := 0
+	for _, gb := range builds {
+		var logs []jobLog
+		if err = bkIO.SpinWhile(f, fmt.Sprintf("Searching build #%d", gb.number), func() error {
+			logs, err = gb.fetchLogs(ctx, f, filter)
+			return err
+		}); err != nil {
+			return err
+		}
+		redactJobLogs(logs, redactor)
+
+		n, err := c.writeMatches(w, gb, logs, re, c.Builds > 0)
+		if err != nil {
+			return err
+		}
+		total += n
+	}
+
+	if total == 0 && !c.JSON {
+		fmt.Fprintln(os.Stderr, "No matches found.")
+	}
+	return nil
+}
+
+// grepBuild is a build to search. When build is set it was listed with its
+// jobs and is searched without fetching it again.
+type grepBuild struct {
+	organization string
+	pipeline     string
+	number       int
+	build        *buildkite.Build
+}
+
+func (gb grepBuild) fetchLogs(ctx context.Context, f *factory.Factory, filter jobLogFilter) ([]jobLog, error) {
+	if gb.build != nil {
+		return fetchBuildJobLogs(ctx, f, gb.organization, gb.pipeline, *gb.build, filter)
+	}
+	return fetchJobLogs(ctx, f, &build.Build{Organization: gb.organization, Pipeline: gb.pipeline, BuildNumber: gb.number}, filter)
+}
+
+// recentBuilds lists the last n builds of a pipeline, optionally on a single
+// branch, newest first.
+func recentBuilds(ctx context.Context, f *factory.Factory, p *pipeline.Pipeline, branch string, n int) ([]grepBuild, error) {
+	list := ListCmd{Limit: n}
+	if branch != "" {
+		list.Branch = []string{branch}
+	}
+	listed, err := list.fetchLastBuilds(ctx, f, p, false)
+	if err != nil {
+		return nil, err
+	}
+
+	builds := make([]grepBuild, 0, len(listed))
+	for i := range listed {
+		builds = append(builds, grepBuild{organization: p.Org, pipeline: p.Name, number: listed[i].Number, build: &listed[i]})
+	}
+	return builds, nil
+}
+
+// grepMatch is a matching log line, as written by --json.
+type grepMatch struct {
+	Organization string            `json:"organization"`
+	Pipeline     string            `json:"pipeline"`
+	BuildNumber  int               `json:"build_number"`
+	JobID        string            `json:"job_id"`
+	JobLabel     string            `json:"job_label"`
+	StepKey      string            `json:"step_key,omitempty"`
+	JobURL       string            `json:"job_url,omitempty"`
+	LineNumber   int               `json:"line_number"`
+	Line         string            `json:"line"`
+	Before       []grepContextLine `json:"before,omitempty"`
+	After        []grepContextLine `json:"after,omitempty"`
+}
+
+type grepContextLine struct {
+	LineNumber int    `json:"line_number"`
+	Line       string `json:"line"`
+}
+
+// writeMatches writes the matches in a build's logs and returns how many
+// lines matched.
+func (c *GrepCmd) writeMatches(w io.Writer, gb grepBuild, logs []jobLog, re *regexp.Regexp, withBuild bool) (int, error) {
+	var enc *json.Encoder
+	if c.JSON {
+		enc = json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+	}
+
+	total := 0
+	for _, l := range logs {
+		lines := make([]string, len(l.lines))
+		for i, line := range l.lines {
+			lines[i] = ansi.Strip(line.Text)
+		}
+		matches := matchingLines(lines, re)
+		total += len(matches)
+		if len(matches) == 0 {
+			continue
+		}
+
+		if enc != nil {
+			for _, i := range matches {
+				m := grepMatch{
+					Organization: gb.organization,
+					Pipeline:     gb.pipeline,
+					BuildNumber:  gb.number,
+					JobID:        l.job.ID,
+					JobLabel:     l.label,
+					StepKey:      l.job.StepKey,
+					JobURL:       l.job.WebURL,
+					LineNumber:   i + 1,
+					Line:         lines[i],
+					Before:       contextLines(lines, max(i-c.Context, 0), i),
+					After:        contextLines(lines, i+1, min(i+1+c.Context, len(lines))),
+				}
+				if err := enc.Encode(m); err != nil {
+					return total, err
+				}
+			}
+			continue
+		}
+
+		prefix := l.label
+		if withBuild {
+			prefix = fmt.Sprintf("#%d %s", gb.number, l.label)
+		}
+		if err := writeGrepLines(w, prefix, lines, matches, c.Context); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// matchingLines returns the indexes of the lines that match re.
+func matchingLines(lines []string, re *regexp.Regexp) []int {
+	var matches []int
+	for i, line := range lines {
+		if re.MatchString(line) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+func contextLines(lines []string, from, to int) []grepContextLine {
+	var out []grepContextLine
+	for i := from; i < to; i++ {
+		out = append(out, grepContextLine{LineNumber: i + 1, Line: lines[i]})
+	}
+	return out
+}
+
+// writeGrepLines writes matching lines as PREFIX:LINE:TEXT with n lines of
+// context as PREFIX-LINE-TEXT, separating non-adjacent groups with -- as grep
+// does.
+func writeGrepLines(w io.Writer, prefix string, lines []string, matches []int, n int) error {
+	matched := make(map[int]bool, len(matches))
+	for _, i := range matches {
+		matched[i] = true
+	}
+
+	next := 0 // first line not yet written
+	for _, m := range matches {
+		from := max(m-n, next)
+		if n > 0 && next > 0 && from > next {
+			if _, err := fmt.Fprintln(w, "--"); err != nil {
+				return err
+			}
+		}
+		to := min(m+n+1, len(lines))
+		for i := from; i < to; i++ {
+			sep := "-"
+			if matched[i] {
+				sep = ":"
+			}
+			if _, err := fmt.Fprintf(w, "%s%s%d%s%s\n", prefix, sep, i+1, sep, lines[i]); err != nil {
+				return err
+			}
+		}
+		next = max(next, to)
+	}
+	return nil
+}
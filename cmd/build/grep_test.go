@@ -0,0 +1,101 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	"github.com/buildkite/cli/v3/internal/pipeline"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestWriteGrepLines(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"one", "error a", "three", "four", "five", "six", "error b", "eight"}
+	matches := matchingLines(lines, regexp.MustCompile("error"))
+	if len(matches) != 2 || matches[0] != 1 || matches[1] != 6 {
+		t.Fatalf("matchingLines() = %v, want [1 6]", matches)
+	}
+
+	var out strings.Builder
+	if err := writeGrepLines(&out, "Test", lines, matches, 1); err != nil {
+		t.Fatal(err)
+	}
+	want := "Test-1-one\nTest:2:error a\nTest-3-three\n--\nTest-6-six\nTest:7:error b\nTest-8-eight\n"
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	// Overlapping context is written once, without a separator.
+	out.Reset()
+	if err := writeGrepLines(&out, "Test", lines, matches, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); strings.Contains(got, "--") || strings.Count(got, "\n") != len(lines) {
+		t.Errorf("expected every line once with no separator, got\n%s", got)
+	}
+}
+
+func TestGrepWriteMatchesJSON(t *testing.T) {
+	t.Parallel()
+
+	logs := []jobLog{{
+		job:   buildkite.Job{ID: "job-1", StepKey: "test", WebURL: "https://buildkite.com/acme/monolith/builds/429#job-1"},
+		label: "Test",
+		lines: []internaljob.LogLine{{Text: "setup"}, {Text: "\x1b[31mFAIL\x1b[0m widgets"}, {Text: "done"}},
+	}}
+
+	cmd := &GrepCmd{JSON: true, Context: 1}
+	var out strings.Builder
+	n, err := cmd.writeMatches(&out, grepBuild{organization: "acme", pipeline: "monolith", number: 429}, logs, regexp.MustCompile("FAIL"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 match, got %d", n)
+	}
+
+	var m grepMatch
+	if err := json.Unmarshal([]byte(out.String()), &m); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	if m.BuildNumber != 429 || m.JobID != "job-1" || m.JobLabel != "Test" || m.LineNumber != 2 || m.Line != "FAIL widgets" {
+		t.Errorf("unexpected match: %+v", m)
+	}
+	if len(m.Before) != 1 || m.Before[0].Line != "setup" || len(m.After) != 1 || m.After[0].LineNumber != 3 {
+		t.Errorf("unexpected context: before=%+v after=%+v", m.Before, m.After)
+	}
+}
+
+func TestRecentBuilds(t *testing.T) {
+	t.Parallel()
+
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/organizations/acme/pipelines/monolith/builds" {
+			t.Errorf("unexpected request path %s", r.URL.Path)
+		}
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]buildkite.Build{{Number: 12}, {Number: 11}, {Number: 10}})
+	}))
+	defer server.Close()
+
+	f := newBuildTestFactory(t, server.URL)
+	builds, err := recentBuilds(context.Background(), f, &pipeline.Pipeline{Org: "acme", Name: "monolith"}, "main", 2)
+	if err != nil {
+		t.Fatalf("recentBuilds() error = %v", err)
+	}
+	if len(builds) != 2 || builds[0].number != 12 || builds[1].number != 11 || builds[0].build == nil {
+		t.Errorf("unexpected builds: %+v", builds)
+	}
+	if len(queries) != 1 || !strings.Contains(queries[0], "branch") || strings.Contains(queries[0], "include_retried_jobs") {
+		t.Errorf("unexpected list queries: %v", queries)
+	}
+}
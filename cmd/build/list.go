@@ -11,9 +11,11 @@ import (
 	"time"
 
 	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/build"
 	"github.com/buildkite/cli/v3/internal/cli"
 	"github.com/buildkite/cli/v3/internal/graphql"
 	bkIO "github.com/buildkite/cli/v3/internal/io"
+	"github.com/buildkite/cli/v3/internal/pipeline"
 	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
 	"github.com/buildkite/cli/v3/pkg/cmd/factory"
 	"github.com/buildkite/cli/v3/pkg/cmd/validation"
@@ -32,7 +34,7 @@ type ListCmd struct {
 	Until    string            `help:"Filter builds created before this time (e.g. 1h, 30m)"`
 	Duration string            `help:"Filter by duration (e.g. >5m, <10m, 20m) - supports >, <, >=, <= operators"`
 	State    []string          `help:"Filter by build state"`
-	Branch   []string          `help:"Filter by branch name"`
+	Branch   []string          `help:"Filter by branch name. Supports * and ? wildcards, e.g. feature/*"`
 	Creator  string            `help:"Filter by creator (email address or user ID)"`
 	Commit   string            `help:"Filter by commit SHA"`
 	Message  string            `help:"Filter by message content"`
@@ -50,7 +52,7 @@ This command supports both server-side filtering (fast) and client-side filterin
 Server-side filters are applied by the Buildkite API, while client-side filters
 are applied after fetching results and may require loading more builds.
 
-Client-side filters: --duration, --message
+Client-side filters: --duration, --message, --branch with wildcards
 Server-side filters: --pipeline, --since, --until, --state, --branch, --creator, --commit, --meta-data
 
 Builds can be filtered by their duration, message content, and other attributes.
@@ -76,6 +78,9 @@ Examples:
   # List builds on main branch
   $ bk build list --branch main
 
+  # List builds on any release branch
+  $ bk build list --branch "release/*"
+
   # List builds by alice
   $ bk build list --creator alice@company.com
 
@@ -235,7 +240,11 @@ func (c *ListCmd) buildListOptions() (*buildkite.BuildsListOptions, error) {
 		}
 	}
 
-	listOpts.Branch = c.Branch
+	// The API only matches branches exactly, so wildcards are matched
+	// client-side instead.
+	if !build.HasBranchWildcard(c.Branch) {
+		listOpts.Branch = c.Branch
+	}
 	listOpts.Creator = c.Creator
 	listOpts.Commit = c.Commit
 
@@ -275,7 +284,7 @@ func (c *ListCmd) fetchBuilds(ctx context.Context, f *factory.Factory, org strin
 		if c.Pipeline != "" {
 			spinnerMsg += fmt.Sprintf("pipeline %s, ", c.Pipeline)
 		}
-		filtersActive := c.Duration != "" || c.Message != ""
+		filtersActive := c.Duration != "" || c.Message != "" || build.HasBranchWildcard(c.Branch)
 
 		// Show matching (filtered) counts and raw counts independently
 		if !c.NoLimit && c.Limit > 0 {
@@ -388,6 +397,20 @@ func (c *ListCmd) fetchBuilds(ctx context.Context, f *factory.Factory, org strin
 	return allBuilds, nil
 }
 
+// fetchLastBuilds returns the last c.Limit builds of p matching c's filters,
+// newest first. It pages and filters as bk build list does, but prints
+// nothing and never stops to ask whether to keep paging.
+func (c ListCmd) fetchLastBuilds(ctx context.Context, f *factory.Factory, p *pipeline.Pipeline, includeRetriedJobs bool) ([]buildkite.Build, error) {
+	c.Pipeline = p.Org + "/" + p.Name
+	listOpts, err := c.buildListOptions()
+	if err != nil {
+		return nil, err
+	}
+	listOpts.ExcludePipeline = true
+	listOpts.IncludeRetriedJobs = includeRetriedJobs
+	return c.fetchBuilds(ctx, f, p.Org, listOpts, output.FormatJSON, nil)
+}
+
 func (c *ListCmd) getBuildsByPipeline(ctx context.Context, f *factory.Factory, org string, listOpts *buildkite.BuildsListOptions) ([]buildkite.Build, error) {
 	pipelineRes := pipelineResolver.NewAggregateResolver(
 		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
@@ -404,7 +427,7 @@ func (c *ListCmd) getBuildsByPipeline(ctx context.Context, f *factory.Factory, o
 }
 
 func (c *ListCmd) applyClientSideFilters(builds []buildkite.Build) ([]buildkite.Build, error) {
-	if c.Duration == "" && c.Message == "" {
+	if c.Duration == "" && c.Message == "" && !build.HasBranchWildcard(c.Branch) {
 		return builds, nil
 	}
 
@@ -436,18 +459,20 @@ func (c *ListCmd) applyClientSideFilters(builds []buildkite.Build) ([]buildkite.
 		messageFilter = strings.ToLower(c.Message)
 	}
 
+	branches := build.BranchPattern(c.Branch)
+
 	var result []buildkite.Build
-	for _, build := range builds {
+	for _, b := range builds {
 		if c.Duration != "" {
-			if build.StartedAt == nil {
+			if b.StartedAt == nil {
 				continue
 			}
 
 			var elapsed time.Duration
-			if build.FinishedAt != nil {
-				elapsed = build.FinishedAt.Sub(build.StartedAt.Time)
+			if b.FinishedAt != nil {
+				elapsed = b.FinishedAt.Sub(b.StartedAt.Time)
 			} else {
-				elapsed = time.Since(build.StartedAt.Time)
+				elapsed = time.Since(b.StartedAt.Time)
 			}
 
 			switch durationOp {
@@ -467,12 +492,16 @@ func (c *ListCmd) applyClientSideFilters(builds []buildkite.Build) ([]buildkite.
 		}
 
 		if messageFilter != "" {
-			if !strings.Contains(strings.ToLower(build.Message), messageFilter) {
+			if !strings.Contains(strings.ToLower(b.Message), messageFilter) {
 				continue
 			}
 		}
 
-		result = append(result, build)
+		if branches != nil && !branches.MatchString(b.Branch) {
+			continue
+		}
+
+		result = append(result, b)
 	}
 
 	return result, nil
@@ -521,35 +550,35 @@ func displayBuilds(builds []buildkite.Build, format output.Format, writer io.Wri
 
 	var rows [][]string
 
-	for _, build := range builds {
-		message := truncateBuildMessage(build.Message)
+	for _, b := range builds {
+		message := truncateBuildMessage(b.Message)
 
 		startedAt := "-"
-		if build.StartedAt != nil {
-			startedAt = build.StartedAt.Format(timeFormat)
+		if b.StartedAt != nil {
+			startedAt = b.StartedAt.Format(timeFormat)
 		}
 
 		finishedAt := "-"
 		duration := "-"
-		if build.FinishedAt != nil {
-			finishedAt = build.FinishedAt.Format(timeFormat)
-			if build.StartedAt != nil {
-				dur := build.FinishedAt.Sub(build.StartedAt.Time)
+		if b.FinishedAt != nil {
+			finishedAt = b.FinishedAt.Format(timeFormat)
+			if b.StartedAt != nil {
+				dur := b.FinishedAt.Sub(b.StartedAt.Time)
 				duration = formatDuration(dur)
 			}
-		} else if build.StartedAt != nil {
-			dur := time.Since(build.StartedAt.Time)
+		} else if b.StartedAt != nil {
+			dur := time.Since(b.StartedAt.Time)
 			duration = formatDuration(dur) + " (running)"
 		}
 
 		rows = append(rows, []string{
-			fmt.Sprintf("%d", build.Number),
-			build.State,
+			fmt.Sprintf("%d", b.Number),
+			b.State,
 			message,
 			startedAt,
 			finishedAt,
 			duration,
-			build.WebURL,
+			b.WebURL,
 		})
 	}
 
@@ -569,14 +598,14 @@ func displayBuilds(builds []buildkite.Build, format output.Format, writer io.Wri
 
 func displaySummaryTable(builds []buildkite.Build, writer io.Writer) error {
 	var rows [][]string
-	for _, build := range builds {
+	for _, b := range builds {
 		rows = append(rows, []string{
-			fmt.Sprintf("%d", build.Number),
-			build.State,
-			truncateBuildMessage(singleLineBuildMessage(build.Message)),
-			build.Branch,
-			build.Commit,
-			build.WebURL,
+			fmt.Sprintf("%d", b.Number),
+			b.State,
+			truncateBuildMessage(singleLineBuildMessage(b.Message)),
+			b.Branch,
+			b.Commit,
+			b.WebURL,
 		})
 	}
 
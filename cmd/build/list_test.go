@@ -3,6 +3,7 @@ package build
 import (
 	"bytes"
 	"encoding/json"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -208,3 +209,47 @@ func TestFilterBuilds(t *testing.T) {
 		t.Errorf("Expected 1 build with 'Fast', got %d", len(filtered))
 	}
 }
+
+func TestBuildListOptions_BranchWildcard(t *testing.T) {
+	cmd := &ListCmd{Branch: []string{"main"}}
+	opts, err := cmd.buildListOptions()
+	if err != nil {
+		t.Fatalf("buildListOptions failed: %v", err)
+	}
+	if len(opts.Branch) != 1 || opts.Branch[0] != "main" {
+		t.Errorf("Expected exact branch to be filtered server-side, got %v", opts.Branch)
+	}
+
+	cmd = &ListCmd{Branch: []string{"main", "feature/*"}}
+	opts, err = cmd.buildListOptions()
+	if err != nil {
+		t.Fatalf("buildListOptions failed: %v", err)
+	}
+	if len(opts.Branch) != 0 {
+		t.Errorf("Expected wildcard branches to be filtered client-side, got %v", opts.Branch)
+	}
+}
+
+func TestFilterBuilds_BranchWildcard(t *testing.T) {
+	builds := []buildkite.Build{
+		{Number: 1, Branch: "main"},
+		{Number: 2, Branch: "feature/login"},
+		{Number: 3, Branch: "feature/auth/sso"},
+		{Number: 4, Branch: "fix-1"},
+		{Number: 5, Branch: "fix-12"},
+	}
+
+	cmd := &ListCmd{Branch: []string{"main", "feature/*", "fix-?"}}
+	filtered, err := cmd.applyClientSideFilters(builds)
+	if err != nil {
+		t.Fatalf("applyClientSideFilters failed: %v", err)
+	}
+
+	var numbers []int
+	for _, b := range filtered {
+		numbers = append(numbers, b.Number)
+	}
+	if want := []int{1, 2, 3, 4}; !slices.Equal(numbers, want) {
+		t.Errorf("Expected builds %v, got %v", want, numbers)
+	}
+}
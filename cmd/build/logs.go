@@ -0,0 +1,385 @@
+package build
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/build"
+	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
+	"github.com/buildkite/cli/v3/internal/build/resolver/options"
+	"github.com/buildkite/cli/v3/internal/build/watch"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxLogLabelWidth caps the width of the job label column in merged log
+// output so one long step label doesn't push every line off screen.
+const maxLogLabelWidth = 32
+
+type LogsCmd struct {
+	BuildNumber string   `arg:"" optional:"" help:"Build number to get logs for (omit for most recent build)"`
+	Pipeline    string   `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
+	Branch      string   `help:"Filter builds to this branch." short:"b"`
+	User        string   `help:"Filter builds to this user. You can use name or email." short:"u" xor:"userfilter"`
+	Mine        bool     `help:"Filter builds to only my user." xor:"userfilter"`
+	State       []string `help:"Only include jobs in these states, e.g. failed,timed_out." short:"s" sep:","`
+	StepKey     []string `help:"Only include jobs for these step keys." name:"step-key" sep:","`
+	Match       string   `help:"Only print lines matching this regular expression."`
+	Timestamps  bool     `help:"Prefix each line with the time it was logged."`
+	Redact      bool     `help:"Mask credentials such as AWS keys, GitHub and Buildkite tokens, JWTs and private keys, plus redact_patterns from config."`
+	Format      string   `help:"Output format: text for one merged stream, or html for a standalone page with each job's log in collapsible sections." enum:"text,html" default:"text"`
+}
+
+func (c *LogsCmd) Help() string {
+	return `Print the logs of every job in a build as one chronological stream.
+
+Job logs are fetched concurrently and merged line by line using the
+timestamps Buildkite records for each line. Every line is prefixed with the
+label of the job that logged it.
+
+With --format html, each job's log is written as its own collapsible block
+instead, keeping ANSI colours and the log's --- and +++ sections, as a single
+HTML file that can be viewed offline.
+
+Examples:
+  # Merged logs for the most recent build on the current branch
+  $ bk build logs
+
+  # Merged logs for build 429
+  $ bk build logs 429 --pipeline my-pipeline
+
+  # Only the failed jobs
+  $ bk build logs 429 -s failed,timed_out
+
+  # Only the jobs for some steps, with the time each line was logged
+  $ bk build logs 429 --step-key test,lint --timestamps
+
+  # Only lines mentioning an error
+  $ bk build logs 429 --match "(?i)error"
+
+  # Mask credentials before sharing the output
+  $ bk build logs 429 --redact
+
+  # Save the failed jobs' logs as an HTML page
+  $ bk build logs 429 -s failed --format html > build-429.html`
+}
+
+func (c *LogsCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+	f.NoPager = f.NoPager || globals.DisablePager()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	redactor, err := newLogRedactor(f, c.Redact)
+	if err != nil {
+		return err
+	}
+
+	if c.Format == "html" && c.Match != "" {
+		return fmt.Errorf("--match can't be combined with --format html")
+	}
+
+	var match *regexp.Regexp
+	if c.Match != "" {
+		if match, err = regexp.Compile(c.Match); err != nil {
+			return fmt.Errorf("invalid --match pattern: %w", err)
+		}
+	}
+
+	ctx := context.Background()
+
+	pipelineRes := pipelineResolver.NewAggregateResolver(
+		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
+		pipelineResolver.ResolveFromConfig(f.Config, pipelineResolver.PickOneWithFactory(f)),
+		pipelineResolver.ResolveFromRepository(f, pipelineResolver.CachedPicker(f.Config, pipelineResolver.PickOneWithFactory(f))),
+	)
+
+	optionsResolver := options.AggregateResolver{
+		options.ResolveBranchFromFlag(c.Branch),
+		options.ResolveBranchFromRepository(f.GitRepository),
+	}.WithResolverWhen(
+		c.User != "",
+		options.ResolveUserFromFlag(c.User),
+	).WithResolverWhen(
+		c.Mine || c.User == "",
+		options.ResolveCurrentUser(ctx, f),
+	)
+
+	args := []string{}
+	if c.BuildNumber != "" {
+		args = []string{c.BuildNumber}
+	}
+	buildRes := buildResolver.NewAggregateResolver(
+		buildResolver.ResolveFromPositionalArgument(args, 0, pipelineRes.Resolve, f.Config),
+		buildResolver.ResolveBuildWithOpts(f, pipelineRes.Resolve, optionsResolver...),
+	)
+
+	bld, err := buildRes.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	if bld == nil {
+		fmt.Println("No build found.")
+		return nil
+	}
+
+	var logs []jobLog
+	if err = bkIO.SpinWhile(f, "Fetching job logs", func() error {
+		logs, err = fetchJobLogs(ctx, f, bld, jobLogFilter{states: c.State, stepKeys: c.StepKey})
+		return err
+	}); err != nil {
+		return err
+	}
+	redactJobLogs(logs, redactor)
+
+	if c.Format == "html" {
+		return internaljob.WriteLogHTML(os.Stdout, buildLogsHTMLDocument(bld, logs))
+	}
+
+	writer, cleanup := bkIO.Pager(f.NoPager, f.Config.Pager())
+	defer func() { _ = cleanup() }()
+
+	return writeMergedLogs(writer, mergeJobLogs(logs), match, c.Timestamps)
+}
+
+// jobLogFilter selects the jobs whose logs are fetched. Empty fields match
+// every job.
+type jobLogFilter struct {
+	states   []string
+	stepKeys []string
+}
+
+func (f jobLogFilter) matches(j buildkite.Job) bool {
+	// Only script (command) jobs have logs.
+	if j.Type != "script" {
+		return false
+	}
+	if len(f.states) > 0 && !slices.Contains(f.states, j.State) {
+		return false
+	}
+	if len(f.stepKeys) > 0 && !slices.Contains(f.stepKeys, j.StepKey) {
+		return false
+	}
+	return true
+}
+
+// jobLog is the log of a single job, split into timestamped lines.
+type jobLog struct {
+	job   buildkite.Job
+	label string
+	lines []internaljob.LogLine
+}
+
+// fetchJobLogs fetches the logs of the build's jobs that match filter, in job
+// order, with at most downloadWorkerLimit requests in flight.
+func fetchJobLogs(ctx context.Context, f *factory.Factory, bld *build.Build, filter jobLogFilter) ([]jobLog, error) {
+	getOpts := &buildkite.BuildGetOptions{
+		BuildsListOptions: buildkite.BuildsListOptions{ExcludePipeline: true},
+	}
+	b, _, err := f.RestAPIClient.Builds.Get(ctx, bld.Organization, bld.Pipeline, fmt.Sprint(bld.BuildNumber), getOpts)
+	if err != nil {
+		return nil, err
+	}
+	return fetchBuildJobLogs(ctx, f, bld.Organization, bld.Pipeline, b, filter)
+}
+
+// fetchBuildJobLogs is fetchJobLogs for a build that has already been
+// fetched with its jobs.
+func fetchBuildJobLogs(ctx context.Context, f *factory.Factory, org, pipeline string, b buildkite.Build, filter jobLogFilter) ([]jobLog, error) {
+	var jobs []buildkite.Job
+	for _, j := range b.Jobs {
+		if filter.matches(j) {
+			jobs = append(jobs, j)
+		}
+	}
+
+	logs := make([]jobLog, len(jobs))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(downloadWorkerLimit)
+	for i, j := range jobs {
+		g.Go(func() error {
+			log, _, err := f.RestAPIClient.Jobs.GetJobLog(ctx, org, pipeline, b.ID, j.ID)
+			if err != nil {
+				return fmt.Errorf("fetching log for job %s: %w", jobLogLabel(j), err)
+			}
+			logs[i] = jobLog{job: j, label: jobLogLabel(j), lines: internaljob.SplitLogLines(log.Content)}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// newLogRedactor returns a redactor for log output, with the patterns from
+// config, when enabled is set, and nil otherwise.
+func newLogRedactor(f *factory.Factory, enabled bool) (*internaljob.Redactor, error) {
+	if !enabled {
+		return nil, nil
+	}
+	return internaljob.NewRedactor(f.Config.RedactPatterns())
+}
+
+// redactJobLogs masks secrets in the lines of each log. It does nothing when
+// r is nil.
+func redactJobLogs(logs []jobLog, r *internaljob.Redactor) {
+	if r == nil {
+		return
+	}
+	for i := range logs {
+		logs[i].lines = r.RedactLines(logs[i].lines)
+	}
+}
+
+// buildLogsHTMLDocument returns the logs of a build's jobs as an HTML page,
+// with the logs of unsuccessful jobs expanded.
+func buildLogsHTMLDocument(bld *build.Build, logs []jobLog) internaljob.HTMLDocument {
+	doc := internaljob.HTMLDocument{
+		Title:      fmt.Sprintf("%s/%s #%d", bld.Organization, bld.Pipeline, bld.BuildNumber),
+		Subtitle:   fmt.Sprintf("%d job logs", len(logs)),
+		Timestamps: true,
+	}
+	if len(logs) == 1 {
+		doc.Subtitle = "1 job log"
+	}
+	for _, l := range logs {
+		meta := l.job.State
+		if l.job.ExitStatus != nil {
+			meta = fmt.Sprintf("%s, exit status %d", meta, *l.job.ExitStatus)
+		}
+		doc.Logs = append(doc.Logs, internaljob.HTMLLog{
+			Title:    l.label,
+			Meta:     meta,
+			Open:     l.job.State != "passed",
+			Sections: internaljob.SectionLogLines(l.lines),
+		})
+	}
+	return doc
+}
+
+// jobLogLabel labels a job's lines in merged output. Parallel jobs share a
+// label, so they are told apart by their index.
+func jobLogLabel(j buildkite.Job) string {
+	label := watch.NewFormattedJob(j).DisplayName()
+	if j.ParallelGroupIndex != nil {
+		label = fmt.Sprintf("%s #%d", label, *j.ParallelGroupIndex+1)
+	}
+	return label
+}
+
+// mergedLogLine is a line of merged build log output.
+type mergedLogLine struct {
+	label string
+	internaljob.LogLine
+}
+
+// mergeJobLogs interleaves the lines of several job logs in time order. Each
+// job's lines keep their relative order, and lines logged at the same time
+// are ordered by job.
+func mergeJobLogs(logs []jobLog) []mergedLogLine {
+	total := 0
+	h := make(logCursorHeap, 0, len(logs))
+	for i, l := range logs {
+		total += len(l.lines)
+		if len(l.lines) > 0 {
+			h = append(h, logCursor{log: i, time: l.lines[0].Time})
+		}
+	}
+	heap.Init(&h)
+
+	merged := make([]mergedLogLine, 0, total)
+	for h.Len() > 0 {
+		c := &h[0]
+		l := logs[c.log]
+		merged = append(merged, mergedLogLine{label: l.label, LogLine: l.lines[c.line]})
+		c.line++
+		if c.line < len(l.lines) {
+			c.time = l.lines[c.line].Time
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return merged
+}
+
+// writeMergedLogs writes merged lines prefixed with their job label, keeping
+// only lines that match when match is non-nil.
+func writeMergedLogs(w io.Writer, lines []mergedLogLine, match *regexp.Regexp, timestamps bool) error {
+	width := 0
+	for _, l := range lines {
+		width = max(width, len([]rune(l.label)))
+	}
+	width = min(width, maxLogLabelWidth)
+
+	for _, l := range lines {
+		if match != nil && !match.MatchString(l.Text) {
+			continue
+		}
+		label := []rune(l.label)
+		if len(label) > width {
+			label = append(label[:width-1], '…')
+		}
+		prefix := fmt.Sprintf("%-*s | ", width, string(label))
+		if timestamps {
+			prefix = l.Time.UTC().Format(time.RFC3339Nano) + " " + prefix
+		}
+		if _, err := fmt.Fprintln(w, prefix+l.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logCursor is the position of the next unmerged line in one job's log.
+type logCursor struct {
+	log  int
+	line int
+	time time.Time
+}
+
+// logCursorHeap orders cursors by the time of their next line, then by job.
+type logCursorHeap []logCursor
+
+func (h logCursorHeap) Len() int { return len(h) }
+
+func (h logCursorHeap) Less(i, j int) bool {
+	if !h[i].time.Equal(h[j].time) {
+		return h[i].time.Before(h[j].time)
+	}
+	return h[i].log < h[j].log
+}
+
+func (h logCursorHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *logCursorHeap) Push(x any) { *h = append(*h, x.(logCursor)) }
+
+func (h *logCursorHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
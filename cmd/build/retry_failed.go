@@ -0,0 +1,214 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/alecthomas/kong"
+	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
+	"github.com/buildkite/cli/v3/internal/build/resolver/options"
+	"github.com/buildkite/cli/v3/internal/build/watch"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/mattn/go-isatty"
+)
+
+type RetryFailedCmd struct {
+	BuildNumber string        `arg:"" optional:"" help:"Build number to retry the failed jobs of (omit for most recent build)"`
+	Pipeline    string        `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
+	Branch      string        `help:"Filter builds to this branch." short:"b"`
+	User        string        `help:"Filter builds to this user. You can use name or email." short:"u" xor:"userfilter"`
+	Mine        bool          `help:"Filter builds to only my user." xor:"userfilter"`
+	SoftFailed  bool          `help:"Also retry jobs that soft failed." name:"soft-failed"`
+	Canceled    bool          `help:"Also retry jobs that were canceled."`
+	Limit       int64         `help:"Limit parallel API requests" short:"l" default:"5"`
+	Watch       bool          `help:"Watch the build after retrying and exit with a status reflecting its outcome, as bk build watch does."`
+	Interval    int           `help:"Polling interval in seconds when watching" default:"1"`
+	Timeout     time.Duration `help:"Stop watching after this long (e.g. 30m) and exit with the timed out status. Requires --watch."`
+}
+
+func (c *RetryFailedCmd) Help() string {
+	return `Retry every failed job in a build.
+
+Command jobs that failed or timed out are retried, several at a time, with a
+progress line showing how many have been retried so far. Jobs that have
+already been retried are left alone. Use --soft-failed and --canceled to also
+retry jobs that soft failed or were canceled.
+
+With --watch, the build is watched after the jobs are retried, and the exit
+status reflects how it finished, as with bk build watch.
+
+Examples:
+  # Retry the failed jobs of the most recent build on the current branch
+  $ bk build retry-failed
+
+  # Retry the failed and canceled jobs of build 429, then watch it
+  $ bk build retry-failed 429 --pipeline my-pipeline --canceled --watch
+
+  # Retry up to 10 jobs at a time
+  $ bk build retry-failed 429 --limit 10`
+}
+
+func (c *RetryFailedCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	if c.Interval < 1 {
+		return fmt.Errorf("--interval must be at least 1 second (requested: %d)", c.Interval)
+	}
+	if c.Timeout > 0 && !c.Watch {
+		return fmt.Errorf("--timeout requires --watch")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pipelineRes := pipelineResolver.NewAggregateResolver(
+		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
+		pipelineResolver.ResolveFromConfig(f.Config, pipelineResolver.PickOneWithFactory(f)),
+		pipelineResolver.ResolveFromRepository(f, pipelineResolver.CachedPicker(f.Config, pipelineResolver.PickOneWithFactory(f))),
+	)
+
+	optionsResolver := options.AggregateResolver{
+		options.ResolveBranchFromFlag(c.Branch),
+		options.ResolveBranchFromRepository(f.GitRepository),
+	}.WithResolverWhen(
+		c.User != "",
+		options.ResolveUserFromFlag(c.User),
+	).WithResolverWhen(
+		c.Mine || c.User == "",
+		options.ResolveCurrentUser(ctx, f),
+	)
+
+	args := []string{}
+	if c.BuildNumber != "" {
+		args = []string{c.BuildNumber}
+	}
+	buildRes := buildResolver.NewAggregateResolver(
+		buildResolver.ResolveFromPositionalArgument(args, 0, pipelineRes.Resolve, f.Config),
+		buildResolver.ResolveBuildWithOpts(f, pipelineRes.Resolve, optionsResolver...),
+	)
+
+	bld, err := buildRes.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	if bld == nil {
+		fmt.Println("No build found.")
+		return nil
+	}
+
+	var b buildkite.Build
+	if err = bkIO.SpinWhile(f, "Loading build", func() error {
+		b, _, err = f.RestAPIClient.Builds.Get(ctx, bld.Organization, bld.Pipeline, fmt.Sprint(bld.BuildNumber), &buildkite.BuildGetOptions{
+			BuildsListOptions: buildkite.BuildsListOptions{ExcludePipeline: true},
+		})
+		return err
+	}); err != nil {
+		return err
+	}
+
+	jobs := retryableJobs(b, c.SoftFailed, c.Canceled)
+	if len(jobs) == 0 {
+		fmt.Printf("No failed jobs to retry in build #%d.\n", b.Number)
+	} else {
+		tty := isatty.IsTerminal(os.Stdout.Fd())
+		if err := retryJobs(ctx, f, bld.Organization, jobs, int(max(c.Limit, 1)), os.Stdout, tty); err != nil {
+			return err
+		}
+	}
+
+	if !c.Watch {
+		return nil
+	}
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	return waitForBuild(ctx, f, nil, bld.Organization, bld.Pipeline, bld.BuildNumber, time.Duration(c.Interval)*time.Second, c.Timeout)
+}
+
+// retryableJobs returns the command jobs of b that failed or timed out and
+// haven't already been retried, along with those that soft failed or were
+// canceled if asked for.
+func retryableJobs(b buildkite.Build, softFailed, canceled bool) []buildkite.Job {
+	var jobs []buildkite.Job
+	for _, j := range b.Jobs {
+		if j.Type != "script" || j.Retried || j.RetriedInJobID != "" {
+			continue
+		}
+		switch {
+		case j.SoftFailed:
+			if !softFailed {
+				continue
+			}
+		case j.State == "failed", j.State == "timed_out":
+		case j.State == "canceled":
+			if !canceled {
+				continue
+			}
+		default:
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs
+}
+
+// retryJobs retries jobs with at most limit requests at a time, writing a
+// progress line to w as each one finishes, and a summary once they all
+// have. On a terminal the progress line is redrawn in place. If ctx is
+// canceled first, the jobs not yet retried are reported as an error.
+func retryJobs(ctx context.Context, f *factory.Factory, org string, jobs []buildkite.Job, limit int, w io.Writer, tty bool) error {
+	label := "Retrying jobs"
+	if len(jobs) == 1 {
+		label = "Retrying job"
+	}
+
+	started, err := bkIO.RunBatch(ctx, bkIO.Batch{
+		Label:  label,
+		Noun:   "job",
+		Verb:   "retry",
+		Done:   "retried",
+		Limit:  limit,
+		Out:    w,
+		ErrOut: os.Stderr,
+		TTY:    tty,
+		Quiet:  f.Quiet,
+	}, jobs, func(j buildkite.Job) string {
+		return watch.NewFormattedJob(j).DisplayName()
+	}, func(ctx context.Context, j buildkite.Job) error {
+		_, err := internaljob.Retry(ctx, f.RestAPIClient, org, j.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if started < len(jobs) {
+		return fmt.Errorf("interrupted before retrying %d of %d %s", len(jobs)-started, len(jobs), bkIO.Pluralize("job", len(jobs)))
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package build
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestRetryableJobs(t *testing.T) {
+	t.Parallel()
+
+	b := buildkite.Build{Jobs: []buildkite.Job{
+		{ID: "passed", Type: "script", State: "passed"},
+		{ID: "failed", Type: "script", State: "failed"},
+		{ID: "timed-out", Type: "script", State: "timed_out"},
+		{ID: "soft-failed", Type: "script", State: "failed", SoftFailed: true},
+		{ID: "canceled", Type: "script", State: "canceled"},
+		{ID: "retried", Type: "script", State: "failed", Retried: true, RetriedInJobID: "retry"},
+		{ID: "broken", Type: "script", State: "broken"},
+		{ID: "trigger", Type: "trigger", State: "failed"},
+	}}
+
+	tests := []struct {
+		name       string
+		softFailed bool
+		canceled   bool
+		want       []string
+	}{
+		{"failed only", false, false, []string{"failed", "timed-out"}},
+		{"with soft failed", true, false, []string{"failed", "timed-out", "soft-failed"}},
+		{"with canceled", false, true, []string{"failed", "timed-out", "canceled"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, j := range retryableJobs(b, tt.softFailed, tt.canceled) {
+				got = append(got, j.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("retryableJobs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryJobs(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var retried []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := strings.CutPrefix(r.URL.Path, "/v2/organizations/acme/jobs/")
+		id, ok2 := strings.CutSuffix(id, "/retry")
+		if r.Method != http.MethodPut || !ok || !ok2 {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		if id == "job-3" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message":"Jobs can only be retried once"}`))
+			return
+		}
+		mu.Lock()
+		retried = append(retried, id)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"new-` + id + `","state":"scheduled"}`))
+	}))
+	defer server.Close()
+
+	f := newBuildTestFactory(t, server.URL)
+	f.Quiet = false
+
+	jobs := []buildkite.Job{
+		{ID: "job-1", Label: "one"},
+		{ID: "job-2", Label: "two"},
+		{ID: "job-3", Label: "three"},
+		{ID: "job-4", Label: "four"},
+	}
+
+	var out strings.Builder
+	err := retryJobs(context.Background(), f, "acme", jobs, 2, &out, false)
+	if err == nil || !strings.Contains(err.Error(), "failed to retry 1 of 4 jobs") {
+		t.Fatalf("retryJobs() error = %v, want 1 of 4 failed", err)
+	}
+
+	slices.Sort(retried)
+	if want := []string{"job-1", "job-2", "job-4"}; !slices.Equal(retried, want) {
+		t.Errorf("retried = %v, want %v", retried, want)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d progress lines, want 5:\n%s", len(lines), out.String())
+	}
+	if want := "Retrying jobs [░░░░░░░░░░░░░░░░░░░░░░░░]   0% 0/4 succeeded:0 failed:0"; lines[0] != want {
+		t.Errorf("first line = %q, want %q", lines[0], want)
+	}
+	if want := "Retrying jobs [████████████████████████] 100% 4/4 succeeded:3 failed:1"; lines[4] != want {
+		t.Errorf("last line = %q, want %q", lines[4], want)
+	}
+}
+
+func TestRetryJobsSucceeded(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"new","state":"scheduled"}`))
+	}))
+	defer server.Close()
+
+	f := newBuildTestFactory(t, server.URL)
+	f.Quiet = false
+
+	var out strings.Builder
+	if err := retryJobs(context.Background(), f, "acme", []buildkite.Job{{ID: "job-1"}}, 5, &out, true); err != nil {
+		t.Fatalf("retryJobs() error = %v", err)
+	}
+
+	want := "Retrying job [░░░░░░░░░░░░░░░░░░░░░░░░]   0% 0/1 succeeded:0 failed:0" +
+		"\rRetrying job [████████████████████████] 100% 1/1 succeeded:1 failed:0\n" +
+		"\nSuccessfully retried 1 of 1 job\n"
+	if out.String() != want {
+		t.Errorf("retryJobs() output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestRetryJobsInterrupted(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL)
+	}))
+	defer server.Close()
+
+	f := newBuildTestFactory(t, server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out strings.Builder
+	err := retryJobs(ctx, f, "acme", []buildkite.Job{{ID: "job-1"}, {ID: "job-2"}}, 5, &out, false)
+	if err == nil || err.Error() != "interrupted before retrying 2 of 2 jobs" {
+		t.Fatalf("retryJobs() error = %v, want both jobs not retried", err)
+	}
+}
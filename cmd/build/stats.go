@@ -0,0 +1,146 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/build/stats"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	"github.com/buildkite/cli/v3/pkg/output"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+type StatsCmd struct {
+	Pipeline string   `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}. Omit for every pipeline in the organization." short:"p"`
+	Since    string   `help:"Include builds created since this time (e.g. 24h, 720h)" default:"168h"`
+	Branch   []string `help:"Filter by branch name. Supports * and ? wildcards, e.g. feature/*" short:"b"`
+	State    []string `help:"Filter by build state"`
+	Limit    int      `help:"Maximum number of builds to include" default:"1000"`
+	By       string   `help:"Group the trend by day or week" enum:"day,week" default:"day"`
+	Format   string   `help:"Export the stats as a spreadsheet instead: csv. Cannot be combined with -o, --json, --yaml or --text" enum:",csv" default:""`
+	output.OutputFlags
+}
+
+func (c *StatsCmd) Validate() error {
+	if c.Format == "csv" && (c.Output != "" || c.JSON || c.YAML || c.Text) {
+		return fmt.Errorf("--format csv cannot be combined with -o, --json, --yaml or --text")
+	}
+	return nil
+}
+
+func (c *StatsCmd) Help() string {
+	return `Report build statistics over a time window.
+
+Aggregates the builds matching the filters, which work as they do for
+bk build list: the pass rate (of builds that passed or failed), percentiles
+of build duration and of how long jobs waited for an agent, the steps whose
+jobs failed most often (including jobs that were retried), and a trend by
+day or week.
+
+Use --format csv for one row per day or week, for pasting into a
+spreadsheet.
+
+Examples:
+  # Stats for the last week of builds of a pipeline
+  $ bk build stats -p my-pipeline
+
+  # The last 30 days of builds on main, by week
+  $ bk build stats -p my-pipeline --since 720h --branch main --by week
+
+  # Daily trend across every pipeline, as CSV
+  $ bk build stats --format csv > builds.csv
+
+  # Stats for feature branches as YAML
+  $ bk build stats -p my-pipeline --branch "feature/*" -o yaml`
+}
+
+func (c *StatsCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+	f.NoPager = f.NoPager || globals.DisablePager()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	format := output.ResolveFormat(c.Output, f.Config.OutputFormat())
+
+	if c.Limit < 1 || c.Limit > maxBuildLimit {
+		return fmt.Errorf("--limit must be between 1 and %d (requested: %d)", maxBuildLimit, c.Limit)
+	}
+
+	org := f.Config.OrganizationSlug()
+	s, err := c.collect(context.Background(), f, org)
+	if err != nil {
+		return err
+	}
+
+	if c.Format == "csv" || format != output.FormatText {
+		return c.export(os.Stdout, s, format)
+	}
+
+	writer, cleanup := bkIO.Pager(f.NoPager, f.Config.Pager())
+	defer func() { _ = cleanup() }()
+
+	target := org
+	if c.Pipeline != "" {
+		target = fmt.Sprintf("%s/%s", org, c.Pipeline)
+	}
+	fmt.Fprintf(writer, "Showing stats for builds of %s created in the last %s\n\n", target, c.Since)
+	return stats.WriteBuilds(writer, s, stats.Period(c.By))
+}
+
+// listOptions maps the filters onto those of bk build list.
+func (c *StatsCmd) listOptions() (*ListCmd, *buildkite.BuildsListOptions, error) {
+	list := &ListCmd{
+		Pipeline: c.Pipeline,
+		Since:    c.Since,
+		State:    c.State,
+		Branch:   c.Branch,
+		Limit:    c.Limit,
+	}
+	listOpts, err := list.buildListOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+	listOpts.ExcludePipeline = true
+	// Retried jobs count towards how often a step failed.
+	listOpts.IncludeRetriedJobs = true
+	return list, listOpts, nil
+}
+
+// collect fetches the builds matching the filters and aggregates them.
+func (c *StatsCmd) collect(ctx context.Context, f *factory.Factory, org string) (stats.BuildStats, error) {
+	list, listOpts, err := c.listOptions()
+	if err != nil {
+		return stats.BuildStats{}, err
+	}
+
+	// Fetch as structured output, so fetchBuilds neither prints the builds
+	// nor stops to ask whether to keep paging.
+	builds, err := list.fetchBuilds(ctx, f, org, listOpts, output.FormatJSON, nil)
+	if err != nil {
+		return stats.BuildStats{}, fmt.Errorf("failed to list builds: %w", err)
+	}
+	return stats.Builds(builds, stats.Period(c.By)), nil
+}
+
+// export writes s as CSV if --format csv was given, or else in format.
+func (c *StatsCmd) export(w io.Writer, s stats.BuildStats, format output.Format) error {
+	if c.Format == "csv" {
+		return stats.WriteBuildsCSV(w, s)
+	}
+	return output.Write(w, s, format)
+}
@@ -0,0 +1,142 @@
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/build/stats"
+	"github.com/buildkite/cli/v3/pkg/output"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestStatsListOptions(t *testing.T) {
+	cmd := &StatsCmd{Since: "24h", Branch: []string{"main"}, State: []string{"PASSED", "failed"}, Limit: 50}
+
+	list, opts, err := cmd.listOptions()
+	if err != nil {
+		t.Fatalf("listOptions() error = %v", err)
+	}
+	if list.Limit != 50 {
+		t.Errorf("limit = %d, want 50", list.Limit)
+	}
+	if !slices.Equal(opts.Branch, []string{"main"}) {
+		t.Errorf("branch = %v, want [main]", opts.Branch)
+	}
+	if !slices.Equal(opts.State, []string{"passed", "failed"}) {
+		t.Errorf("state = %v, want [passed failed]", opts.State)
+	}
+	if since := time.Since(opts.CreatedFrom); since < 23*time.Hour || since > 25*time.Hour {
+		t.Errorf("created from = %v, want about 24h ago", opts.CreatedFrom)
+	}
+	if !opts.ExcludePipeline || !opts.IncludeRetriedJobs {
+		t.Errorf("options = %+v, want pipeline excluded and retried jobs included", opts)
+	}
+
+	cmd.Branch = []string{"feature/*"}
+	if _, opts, err = cmd.listOptions(); err != nil {
+		t.Fatalf("listOptions() error = %v", err)
+	}
+	if len(opts.Branch) != 0 {
+		t.Errorf("branch = %v, want wildcards left to the client", opts.Branch)
+	}
+
+	cmd.Since = "a week"
+	if _, _, err := cmd.listOptions(); err == nil {
+		t.Error("expected an invalid --since to be rejected")
+	}
+}
+
+func TestStatsFormatFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{[]string{"--format", "csv"}, false},
+		{[]string{"-o", "yaml"}, false},
+		{[]string{"--format", "csv", "-o", "json"}, true},
+		{[]string{"--format", "csv", "--json"}, true},
+		{[]string{"-o", "csv"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var cmd StatsCmd
+			parser, err := kong.New(&cmd, kong.Vars{"output_default_format": ""})
+			if err != nil {
+				t.Fatalf("kong.New() error = %v", err)
+			}
+			if _, err := parser.Parse(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStatsCollectAndExport(t *testing.T) {
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	statsBuild := func(number int, branch, state string) buildkite.Build {
+		return buildkite.Build{
+			Number:     number,
+			Branch:     branch,
+			State:      state,
+			CreatedAt:  buildkite.NewTimestamp(created),
+			StartedAt:  buildkite.NewTimestamp(created),
+			FinishedAt: buildkite.NewTimestamp(created.Add(5 * time.Minute)),
+		}
+	}
+
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		builds := []buildkite.Build{}
+		if r.URL.Query().Get("page") == "1" {
+			builds = []buildkite.Build{
+				statsBuild(3, "feature/a", "passed"),
+				statsBuild(2, "main", "failed"),
+				statsBuild(1, "feature/b", "failed"),
+			}
+		}
+		_ = json.NewEncoder(w).Encode(builds)
+	}))
+	defer server.Close()
+
+	cmd := &StatsCmd{Since: "168h", Branch: []string{"feature/*"}, Limit: 10, By: "day"}
+	s, err := cmd.collect(t.Context(), newBuildTestFactory(t, server.URL), "acme")
+	if err != nil {
+		t.Fatalf("collect() error = %v", err)
+	}
+	if !strings.Contains(query, "include_retried_jobs=true") || !strings.Contains(query, "exclude_pipeline=true") {
+		t.Errorf("unexpected query %q", query)
+	}
+	if s.Builds != 2 || s.Passed != 1 || s.Failed != 1 {
+		t.Fatalf("stats = %+v, want the two feature builds, one passed and one failed", s)
+	}
+
+	var out bytes.Buffer
+	if err := cmd.export(&out, s, output.FormatJSON); err != nil {
+		t.Fatalf("export() error = %v", err)
+	}
+	var got stats.BuildStats
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal JSON output: %v\n%s", err, out.String())
+	}
+	if got.Builds != 2 || got.PassRate != 0.5 {
+		t.Errorf("JSON stats = %+v, want 2 builds at a 0.5 pass rate", got)
+	}
+
+	cmd.Format = "csv"
+	out.Reset()
+	if err := cmd.export(&out, s, output.FormatJSON); err != nil {
+		t.Fatalf("export() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "period,builds,passed,failed,pass_rate") || !strings.HasPrefix(lines[1], "2026-03-02,2,1,1,") {
+		t.Errorf("unexpected CSV output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,147 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/alecthomas/kong"
+	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
+	"github.com/buildkite/cli/v3/internal/build/resolver/options"
+	"github.com/buildkite/cli/v3/internal/build/timeline"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	"github.com/buildkite/cli/v3/pkg/output"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+type TimelineCmd struct {
+	BuildNumber string `arg:"" optional:"" help:"Build number to chart (omit for most recent build)"`
+	Pipeline    string `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
+	Branch      string `help:"Filter builds to this branch." short:"b"`
+	User        string `help:"Filter builds to this user. You can use name or email." short:"u" xor:"userfilter"`
+	Mine        bool   `help:"Filter builds to only my user." xor:"userfilter"`
+	Format      string `help:"Export the chart as a diagram instead: mermaid" enum:",mermaid" default:""`
+	Width       int    `help:"Width of the chart's bars, in characters" default:"60"`
+	output.OutputFlags
+}
+
+func (c *TimelineCmd) Help() string {
+	return `Chart when each job in a build waited for an agent and when it ran.
+
+Every job is drawn as a bar on a timeline of the whole build, grouped by
+step: the light part is the time from being scheduled until an agent picked
+it up, and the solid part is the time it ran. The agent and queue that ran
+each job are listed next to it. Retried jobs are included.
+
+Use --format mermaid to export the chart as a Mermaid Gantt diagram, e.g.
+to paste into a GitHub comment, or -o json or -o yaml for the raw timings.
+
+Examples:
+  # Timeline of the most recent build on the current branch
+  $ bk build timeline
+
+  # Timeline of build 429 as a Mermaid diagram
+  $ bk build timeline 429 --pipeline my-pipeline --format mermaid
+
+  # Timings of every job as JSON
+  $ bk build timeline 429 -o json`
+}
+
+func (c *TimelineCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+	f.NoPager = f.NoPager || globals.DisablePager()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	format := output.ResolveFormat(c.Output, f.Config.OutputFormat())
+
+	if c.Width < 10 {
+		return fmt.Errorf("--width must be at least 10 (requested: %d)", c.Width)
+	}
+
+	ctx := context.Background()
+
+	pipelineRes := pipelineResolver.NewAggregateResolver(
+		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
+		pipelineResolver.ResolveFromConfig(f.Config, pipelineResolver.PickOneWithFactory(f)),
+		pipelineResolver.ResolveFromRepository(f, pipelineResolver.CachedPicker(f.Config, pipelineResolver.PickOneWithFactory(f))),
+	)
+
+	optionsResolver := options.AggregateResolver{
+		options.ResolveBranchFromFlag(c.Branch),
+		options.ResolveBranchFromRepository(f.GitRepository),
+	}.WithResolverWhen(
+		c.User != "",
+		options.ResolveUserFromFlag(c.User),
+	).WithResolverWhen(
+		c.Mine || c.User == "",
+		options.ResolveCurrentUser(ctx, f),
+	)
+
+	args := []string{}
+	if c.BuildNumber != "" {
+		args = []string{c.BuildNumber}
+	}
+	buildRes := buildResolver.NewAggregateResolver(
+		buildResolver.ResolveFromPositionalArgument(args, 0, pipelineRes.Resolve, f.Config),
+		buildResolver.ResolveBuildWithOpts(f, pipelineRes.Resolve, optionsResolver...),
+	)
+
+	bld, err := buildRes.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	if bld == nil {
+		fmt.Println("No build found.")
+		return nil
+	}
+
+	var b buildkite.Build
+	if err = bkIO.SpinWhile(f, "Loading build timeline", func() error {
+		b, _, err = f.RestAPIClient.Builds.Get(ctx, bld.Organization, bld.Pipeline, fmt.Sprint(bld.BuildNumber), &buildkite.BuildGetOptions{
+			BuildsListOptions: buildkite.BuildsListOptions{ExcludePipeline: true, IncludeRetriedJobs: true},
+		})
+		return err
+	}); err != nil {
+		return err
+	}
+
+	t := timeline.New(b, time.Now())
+
+	if c.Format != "" || format != output.FormatText {
+		return writeTimeline(os.Stdout, t, c.Format, format, c.Width)
+	}
+
+	writer, cleanup := bkIO.Pager(f.NoPager, f.Config.Pager())
+	defer func() { _ = cleanup() }()
+
+	return writeTimeline(writer, t, c.Format, format, c.Width)
+}
+
+// writeTimeline writes t as a diagram, if one was asked for, or else in
+// format.
+func writeTimeline(w io.Writer, t timeline.Timeline, diagram string, format output.Format, width int) error {
+	switch {
+	case diagram == "mermaid":
+		return timeline.WriteMermaid(w, t)
+	case format != output.FormatText:
+		return output.Write(w, t, format)
+	default:
+		return timeline.Write(w, t, width)
+	}
+}
@@ -0,0 +1,68 @@
+package build
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/build/timeline"
+	"github.com/buildkite/cli/v3/pkg/output"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestWriteTimeline(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	tl := timeline.New(buildkite.Build{
+		Number:     3,
+		CreatedAt:  buildkite.NewTimestamp(start),
+		FinishedAt: buildkite.NewTimestamp(start.Add(2 * time.Minute)),
+		Jobs: []buildkite.Job{{
+			ID:          "job-1",
+			Type:        "script",
+			Label:       "Test",
+			State:       "passed",
+			ScheduledAt: buildkite.NewTimestamp(start),
+			StartedAt:   buildkite.NewTimestamp(start.Add(30 * time.Second)),
+			FinishedAt:  buildkite.NewTimestamp(start.Add(2 * time.Minute)),
+		}},
+	}, start)
+
+	var out strings.Builder
+	if err := writeTimeline(&out, tl, "", output.FormatJSON, 60); err != nil {
+		t.Fatalf("writeTimeline(json) error = %v", err)
+	}
+	var got struct {
+		Build int `json:"build"`
+		Steps []struct {
+			Jobs []struct {
+				WaitSeconds float64 `json:"wait_seconds"`
+				RunSeconds  float64 `json:"run_seconds"`
+			} `json:"jobs"`
+		} `json:"steps"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	if got.Build != 3 || len(got.Steps) != 1 || got.Steps[0].Jobs[0].WaitSeconds != 30 || got.Steps[0].Jobs[0].RunSeconds != 90 {
+		t.Errorf("writeTimeline(json) = %s, want build 3 with one job waiting 30s and running 90s", out.String())
+	}
+
+	out.Reset()
+	if err := writeTimeline(&out, tl, "", output.FormatYAML, 60); err != nil {
+		t.Fatalf("writeTimeline(yaml) error = %v", err)
+	}
+	if !strings.Contains(out.String(), "build: 3\n") || !strings.Contains(out.String(), "wait_seconds: 30\n") {
+		t.Errorf("writeTimeline(yaml) = %s, want build 3 with one job waiting 30s", out.String())
+	}
+
+	out.Reset()
+	if err := writeTimeline(&out, tl, "mermaid", output.FormatJSON, 60); err != nil {
+		t.Fatalf("writeTimeline(mermaid) error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "gantt\n") {
+		t.Errorf("writeTimeline(mermaid) = %q, want a Mermaid Gantt chart", out.String())
+	}
+}
@@ -4,14 +4,19 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"sync"
 
 	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/build/archive"
 	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
 	"github.com/buildkite/cli/v3/internal/build/resolver/options"
 	"github.com/buildkite/cli/v3/internal/build/view"
+	"github.com/buildkite/cli/v3/internal/build/watch"
 	"github.com/buildkite/cli/v3/internal/cli"
 	bkIO "github.com/buildkite/cli/v3/internal/io"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	"github.com/buildkite/cli/v3/internal/job/classify"
 	pipelineResolver "github.com/buildkite/cli/v3/internal/pipeline/resolver"
 	"github.com/buildkite/cli/v3/pkg/cmd/factory"
 	"github.com/buildkite/cli/v3/pkg/cmd/validation"
@@ -21,14 +26,17 @@ import (
 )
 
 type ViewCmd struct {
-	BuildNumber string   `arg:"" optional:"" help:"Build number to view (omit for most recent build)"`
-	Pipeline    string   `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
-	Branch      string   `help:"Filter builds to this branch." short:"b"`
-	User        string   `help:"Filter builds to this user. You can use name or email." short:"u" xor:"userfilter"`
-	Mine        bool     `help:"Filter builds to only my user." xor:"userfilter"`
-	JobStates   []string `help:"Filter jobs by state. Valid states: running, scheduled, passed, failed, canceled, skipped, not_run, broken." short:"s" sep:","`
-	Web         bool     `help:"Open the build in a web browser." short:"w" xor:"viewmode"`
-	Summary     bool     `help:"Return metadata only for fast state checks, polling, scripts, and LLM agents." xor:"viewmode"`
+	BuildNumber    string   `arg:"" optional:"" help:"Build number to view (omit for most recent build)"`
+	Pipeline       string   `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
+	Branch         string   `help:"Filter builds to this branch." short:"b"`
+	User           string   `help:"Filter builds to this user. You can use name or email." short:"u" xor:"userfilter"`
+	Mine           bool     `help:"Filter builds to only my user." xor:"userfilter"`
+	JobStates      []string `help:"Filter jobs by state. Valid states: running, scheduled, passed, failed, canceled, skipped, not_run, broken." short:"s" sep:","`
+	Web            bool     `help:"Open the build in a web browser." short:"w" xor:"viewmode"`
+	Summary        bool     `help:"Return metadata only for fast state checks, polling, scripts, and LLM agents." xor:"viewmode"`
+	FollowTriggers int      `help:"Follow trigger steps into the builds they start, up to this many levels deep (0 disables)." default:"0"`
+	Classify       bool     `help:"Fetch the logs of failed jobs and label the likely cause of each failure."`
+	FromArchive    string   `help:"View a build archive written by bk build export instead of fetching the build, without API access." name:"from-archive" type:"existingfile"`
 	output.OutputFlags
 }
 
@@ -63,6 +71,15 @@ Examples:
   # Filter to only show failed and broken jobs
   $ bk build view -s failed,broken
 
+  # Include the builds started by trigger steps, and the builds those trigger
+  $ bk build view 429 --follow-triggers 2
+
+  # Label the likely cause of each failed job (OOM, timeout, test failure, ...)
+  $ bk build view 429 --classify
+
+  # View a build exported with bk build export, offline
+  $ bk build view --from-archive monolith-429.tar.gz
+
   # You can combine most of these flags
   # To view most recent build by greg on the deploy-pipeline
   $ bk build view -p deploy-pipeline -u "greg"`
@@ -92,12 +109,18 @@ func (c *ViewCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 	f.Quiet = globals.IsQuiet()
 	f.NoPager = f.NoPager || globals.DisablePager()
 
+	format := output.ResolveFormat(c.Output, f.Config.OutputFormat())
+
+	// Archives are viewed without API access, so need no token.
+	if c.FromArchive != "" {
+		return c.viewArchive(f, format)
+	}
+
 	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
 		return err
 	}
 
 	ctx := context.Background()
-	format := output.ResolveFormat(c.Output, f.Config.OutputFormat())
 
 	var opts view.ViewOptions
 	opts.Pipeline = c.Pipeline
@@ -158,8 +181,16 @@ func (c *ViewCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 	var build buildkite.Build
 	var artifacts []buildkite.Artifact
 	var annotations []buildkite.Annotation
+	var triggered []*watch.BuildTree
+	var causes map[string]classify.Result
 	if err = bkIO.SpinWhile(f, "Loading build information", func() error {
 		build, artifacts, annotations, err = c.fetchBuildDetails(ctx, f, opts)
+		if err == nil && c.FollowTriggers > 0 && !c.Summary {
+			triggered = watch.NewTriggerFollower(f.RestAPIClient, c.FollowTriggers).Triggered(ctx, build)
+		}
+		if err == nil && c.Classify && !c.Summary {
+			causes, err = classifyFailedJobs(ctx, f, opts.Organization, opts.Pipeline, build)
+		}
 		return err
 	}); err != nil {
 		return err
@@ -169,21 +200,34 @@ func (c *ViewCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 		return output.Write(os.Stdout, newBuildSummaryOutput(build, opts.Organization, opts.Pipeline), format)
 	}
 
-	// Create a combined view for JSON/YAML output
-	type BuildOutput struct {
-		buildkite.Build
-		Artifacts   []buildkite.Artifact   `json:"artifacts,omitempty"`
-		Annotations []buildkite.Annotation `json:"annotations,omitempty"`
-	}
+	return writeBuildView(f, format, opts.Organization, opts.Pipeline, buildViewOutput{
+		Build:       build,
+		Artifacts:   artifacts,
+		Annotations: annotations,
+		Triggered:   triggered,
+		Causes:      causes,
+	})
+}
 
-	buildOutput := output.Viewable[BuildOutput]{
-		Data: BuildOutput{
-			Build:       build,
-			Artifacts:   artifacts,
-			Annotations: annotations,
-		},
-		Render: func(b BuildOutput) string {
-			return view.NewBuildView(&b.Build, b.Artifacts, b.Annotations, opts.Organization, opts.Pipeline).Render()
+// buildViewOutput combines a build with its related resources for JSON and
+// YAML output.
+type buildViewOutput struct {
+	buildkite.Build
+	Artifacts   []buildkite.Artifact       `json:"artifacts,omitempty"`
+	Annotations []buildkite.Annotation     `json:"annotations,omitempty"`
+	Triggered   []*watch.BuildTree         `json:"triggered_builds,omitempty"`
+	Causes      map[string]classify.Result `json:"failure_causes,omitempty"`
+}
+
+// writeBuildView writes a build in the requested format, paging text output.
+func writeBuildView(f *factory.Factory, format output.Format, org, pipeline string, data buildViewOutput) error {
+	buildOutput := output.Viewable[buildViewOutput]{
+		Data: data,
+		Render: func(b buildViewOutput) string {
+			v := view.NewBuildView(&b.Build, b.Artifacts, b.Annotations, org, pipeline)
+			v.TriggeredBuilds = b.Triggered
+			v.FailureCauses = b.Causes
+			return v.Render()
 		},
 	}
 
@@ -198,6 +242,84 @@ func (c *ViewCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 	return output.Write(os.Stdout, buildOutput, format)
 }
 
+// viewArchive views a build archive written by bk build export. Failure
+// causes for --classify come from the archived logs.
+func (c *ViewCmd) viewArchive(f *factory.Factory, format output.Format) error {
+	if c.BuildNumber != "" || c.Web || c.FollowTriggers > 0 {
+		return fmt.Errorf("--from-archive can't be combined with a build number, --web or --follow-triggers")
+	}
+
+	a, err := archive.Open(c.FromArchive)
+	if err != nil {
+		return err
+	}
+	org, pipeline := a.Manifest.Organization, a.Manifest.Pipeline
+
+	b := a.Build
+	if len(c.JobStates) > 0 {
+		b.Jobs = slices.DeleteFunc(slices.Clone(b.Jobs), func(j buildkite.Job) bool {
+			return !slices.Contains(c.JobStates, j.State)
+		})
+	}
+
+	if c.Summary {
+		return output.Write(os.Stdout, newBuildSummaryOutput(b, org, pipeline), format)
+	}
+
+	var causes map[string]classify.Result
+	if c.Classify {
+		rules, err := classify.LoadForRepository(f.GitRepository)
+		if err != nil {
+			return err
+		}
+		var logs []jobLog
+		filter := jobLogFilter{states: failedJobStates}
+		for _, j := range a.Build.Jobs {
+			if content, ok := a.Logs[j.ID]; ok && filter.matches(j) {
+				logs = append(logs, jobLog{job: j, label: jobLogLabel(j), lines: internaljob.SplitLogLines(content)})
+			}
+		}
+		causes = classifyJobLogs(rules, logs)
+	}
+
+	return writeBuildView(f, format, org, pipeline, buildViewOutput{
+		Build:       b,
+		Artifacts:   a.Artifacts,
+		Annotations: a.Annotations,
+		Causes:      causes,
+	})
+}
+
+// classifyFailedJobs fetches the logs of the build's failed jobs and returns
+// the likely cause of each failure, by job ID. Jobs no rule matches are left
+// out.
+func classifyFailedJobs(ctx context.Context, f *factory.Factory, org, pipeline string, b buildkite.Build) (map[string]classify.Result, error) {
+	rules, err := classify.LoadForRepository(f.GitRepository)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := fetchBuildJobLogs(ctx, f, org, pipeline, b, jobLogFilter{states: failedJobStates})
+	if err != nil {
+		return nil, err
+	}
+	return classifyJobLogs(rules, logs), nil
+}
+
+// failedJobStates are the states of jobs whose failures are classified.
+var failedJobStates = []string{"failed", "timed_out"}
+
+// classifyJobLogs returns the likely cause of each job's failure by job ID,
+// leaving out jobs no rule matches.
+func classifyJobLogs(rules *classify.Ruleset, logs []jobLog) map[string]classify.Result {
+	causes := make(map[string]classify.Result, len(logs))
+	for _, l := range logs {
+		if results := rules.ClassifyJob(l.job, internaljob.LogLineTexts(l.lines)); len(results) > 0 {
+			causes[l.job.ID] = results[0]
+		}
+	}
+	return causes
+}
+
 func (c *ViewCmd) fetchBuildDetails(ctx context.Context, f *factory.Factory, opts view.ViewOptions) (buildkite.Build, []buildkite.Artifact, []buildkite.Annotation, error) {
 	if c.Summary {
 		build, _, err := f.RestAPIClient.Builds.Get(ctx, opts.Organization, opts.Pipeline, fmt.Sprint(opts.BuildNumber), c.buildGetOptions())
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/alecthomas/kong"
 	buildResolver "github.com/buildkite/cli/v3/internal/build/resolver"
 	"github.com/buildkite/cli/v3/internal/build/resolver/options"
+	"github.com/buildkite/cli/v3/internal/build/view"
 	"github.com/buildkite/cli/v3/internal/build/view/shared"
 	"github.com/buildkite/cli/v3/internal/build/watch"
 	"github.com/buildkite/cli/v3/internal/cli"
@@ -18,18 +22,45 @@ import (
 	"github.com/buildkite/cli/v3/pkg/cmd/factory"
 	pkgValidation "github.com/buildkite/cli/v3/pkg/cmd/validation"
 	buildkite "github.com/buildkite/go-buildkite/v5"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mattn/go-isatty"
 )
 
 type WatchCmd struct {
-	BuildNumber string `arg:"" optional:"" help:"Build number to watch (omit for most recent build)"`
-	Pipeline    string `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
-	Branch      string `help:"The branch to watch builds for." short:"b"`
-	Interval    int    `help:"Polling interval in seconds" default:"1"`
+	BuildNumber string        `arg:"" optional:"" help:"Build number to watch (omit for most recent build)"`
+	Pipeline    string        `help:"The pipeline to use. This can be a {pipeline slug} or in the format {org slug}/{pipeline slug}." short:"p"`
+	Branch      string        `help:"The branch to watch builds for." short:"b"`
+	Interval    int           `help:"Polling interval in seconds" default:"1"`
+	JSON        bool          `help:"Emit one JSON object per event (JSONL)."`
+	Timeout     time.Duration `help:"Stop watching after this long (e.g. 30m) and exit with the timed out status. Waits indefinitely by default."`
+
+	FollowTriggers int `help:"Follow trigger steps into the builds they start, up to this many levels deep (0 disables)." default:"0"`
 }
 
 func (c *WatchCmd) Help() string {
-	return `
+	return `Watch a build until it finishes.
+
+In a terminal, jobs are shown as a tree grouped by step with the selected
+job's log tailed alongside. Use the arrow keys (or j/k) to select a job, f to
+jump to the next failure and q to stop watching. The selected failed job can
+be retried with r, a blocked step unblocked with u, and the job opened in a
+browser with o. Press c to cancel the build. Actions ask for confirmation
+unless --yes is given.
+
+When output is not a terminal, a timestamped summary is printed on every poll
+instead. With --json, one JSON object is written per event: build_status when
+the build state or job counts change, job_failure, job_retry_passed and
+job_promised_failure as jobs change, and a final build_summary.
+
+The exit status reflects how the build finished: 0 when it passed, 13 when it
+failed, 14 when it was canceled (or skipped), 15 when it is blocked and 16 when
+--timeout elapsed first. Stopping early with q or Ctrl-C exits 0.
+
+With --follow-triggers, the builds started by trigger steps are watched too,
+down to the given depth, and shown beneath the trigger step that started them.
+A trigger step fails when its triggered build (or any build below it) fails,
+and watching continues until every followed build has finished.
+
 Examples:
   # Watch the most recent build for the current branch
   $ bk build watch --pipeline my-pipeline
@@ -44,7 +75,13 @@ Examples:
   $ bk build watch --pipeline my-pipeline
 
   # Set a custom polling interval (in seconds)
-  $ bk build watch --interval 5 --pipeline my-pipeline`
+  $ bk build watch --interval 5 --pipeline my-pipeline
+
+  # Also watch the builds started by trigger steps, and the builds those trigger
+  $ bk build watch 429 --pipeline my-pipeline --follow-triggers 2
+
+  # Stream build events as JSONL
+  $ bk build watch 429 --pipeline my-pipeline --json | jq -c 'select(.type == "job_failure")'`
 }
 
 func (c *WatchCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
@@ -61,18 +98,19 @@ func (c *WatchCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 		return err
 	}
 
-	tty := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
-
 	// Validate command options
 	v := validation.New()
 	v.AddRule("Interval", validation.MinValue(1))
+	v.AddRule("FollowTriggers", validation.MinValue(0))
 	if err := v.Validate(map[string]interface{}{
-		"Interval": c.Interval,
+		"Interval":       c.Interval,
+		"FollowTriggers": c.FollowTriggers,
 	}); err != nil {
 		return err
 	}
 
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	pipelineRes := pipelineResolver.NewAggregateResolver(
 		pipelineResolver.ResolveFromFlag(c.Pipeline, f.Config),
@@ -102,23 +140,171 @@ func (c *WatchCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 		return fmt.Errorf("no running builds found")
 	}
 
-	fmt.Printf("Watching build %d on %s/%s\n", bld.BuildNumber, bld.Organization, bld.Pipeline)
-
 	interval := time.Duration(c.Interval) * time.Second
 
-	_, err = watch.WatchBuild(ctx, f.RestAPIClient, bld.Organization, bld.Pipeline, bld.BuildNumber, interval, func(b buildkite.Build) error {
-		summary := shared.BuildSummaryWithJobs(&b, bld.Organization, bld.Pipeline)
-		if tty {
-			fmt.Print("\033[H\033[2J")
-			fmt.Printf("%s\n", summary)
-		} else {
-			fmt.Printf("[%s] %s\n", time.Now().Format(time.RFC3339), summary)
-		}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	follower := newTriggerFollower(f, c.FollowTriggers)
+
+	if c.JSON {
+		return watchJSON(ctx, f, follower, bld.Organization, bld.Pipeline, bld.BuildNumber, interval, c.Timeout, os.Stdout)
+	}
+
+	return waitForBuild(ctx, f, follower, bld.Organization, bld.Pipeline, bld.BuildNumber, interval, c.Timeout)
+}
+
+// newTriggerFollower returns the follower for --follow-triggers, or nil when
+// triggers are not followed.
+func newTriggerFollower(f *factory.Factory, depth int) *watch.TriggerFollower {
+	if depth <= 0 {
 		return nil
+	}
+	return watch.NewTriggerFollower(f.RestAPIClient, depth)
+}
+
+// untilTriggeredFinished keeps a watch polling until every build in *triggered
+// has finished. triggered is updated by the watch's status callback.
+func untilTriggeredFinished(triggered *[]*watch.BuildTree) watch.WatchOpt {
+	return watch.WithUntil(func(buildkite.Build) bool {
+		return watch.TriggeredBuildsFinished(*triggered)
 	})
+}
+
+// rolledUp returns b with its state combined with those of the builds it
+// triggered, so that a failed triggered build fails the watch.
+func rolledUp(b buildkite.Build, triggered []*watch.BuildTree) buildkite.Build {
+	b.State = watch.RollupState(b.State, triggered)
+	return b
+}
+
+// waitForBuild watches a build until it finishes, using the interactive UI
+// when attached to a terminal and printing a timestamped summary on every poll
+// otherwise. The returned error reflects the build outcome (see watchOutcome).
+// follower may be nil when trigger steps are not followed.
+func waitForBuild(ctx context.Context, f *factory.Factory, follower *watch.TriggerFollower, org, pipeline string, buildNumber int, interval, timeout time.Duration) error {
+	tty := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+	if tty && !f.NoInput {
+		return watchInteractive(ctx, f, follower, org, pipeline, buildNumber, interval, timeout)
+	}
+
+	fmt.Printf("Watching build %d on %s/%s\n", buildNumber, org, pipeline)
+
+	var triggered []*watch.BuildTree
+	b, err := watch.WatchBuild(ctx, f.RestAPIClient, org, pipeline, buildNumber, interval, func(b buildkite.Build) error {
+		triggered = follower.Triggered(ctx, b)
+		summary := shared.BuildSummaryWithJobs(&b, org, pipeline)
+		if tree := view.RenderTriggeredBuilds(&b, triggered); tree != "" {
+			summary += "\n\n" + tree + "\n"
+		}
+		fmt.Printf("[%s] %s\n", time.Now().Format(time.RFC3339), summary)
+		return nil
+	}, untilTriggeredFinished(&triggered))
+
+	return watchOutcome(ctx, rolledUp(b, triggered), err, timeout)
+}
+
+// watchOutcome turns the result of watching a build into the command's error
+// so that the exit code reflects how the build finished. ctx is the context
+// the watch ran under and tells a --timeout apart from an interrupt, which
+// is not an error.
+func watchOutcome(ctx context.Context, b buildkite.Build, err error, timeout time.Duration) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return watch.TimedOutError(b, timeout)
+	}
 	if errors.Is(err, context.Canceled) {
 		return nil
 	}
+	if err != nil {
+		return err
+	}
+	return watch.OutcomeError(b)
+}
+
+// watchInteractive runs the full-screen watch UI until the build finishes or
+// the user quits, then prints the final build summary to the scrollback.
+func watchInteractive(parent context.Context, f *factory.Factory, follower *watch.TriggerFollower, org, pipeline string, buildNumber int, interval, timeout time.Duration) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	fetchLog := func(buildID, jobID string) (string, error) {
+		reqCtx, reqCancel := context.WithTimeout(ctx, watch.DefaultRequestTimeout)
+		defer reqCancel()
+		jobLog, _, err := f.RestAPIClient.Jobs.GetJobLog(reqCtx, org, pipeline, buildID, jobID)
+		return jobLog.Content, err
+	}
 
-	return err
+	actions := watchBuildActions{ctx: ctx, f: f, organization: org, pipeline: pipeline, buildNumber: buildNumber}
+	model := newWatchModel(org, pipeline, buildNumber, fetchLog, actions, cancel)
+	program := tea.NewProgram(model, tea.WithAltScreen())
+
+	tracker := watch.NewJobTracker()
+	go func() {
+		var triggered []*watch.BuildTree
+		b, err := watch.WatchBuild(ctx, f.RestAPIClient, org, pipeline, buildNumber, interval, func(b buildkite.Build) error {
+			status := updateTracker(ctx, f.RestAPIClient, tracker, follower, org, pipeline, buildNumber, b)
+			triggered = status.Triggered
+			program.Send(watchStatusMsg(status))
+			return nil
+		}, watch.WithRetriedJobs(), untilTriggeredFinished(&triggered))
+		program.Send(watchDoneMsg{build: b, err: err})
+	}()
+
+	final, err := program.Run()
+	if err != nil {
+		return err
+	}
+
+	m := final.(watchModel)
+	if m.status.Build.Number != 0 {
+		fmt.Println(shared.BuildSummaryWithJobs(&m.status.Build, org, pipeline))
+		if tree := view.RenderTriggeredBuilds(&m.status.Build, m.status.Triggered); tree != "" {
+			fmt.Printf("\n%s\n", tree)
+		}
+	}
+	if !m.finished {
+		// The user stopped watching before the build finished.
+		return nil
+	}
+	return watchOutcome(parent, rolledUp(m.status.Build, m.status.Triggered), m.err, timeout)
+}
+
+// watchJSON streams watch events as JSONL to w until the build finishes. A
+// build_summary event is always written last, marked incomplete when watching
+// stopped early (e.g. on interrupt).
+func watchJSON(ctx context.Context, f *factory.Factory, follower *watch.TriggerFollower, org, pipeline string, buildNumber int, interval, timeout time.Duration, w io.Writer) error {
+	emitter := newWatchEventEmitter(w, org, pipeline, buildNumber)
+	tracker := watch.NewJobTracker()
+
+	var triggered []*watch.BuildTree
+	b, err := watch.WatchBuild(ctx, f.RestAPIClient, org, pipeline, buildNumber, interval, func(b buildkite.Build) error {
+		status := updateTracker(ctx, f.RestAPIClient, tracker, follower, org, pipeline, buildNumber, b)
+		triggered = status.Triggered
+		return emitter.Status(status)
+	}, watch.WithRetriedJobs(), untilTriggeredFinished(&triggered))
+
+	if summaryErr := emitter.Summary(b, tracker, err != nil); summaryErr != nil && err == nil {
+		return summaryErr
+	}
+	return watchOutcome(ctx, rolledUp(b, triggered), err, timeout)
+}
+
+// updateTracker feeds a polled build into the tracker, first asking the server
+// which running jobs it classifies as hard-failing promised failures and, when
+// follower is non-nil, fetching the builds started by trigger steps. The
+// classification is best-effort: on error the tracker falls back to its
+// client-side heuristic for this poll.
+func updateTracker(ctx context.Context, client *buildkite.Client, tracker *watch.JobTracker, follower *watch.TriggerFollower, org, pipeline string, buildNumber int, b buildkite.Build) watch.BuildStatus {
+	var serverFailing map[string]bool
+	if watch.HasRunningScriptJob(b) {
+		if failing, err := watch.FetchPromisedHardFailures(ctx, client, org, pipeline, buildNumber); err == nil {
+			serverFailing = failing
+		}
+	}
+	tracker.SetServerClassifiedFailures(serverFailing)
+	tracker.SetTriggeredBuilds(follower.Triggered(ctx, b))
+	return tracker.Update(b)
 }
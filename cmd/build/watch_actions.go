@@ -0,0 +1,166 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/buildkite/cli/v3/internal/build/watch"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	"github.com/buildkite/cli/v3/internal/util"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watchActions performs the operations available from the watch UI. Each
+// method returns a short description of the outcome to show in the UI.
+type watchActions interface {
+	confirm(prompt string) (bool, error)
+	retry(job buildkite.Job) (string, error)
+	unblock(job buildkite.Job) (string, error)
+	cancel() (string, error)
+	open(url string) error
+}
+
+// watchBuildActions acts on the watched build through the same code paths as
+// bk job retry, bk job unblock and bk build cancel.
+type watchBuildActions struct {
+	ctx          context.Context
+	f            *factory.Factory
+	organization string
+	pipeline     string
+	buildNumber  int
+}
+
+func (a watchBuildActions) confirm(prompt string) (bool, error) {
+	return bkIO.Confirm(a.f, prompt)
+}
+
+func (a watchBuildActions) retry(j buildkite.Job) (string, error) {
+	var job buildkite.Job
+	if err := bkIO.SpinWhile(a.f, "Retrying job", func() error {
+		var apiErr error
+		job, apiErr = internaljob.Retry(a.ctx, a.f.RestAPIClient, a.organization, j.ID)
+		return apiErr
+	}); err != nil {
+		return "", err
+	}
+	return "Successfully retried job: " + job.WebURL, nil
+}
+
+func (a watchBuildActions) unblock(j buildkite.Job) (string, error) {
+	err := bkIO.SpinWhile(a.f, "Unblocking job", func() error {
+		_, apiErr := internaljob.Unblock(a.ctx, a.f.RestAPIClient, a.organization, j.ID, nil)
+		return apiErr
+	})
+	if err != nil {
+		if internaljob.IsAlreadyUnblocked(err) {
+			return "This job is already unblocked", nil
+		}
+		return "", err
+	}
+	return "Successfully unblocked job", nil
+}
+
+func (a watchBuildActions) cancel() (string, error) {
+	if err := cancelBuild(a.ctx, a.organization, a.pipeline, fmt.Sprint(a.buildNumber), false, a.f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Build #%d canceled", a.buildNumber), nil
+}
+
+func (a watchBuildActions) open(url string) error {
+	return util.OpenInWebBrowser(true, url)
+}
+
+// watchAction is a confirmed operation triggered from the watch UI. It
+// implements tea.ExecCommand so that it runs with the terminal released from
+// the UI, letting the confirmation prompt and spinner behave exactly as they
+// do in the standalone commands.
+type watchAction struct {
+	actions watchActions
+	prompt  string
+	run     func() (string, error)
+	result  string
+}
+
+func (a *watchAction) Run() error {
+	confirmed, err := a.actions.confirm(a.prompt)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return nil
+	}
+	a.result, err = a.run()
+	return err
+}
+
+func (a *watchAction) SetStdin(io.Reader)  {}
+func (a *watchAction) SetStdout(io.Writer) {}
+func (a *watchAction) SetStderr(io.Writer) {}
+
+// watchActionMsg reports the outcome of an action back to the watch UI.
+type watchActionMsg struct {
+	text string
+	err  error
+}
+
+func (m watchModel) execAction(prompt string, run func() (string, error)) (tea.Model, tea.Cmd) {
+	action := &watchAction{actions: m.actions, prompt: prompt, run: run}
+	return m, tea.Exec(action, func(err error) tea.Msg {
+		return watchActionMsg{text: action.result, err: err}
+	})
+}
+
+func (m watchModel) retrySelected() (tea.Model, tea.Cmd) {
+	j, ok := m.selectedJob()
+	if !ok || m.actions == nil {
+		return m, nil
+	}
+	job := watch.NewFormattedJob(j)
+	if j.Type != "script" || !job.IsFailed() {
+		m.notice = watchActionMsg{err: fmt.Errorf("only failed jobs can be retried")}
+		return m, nil
+	}
+	return m.execAction(fmt.Sprintf("Retry job %q", job.DisplayName()), func() (string, error) {
+		return m.actions.retry(j)
+	})
+}
+
+func (m watchModel) unblockSelected() (tea.Model, tea.Cmd) {
+	j, ok := m.selectedJob()
+	if !ok || m.actions == nil {
+		return m, nil
+	}
+	if j.Type != "manual" || j.State != "blocked" {
+		m.notice = watchActionMsg{err: fmt.Errorf("only blocked steps can be unblocked")}
+		return m, nil
+	}
+	return m.execAction(fmt.Sprintf("Unblock step %q", watch.NewFormattedJob(j).DisplayName()), func() (string, error) {
+		return m.actions.unblock(j)
+	})
+}
+
+func (m watchModel) cancelWatchedBuild() (tea.Model, tea.Cmd) {
+	if m.actions == nil {
+		return m, nil
+	}
+	return m.execAction(fmt.Sprintf("Cancel build #%d on %s", m.buildNumber, m.pipeline), m.actions.cancel)
+}
+
+func (m watchModel) openSelected() (tea.Model, tea.Cmd) {
+	j, ok := m.selectedJob()
+	if !ok || m.actions == nil || j.WebURL == "" {
+		return m, nil
+	}
+	actions := m.actions
+	return m, func() tea.Msg {
+		if err := actions.open(j.WebURL); err != nil {
+			return watchActionMsg{err: err}
+		}
+		return watchActionMsg{text: "Opened " + j.WebURL}
+	}
+}
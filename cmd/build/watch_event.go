@@ -0,0 +1,239 @@
+package build
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/build/watch"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+// watchEventType identifies the kind of event emitted by bk build watch --json.
+type watchEventType string
+
+const (
+	watchEventBuildStatus    watchEventType = "build_status"
+	watchEventJobFailure     watchEventType = "job_failure"
+	watchEventJobRetryPassed watchEventType = "job_retry_passed"
+
+	// watchEventJobPromisedFailure is emitted when a still-running job declares
+	// an early (promised) failure, ahead of reaching a terminal state.
+	watchEventJobPromisedFailure watchEventType = "job_promised_failure"
+	watchEventBuildSummary       watchEventType = "build_summary"
+)
+
+// watchEvent is a single line of bk build watch --json output.
+type watchEvent struct {
+	Type watchEventType `json:"type"`
+	Time time.Time      `json:"timestamp"`
+
+	Organization string `json:"organization"`
+	Pipeline     string `json:"pipeline"`
+	BuildNumber  int    `json:"build_number"`
+	BuildURL     string `json:"build_url,omitempty"`
+	BuildState   string `json:"build_state,omitempty"`
+
+	Jobs *watch.JobSummary `json:"jobs,omitempty"`
+
+	// RollupState and TriggeredBuilds are set for build_status and
+	// build_summary events when following triggers. RollupState combines the
+	// build's state with those of the builds it triggered.
+	RollupState     string                     `json:"rollup_state,omitempty"`
+	TriggeredBuilds []watchEventTriggeredBuild `json:"triggered_builds,omitempty"`
+
+	// Job is set for job_failure, job_retry_passed and job_promised_failure events.
+	Job *watchEventJob `json:"job,omitempty"`
+
+	// FailedJobs is set for build_summary events. Contains hard-failed jobs only
+	// (soft failures excluded).
+	FailedJobs []watchEventJob `json:"failed_jobs,omitempty"`
+
+	// Duration is set for build_summary events. Elapsed time since the build started.
+	Duration time.Duration `json:"duration_ns,omitempty"`
+
+	// Incomplete is set for build_summary events when watching stopped before
+	// the build reached a terminal state.
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// watchEventJob is the compact job shape emitted in watch events.
+type watchEventJob struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name,omitempty"`
+	StepKey            string `json:"step_key,omitempty"`
+	State              string `json:"state,omitempty"`
+	ExitStatus         *int   `json:"exit_status,omitempty"`
+	PromisedExitStatus *int   `json:"promised_exit_status,omitempty"`
+	SoftFailed         bool   `json:"soft_failed"`
+	RetriesCount       int    `json:"retries_count,omitempty"`
+	WebURL             string `json:"web_url,omitempty"`
+}
+
+func newWatchEventJob(j buildkite.Job) watchEventJob {
+	return watchEventJob{
+		ID:                 j.ID,
+		Name:               watch.NewFormattedJob(j).DisplayName(),
+		StepKey:            j.StepKey,
+		State:              j.State,
+		ExitStatus:         j.ExitStatus,
+		PromisedExitStatus: j.PromisedExitStatus,
+		SoftFailed:         j.SoftFailed,
+		RetriesCount:       j.RetriesCount,
+		WebURL:             j.WebURL,
+	}
+}
+
+// watchEventTriggeredBuild is the compact shape of a build started by a
+// trigger step, nested under the builds it triggered in turn.
+type watchEventTriggeredBuild struct {
+	TriggerJobID string                     `json:"trigger_job_id"`
+	Organization string                     `json:"organization"`
+	Pipeline     string                     `json:"pipeline"`
+	BuildNumber  int                        `json:"build_number"`
+	BuildURL     string                     `json:"build_url,omitempty"`
+	BuildState   string                     `json:"build_state,omitempty"`
+	RollupState  string                     `json:"rollup_state,omitempty"`
+	Error        string                     `json:"error,omitempty"`
+	Children     []watchEventTriggeredBuild `json:"children,omitempty"`
+}
+
+func newWatchEventTriggeredBuilds(trees []*watch.BuildTree) []watchEventTriggeredBuild {
+	if len(trees) == 0 {
+		return nil
+	}
+	builds := make([]watchEventTriggeredBuild, 0, len(trees))
+	for _, t := range trees {
+		tb := watchEventTriggeredBuild{
+			TriggerJobID: t.TriggerJobID,
+			Organization: t.Organization,
+			Pipeline:     t.Pipeline,
+			BuildNumber:  t.Build.Number,
+			BuildURL:     t.Build.WebURL,
+			BuildState:   t.Build.State,
+			RollupState:  t.RollupState,
+			Children:     newWatchEventTriggeredBuilds(t.Children),
+		}
+		if t.Err != nil {
+			tb.Error = t.Err.Error()
+		}
+		builds = append(builds, tb)
+	}
+	return builds
+}
+
+// triggeredStatesKey identifies the states of every triggered build, so that
+// a change anywhere downstream produces a new build_status event.
+func triggeredStatesKey(trees []*watch.BuildTree) string {
+	var sb strings.Builder
+	for _, t := range trees {
+		sb.WriteString(t.TriggerJobID + "=" + t.Build.State + "/" + t.RollupState + "[" + triggeredStatesKey(t.Children) + "];")
+	}
+	return sb.String()
+}
+
+// watchEventEmitter writes JSONL events derived from successive
+// watch.JobTracker updates.
+type watchEventEmitter struct {
+	encoder      *json.Encoder
+	organization string
+	pipeline     string
+	buildNumber  int
+
+	emitted       bool
+	lastState     string
+	lastSummary   watch.JobSummary
+	lastTriggered []*watch.BuildTree
+}
+
+func newWatchEventEmitter(w io.Writer, organization, pipeline string, buildNumber int) *watchEventEmitter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &watchEventEmitter{
+		encoder:      enc,
+		organization: organization,
+		pipeline:     pipeline,
+		buildNumber:  buildNumber,
+	}
+}
+
+func (e *watchEventEmitter) event(t watchEventType, b buildkite.Build) watchEvent {
+	return watchEvent{
+		Type:         t,
+		Time:         time.Now(),
+		Organization: e.organization,
+		Pipeline:     e.pipeline,
+		BuildNumber:  e.buildNumber,
+		BuildURL:     b.WebURL,
+		BuildState:   b.State,
+	}
+}
+
+// Status emits an event for each job change in status, followed by a
+// build_status event when the build state or job counts have changed since
+// the previous poll.
+func (e *watchEventEmitter) Status(status watch.BuildStatus) error {
+	jobEvents := []struct {
+		t    watchEventType
+		jobs []buildkite.Job
+	}{
+		{watchEventJobFailure, status.NewlyFailed},
+		{watchEventJobRetryPassed, status.NewlyRetryPassed},
+		{watchEventJobPromisedFailure, status.NewlyPromisedFailure},
+	}
+	for _, je := range jobEvents {
+		for _, j := range je.jobs {
+			ev := e.event(je.t, status.Build)
+			job := newWatchEventJob(j)
+			ev.Job = &job
+			if err := e.encoder.Encode(ev); err != nil {
+				return err
+			}
+		}
+	}
+
+	if e.emitted && status.Build.State == e.lastState && status.Summary == e.lastSummary &&
+		triggeredStatesKey(status.Triggered) == triggeredStatesKey(e.lastTriggered) {
+		return nil
+	}
+	e.emitted = true
+	e.lastState = status.Build.State
+	e.lastSummary = status.Summary
+	e.lastTriggered = status.Triggered
+
+	ev := e.event(watchEventBuildStatus, status.Build)
+	summary := status.Summary
+	ev.Jobs = &summary
+	e.addTriggered(&ev, status.Build)
+	return e.encoder.Encode(ev)
+}
+
+func (e *watchEventEmitter) addTriggered(ev *watchEvent, b buildkite.Build) {
+	if len(e.lastTriggered) == 0 {
+		return
+	}
+	ev.RollupState = watch.RollupState(b.State, e.lastTriggered)
+	ev.TriggeredBuilds = newWatchEventTriggeredBuilds(e.lastTriggered)
+}
+
+// Summary emits the final build_summary event. incomplete is set when watching
+// stopped before the build finished.
+func (e *watchEventEmitter) Summary(b buildkite.Build, tracker *watch.JobTracker, incomplete bool) error {
+	ev := e.event(watchEventBuildSummary, b)
+	summary := e.lastSummary
+	ev.Jobs = &summary
+	ev.Incomplete = incomplete
+	e.addTriggered(&ev, b)
+	for _, j := range tracker.FailedJobs() {
+		ev.FailedJobs = append(ev.FailedJobs, newWatchEventJob(j))
+	}
+	if b.StartedAt != nil {
+		end := time.Now()
+		if b.FinishedAt != nil {
+			end = b.FinishedAt.Time
+		}
+		ev.Duration = end.Sub(b.StartedAt.Time)
+	}
+	return e.encoder.Encode(ev)
+}
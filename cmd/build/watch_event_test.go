@@ -0,0 +1,166 @@
+package build
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/build/watch"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func decodeWatchEvents(t *testing.T, out *bytes.Buffer) []watchEvent {
+	t.Helper()
+	var events []watchEvent
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		var ev watchEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, ev)
+	}
+	out.Reset()
+	return events
+}
+
+func TestWatchEventEmitter_Status(t *testing.T) {
+	var out bytes.Buffer
+	e := newWatchEventEmitter(&out, "acme", "widgets", 42)
+
+	build := buildkite.Build{Number: 42, State: "running", WebURL: "https://buildkite.com/acme/widgets/builds/42"}
+	if err := e.Status(watch.BuildStatus{Build: build, Summary: watch.JobSummary{Running: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	events := decodeWatchEvents(t, &out)
+	if len(events) != 1 || events[0].Type != watchEventBuildStatus {
+		t.Fatalf("expected a single build_status event, got %+v", events)
+	}
+	if events[0].Organization != "acme" || events[0].Pipeline != "widgets" || events[0].BuildNumber != 42 || events[0].BuildState != "running" {
+		t.Errorf("unexpected build fields: %+v", events[0])
+	}
+	if events[0].Jobs == nil || events[0].Jobs.Running != 2 {
+		t.Errorf("expected job counts, got %+v", events[0].Jobs)
+	}
+
+	// An unchanged poll emits nothing.
+	if err := e.Status(watch.BuildStatus{Build: build, Summary: watch.JobSummary{Running: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if events := decodeWatchEvents(t, &out); len(events) != 0 {
+		t.Fatalf("expected duplicate status to be suppressed, got %+v", events)
+	}
+
+	exitStatus := 1
+	build.State = "failing"
+	failed := buildkite.Job{ID: "job-1", Name: "Test", StepKey: "test", State: "failed", ExitStatus: &exitStatus}
+	retried := buildkite.Job{ID: "job-2", Name: "Lint", State: "passed", RetriesCount: 1}
+	if err := e.Status(watch.BuildStatus{
+		Build:            build,
+		NewlyFailed:      []buildkite.Job{failed},
+		NewlyRetryPassed: []buildkite.Job{retried},
+		Summary:          watch.JobSummary{Failed: 1, Passed: 1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	events = decodeWatchEvents(t, &out)
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %+v", events)
+	}
+	if events[0].Type != watchEventJobFailure || events[0].Job == nil || events[0].Job.ID != "job-1" || events[0].Job.StepKey != "test" {
+		t.Errorf("unexpected job_failure event: %+v", events[0])
+	}
+	if events[0].Job.ExitStatus == nil || *events[0].Job.ExitStatus != 1 {
+		t.Errorf("expected exit status 1, got %v", events[0].Job.ExitStatus)
+	}
+	if events[1].Type != watchEventJobRetryPassed || events[1].Job.RetriesCount != 1 {
+		t.Errorf("unexpected job_retry_passed event: %+v", events[1])
+	}
+	if events[2].Type != watchEventBuildStatus || events[2].BuildState != "failing" {
+		t.Errorf("expected build_status for the state change, got %+v", events[2])
+	}
+}
+
+func TestWatchEventEmitter_Summary(t *testing.T) {
+	var out bytes.Buffer
+	e := newWatchEventEmitter(&out, "acme", "widgets", 42)
+
+	exitStatus := 1
+	started := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	finished := started.Add(90 * time.Second)
+	build := buildkite.Build{
+		Number:     42,
+		State:      "failed",
+		StartedAt:  &buildkite.Timestamp{Time: started},
+		FinishedAt: &buildkite.Timestamp{Time: finished},
+		Jobs: []buildkite.Job{
+			{ID: "job-1", Type: "script", Name: "Test", State: "failed", ExitStatus: &exitStatus},
+			{ID: "job-2", Type: "script", Name: "Lint", State: "passed"},
+		},
+	}
+
+	tracker := watch.NewJobTracker()
+	if err := e.Status(tracker.Update(build)); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+
+	if err := e.Summary(build, tracker, false); err != nil {
+		t.Fatal(err)
+	}
+	events := decodeWatchEvents(t, &out)
+	if len(events) != 1 || events[0].Type != watchEventBuildSummary {
+		t.Fatalf("expected a single build_summary event, got %+v", events)
+	}
+	got := events[0]
+	if got.Duration != 90*time.Second {
+		t.Errorf("duration = %v, want 90s", got.Duration)
+	}
+	if got.Incomplete {
+		t.Errorf("expected a finished build not to be marked incomplete")
+	}
+	if len(got.FailedJobs) != 1 || got.FailedJobs[0].ID != "job-1" {
+		t.Errorf("unexpected failed jobs: %+v", got.FailedJobs)
+	}
+	if got.Jobs == nil || got.Jobs.Failed != 1 || got.Jobs.Passed != 1 {
+		t.Errorf("unexpected job counts: %+v", got.Jobs)
+	}
+}
+
+func TestWatchEventEmitter_TriggeredBuilds(t *testing.T) {
+	var out bytes.Buffer
+	e := newWatchEventEmitter(&out, "acme", "widgets", 42)
+
+	build := buildkite.Build{Number: 42, State: "passed"}
+	child := &watch.BuildTree{Organization: "acme", Pipeline: "deploy", TriggerJobID: "trigger", Build: buildkite.Build{Number: 7, State: "running"}, RollupState: "running"}
+	if err := e.Status(watch.BuildStatus{Build: build, Triggered: []*watch.BuildTree{child}}); err != nil {
+		t.Fatal(err)
+	}
+	events := decodeWatchEvents(t, &out)
+	if len(events) != 1 || events[0].RollupState != "running" || len(events[0].TriggeredBuilds) != 1 {
+		t.Fatalf("expected build_status with triggered builds, got %+v", events)
+	}
+	if tb := events[0].TriggeredBuilds[0]; tb.Pipeline != "deploy" || tb.BuildNumber != 7 || tb.TriggerJobID != "trigger" {
+		t.Errorf("unexpected triggered build: %+v", tb)
+	}
+
+	// A change in a triggered build alone emits a new build_status.
+	finished := *child
+	finished.Build.State, finished.RollupState = "failed", "failed"
+	if err := e.Status(watch.BuildStatus{Build: build, Triggered: []*watch.BuildTree{&finished}}); err != nil {
+		t.Fatal(err)
+	}
+	events = decodeWatchEvents(t, &out)
+	if len(events) != 1 || events[0].BuildState != "passed" || events[0].RollupState != "failed" {
+		t.Fatalf("expected build_status for the triggered build's change, got %+v", events)
+	}
+
+	if err := e.Summary(build, watch.NewJobTracker(), false); err != nil {
+		t.Fatal(err)
+	}
+	if events := decodeWatchEvents(t, &out); len(events) != 1 || events[0].RollupState != "failed" {
+		t.Errorf("expected build_summary to carry the rolled-up state, got %+v", events)
+	}
+}
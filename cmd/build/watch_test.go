@@ -0,0 +1,59 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/build/watch"
+	bkErrors "github.com/buildkite/cli/v3/internal/errors"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestWatchOutcome(t *testing.T) {
+	running := buildkite.Build{Number: 42, State: "running"}
+
+	t.Run("reports the build outcome", func(t *testing.T) {
+		err := watchOutcome(context.Background(), buildkite.Build{Number: 42, State: "failed"}, nil, 0)
+		if !bkErrors.IsBuildFailed(err) {
+			t.Fatalf("expected build failed error, got %v", err)
+		}
+		if err := watchOutcome(context.Background(), buildkite.Build{Number: 42, State: "passed"}, nil, 0); err != nil {
+			t.Fatalf("expected nil for a passed build, got %v", err)
+		}
+	})
+
+	t.Run("a failed triggered build fails the watch", func(t *testing.T) {
+		triggered := []*watch.BuildTree{{Build: buildkite.Build{State: "failed"}, RollupState: "failed"}}
+		err := watchOutcome(context.Background(), rolledUp(buildkite.Build{Number: 42, State: "passed"}, triggered), nil, 0)
+		if !bkErrors.IsBuildFailed(err) {
+			t.Fatalf("expected build failed error, got %v", err)
+		}
+	})
+
+	t.Run("interrupt is not an error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := watchOutcome(ctx, running, context.Canceled, 0); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+		err := watchOutcome(ctx, running, ctx.Err(), time.Minute)
+		if bkErrors.GetExitCodeForError(err) != bkErrors.ExitCodeBuildTimedOut {
+			t.Fatalf("expected timed out exit code, got %v", err)
+		}
+	})
+
+	t.Run("request errors pass through", func(t *testing.T) {
+		apiErr := errors.New("fetching build status (3 consecutive errors): boom")
+		if err := watchOutcome(context.Background(), running, apiErr, 0); !errors.Is(err, apiErr) {
+			t.Fatalf("expected %v, got %v", apiErr, err)
+		}
+	})
+}
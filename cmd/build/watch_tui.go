@@ -0,0 +1,669 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	buildstate "github.com/buildkite/cli/v3/internal/build/state"
+	"github.com/buildkite/cli/v3/internal/build/watch"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// watchLogRefreshInterval is how often the log pane re-fetches the selected
+// job's log while that job is still running.
+const watchLogRefreshInterval = 2 * time.Second
+
+var (
+	watchDimStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	watchStatusStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFBA03")).Bold(true)
+	watchBorderStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+	watchFailureStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	watchSoftFailureStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	watchPassedStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	watchSelectedStyle    = lipgloss.NewStyle().Reverse(true)
+)
+
+// watchHighlight marks a job whose state changed in a way worth drawing the
+// user's attention to, as reported by watch.JobTracker.
+type watchHighlight int
+
+const (
+	watchHighlightNone watchHighlight = iota
+	watchHighlightFailed
+	watchHighlightRetryPassed
+	watchHighlightPromisedFailure
+)
+
+// watchStatusMsg carries the tracker output for a single poll.
+type watchStatusMsg watch.BuildStatus
+
+// watchDoneMsg is sent once WatchBuild returns.
+type watchDoneMsg struct {
+	build buildkite.Build
+	err   error
+}
+
+// watchLogMsg carries a freshly fetched log for a job.
+type watchLogMsg struct {
+	jobID     string
+	content   string
+	err       error
+	fetchedAt time.Time
+}
+
+type watchLogTickMsg struct{}
+
+// watchStep is a node in the job tree: a pipeline step and the jobs it
+// produced (more than one for parallel and matrix steps). A wait step is
+// represented as a separator between stages.
+type watchStep struct {
+	label string
+	wait  bool
+	jobs  []buildkite.Job
+}
+
+// watchLogFetcher fetches the raw log content for a job in the watched build.
+type watchLogFetcher func(buildID, jobID string) (string, error)
+
+type watchModel struct {
+	organization string
+	pipeline     string
+	buildNumber  int
+
+	spinner    spinner.Model
+	status     watch.BuildStatus
+	steps      []watchStep
+	selectable []buildkite.Job
+	selected   string
+	pinned     bool
+	highlights map[string]watchHighlight
+	logs       map[string]watchLogMsg
+	fetchLog   watchLogFetcher
+	actions    watchActions
+	notice     watchActionMsg
+	cancelFunc context.CancelFunc
+
+	width    int
+	height   int
+	finished bool
+	err      error
+}
+
+func newWatchModel(organization, pipeline string, buildNumber int, fetchLog watchLogFetcher, actions watchActions, cancel context.CancelFunc) watchModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#DE8F0C"))
+	return watchModel{
+		organization: organization,
+		pipeline:     pipeline,
+		buildNumber:  buildNumber,
+		spinner:      s,
+		highlights:   make(map[string]watchHighlight),
+		logs:         make(map[string]watchLogMsg),
+		fetchLog:     fetchLog,
+		actions:      actions,
+		cancelFunc:   cancel,
+	}
+}
+
+func (m watchModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, watchLogTick())
+}
+
+func (m watchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			if m.cancelFunc != nil {
+				m.cancelFunc()
+			}
+			return m, tea.Quit
+		case "up", "k":
+			return m.moveSelection(-1)
+		case "down", "j":
+			return m.moveSelection(1)
+		case "home", "g":
+			return m.moveSelection(-len(m.selectable))
+		case "end", "G":
+			return m.moveSelection(len(m.selectable))
+		case "f":
+			return m.selectNextFailure()
+		case "r":
+			return m.retrySelected()
+		case "u":
+			return m.unblockSelected()
+		case "c":
+			return m.cancelWatchedBuild()
+		case "o":
+			return m.openSelected()
+		}
+
+	case watchStatusMsg:
+		status := watch.BuildStatus(msg)
+		m.status = status
+		for _, j := range status.NewlyFailed {
+			m.highlights[j.ID] = watchHighlightFailed
+		}
+		for _, j := range status.NewlyRetryPassed {
+			m.highlights[j.ID] = watchHighlightRetryPassed
+		}
+		for _, j := range status.NewlyPromisedFailure {
+			m.highlights[j.ID] = watchHighlightPromisedFailure
+		}
+		m.steps = groupWatchSteps(status.Build.Jobs)
+		m.selectable = selectableJobs(m.steps)
+
+		// A retried job is replaced in the tree by its retry, so move the
+		// selection along with it.
+		for _, j := range status.Build.Jobs {
+			if j.ID == m.selected && j.Retried && j.RetriedInJobID != "" {
+				m.selected = j.RetriedInJobID
+				break
+			}
+		}
+
+		// Until the user picks a job themselves, follow the most interesting
+		// one: a job that has just failed, otherwise the first running job.
+		if !m.pinned {
+			switch {
+			case len(status.NewlyFailed) > 0:
+				m.selected = status.NewlyFailed[0].ID
+			case len(status.NewlyPromisedFailure) > 0:
+				m.selected = status.NewlyPromisedFailure[0].ID
+			case len(status.Running) > 0 && !m.isSelectedRunning():
+				m.selected = status.Running[0].ID
+			}
+		}
+		if m.selectedIndex() < 0 && len(m.selectable) > 0 {
+			m.selected = m.selectable[0].ID
+		}
+		if _, ok := m.logs[m.selected]; !ok {
+			return m, m.fetchSelectedLog()
+		}
+		return m, nil
+
+	case watchLogMsg:
+		m.logs[msg.jobID] = msg
+		return m, nil
+
+	case watchActionMsg:
+		m.notice = msg
+		return m, nil
+
+	case watchLogTickMsg:
+		var cmd tea.Cmd
+		if m.selectedLogStale() {
+			cmd = m.fetchSelectedLog()
+		}
+		return m, tea.Batch(cmd, watchLogTick())
+
+	case watchDoneMsg:
+		m.finished = true
+		m.err = msg.err
+		if msg.build.Number != 0 {
+			m.status.Build = msg.build
+		}
+		return m, tea.Quit
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m watchModel) moveSelection(delta int) (tea.Model, tea.Cmd) {
+	if len(m.selectable) == 0 {
+		return m, nil
+	}
+	i := max(m.selectedIndex(), 0) + delta
+	i = min(max(i, 0), len(m.selectable)-1)
+	m.selected = m.selectable[i].ID
+	m.pinned = true
+	if _, ok := m.logs[m.selected]; !ok {
+		return m, m.fetchSelectedLog()
+	}
+	return m, nil
+}
+
+// selectNextFailure moves the selection to the next failed job after the
+// current one, wrapping around to the top of the tree.
+func (m watchModel) selectNextFailure() (tea.Model, tea.Cmd) {
+	start := m.selectedIndex()
+	for offset := 1; offset <= len(m.selectable); offset++ {
+		i := (start + offset + len(m.selectable)) % len(m.selectable)
+		if watch.NewFormattedJob(m.selectable[i]).IsFailed() {
+			return m.moveSelection(i - max(start, 0))
+		}
+	}
+	return m, nil
+}
+
+func (m watchModel) selectedIndex() int {
+	for i, j := range m.selectable {
+		if j.ID == m.selected {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m watchModel) selectedJob() (buildkite.Job, bool) {
+	if i := m.selectedIndex(); i >= 0 {
+		return m.selectable[i], true
+	}
+	return buildkite.Job{}, false
+}
+
+func (m watchModel) isSelectedRunning() bool {
+	j, ok := m.selectedJob()
+	return ok && watch.NewFormattedJob(j).IsRunning()
+}
+
+// selectedLogStale reports whether the selected job's log may have changed
+// since it was last fetched: the job is still running, or it finished shortly
+// before (or after) the last fetch and the tail may not have been flushed yet.
+func (m watchModel) selectedLogStale() bool {
+	j, ok := m.selectedJob()
+	if !ok {
+		return false
+	}
+	if watch.NewFormattedJob(j).IsRunning() {
+		return true
+	}
+	log, fetched := m.logs[j.ID]
+	return fetched && j.FinishedAt != nil && log.fetchedAt.Before(j.FinishedAt.Add(watchLogRefreshInterval))
+}
+
+func (m watchModel) fetchSelectedLog() tea.Cmd {
+	j, ok := m.selectedJob()
+	if !ok || m.fetchLog == nil || j.Type != "script" || j.StartedAt == nil {
+		return nil
+	}
+	buildID, jobID, fetch := m.status.Build.ID, j.ID, m.fetchLog
+	return func() tea.Msg {
+		content, err := fetch(buildID, jobID)
+		return watchLogMsg{jobID: jobID, content: content, err: err, fetchedAt: time.Now()}
+	}
+}
+
+func watchLogTick() tea.Cmd {
+	return tea.Tick(watchLogRefreshInterval, func(time.Time) tea.Msg {
+		return watchLogTickMsg{}
+	})
+}
+
+// groupWatchSteps arranges a build's jobs into a tree of steps. Jobs sharing a
+// step key (parallel and matrix jobs) are grouped under a single step, and
+// jobs superseded by a retry are hidden in favour of the retry.
+//
+// Group steps aren't shown as parents: neither the REST API's jobs nor the
+// GraphQL API's steps say which group a step belongs to, only its own key.
+func groupWatchSteps(jobs []buildkite.Job) []watchStep {
+	var steps []watchStep
+	index := make(map[string]int)
+
+	for _, j := range jobs {
+		if j.Retried {
+			continue
+		}
+		if j.Type == "waiter" {
+			if len(steps) > 0 && !steps[len(steps)-1].wait {
+				steps = append(steps, watchStep{wait: true})
+			}
+			// Step keys are unique within a build, but labels are not, so
+			// only group by label within a single stage.
+			index = make(map[string]int)
+			continue
+		}
+
+		name := watch.NewFormattedJob(j).DisplayName()
+		key := "label:" + name
+		if j.StepKey != "" {
+			key = "key:" + j.StepKey
+		}
+		if i, ok := index[key]; ok {
+			steps[i].jobs = append(steps[i].jobs, j)
+			continue
+		}
+		index[key] = len(steps)
+		steps = append(steps, watchStep{label: name, jobs: []buildkite.Job{j}})
+	}
+
+	if len(steps) > 0 && steps[len(steps)-1].wait {
+		steps = steps[:len(steps)-1]
+	}
+	return steps
+}
+
+func selectableJobs(steps []watchStep) []buildkite.Job {
+	var jobs []buildkite.Job
+	for _, s := range steps {
+		jobs = append(jobs, s.jobs...)
+	}
+	return jobs
+}
+
+func (m watchModel) View() string {
+	if m.finished {
+		return ""
+	}
+
+	header := m.renderHeader()
+	footer := watchDimStyle.Render("  ↑/↓ select • f next failure • r retry • u unblock • c cancel build • o open • q quit")
+	switch {
+	case m.notice.err != nil:
+		footer = "  " + watchFailureStyle.Render(m.notice.err.Error()) + "\n" + footer
+	case m.notice.text != "":
+		footer = "  " + watchDimStyle.Render(m.notice.text) + "\n" + footer
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = 100
+	}
+	bodyHeight := m.height - lipgloss.Height(header) - lipgloss.Height(footer)
+	if m.height <= 0 || bodyHeight < 5 {
+		bodyHeight = 20
+	}
+
+	treeWidth := min(max(width*2/5, 30), 60)
+	logWidth := max(width-treeWidth-3, 10)
+
+	tree := padLines(m.renderTree(treeWidth, bodyHeight), treeWidth, bodyHeight)
+	logPane := padLines(m.renderLog(logWidth, bodyHeight), logWidth, bodyHeight)
+	separator := strings.TrimSuffix(strings.Repeat(watchBorderStyle.Render(" │ ")+"\n", bodyHeight), "\n")
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top, tree, separator, logPane)
+	return header + "\n" + body + "\n" + footer
+}
+
+func (m watchModel) renderHeader() string {
+	separator := watchBorderStyle.Render(strings.Repeat("─", 45))
+
+	state := m.status.Build.State
+	if state == "" {
+		state = "starting"
+	} else if len(m.status.Triggered) > 0 {
+		state = watch.RollupState(state, m.status.Triggered)
+	}
+	title := fmt.Sprintf("Watching build #%d on %s/%s (%s)", m.buildNumber, m.organization, m.pipeline, state)
+	statusLine := fmt.Sprintf("  %s %s", m.spinner.View(), watchStatusStyle.Render(title))
+
+	summary := m.status.Summary
+	parts := make([]string, 0, 6)
+	appendPart := func(count int, text string) {
+		if count > 0 {
+			parts = append(parts, text)
+		}
+	}
+	appendPart(summary.Passed, fmt.Sprintf("%d passed", summary.Passed))
+	appendPart(summary.Failed, watchFailureStyle.Render(fmt.Sprintf("%d failed", summary.Failed)))
+	appendPart(summary.SoftFailed, watchSoftFailureStyle.Render(fmt.Sprintf("%d soft failed", summary.SoftFailed)))
+	appendPart(summary.Running, fmt.Sprintf("%d running", summary.Running))
+	appendPart(summary.Scheduled, fmt.Sprintf("%d scheduled", summary.Scheduled))
+	appendPart(summary.Blocked, fmt.Sprintf("%d blocked", summary.Blocked))
+	appendPart(summary.Waiting, fmt.Sprintf("%d waiting", summary.Waiting))
+
+	summaryLine := "  " + watchDimStyle.Render(strings.Join(parts, ", "))
+	return separator + "\n" + statusLine + "\n" + summaryLine + "\n" + separator
+}
+
+func (m watchModel) renderTree(width, height int) []string {
+	var lines []string
+	selectedLine := 0
+
+	triggered := make(map[string]*watch.BuildTree, len(m.status.Triggered))
+	for _, t := range m.status.Triggered {
+		triggered[t.TriggerJobID] = t
+	}
+
+	for _, s := range m.steps {
+		if s.wait {
+			lines = append(lines, watchBorderStyle.Render(strings.Repeat("┄", width)))
+			continue
+		}
+
+		indent := ""
+		if len(s.jobs) > 1 {
+			lines = append(lines, fmt.Sprintf("%s %s %s", stepRollupIcon(s.jobs), s.label, watchDimStyle.Render(stepRollupCounts(s.jobs))))
+			indent = "  "
+		}
+		for _, j := range s.jobs {
+			line := indent + m.renderJobLine(j, len(s.jobs) > 1)
+			if j.ID == m.selected {
+				selectedLine = len(lines)
+				line = watchSelectedStyle.Render(ansi.Strip(ansi.Truncate(line, width, "…")))
+			}
+			lines = append(lines, line)
+			if t, ok := triggered[j.ID]; ok {
+				lines = append(lines, renderTriggeredBuildLines([]*watch.BuildTree{t}, indent+"  ")...)
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		return []string{watchDimStyle.Render("Waiting for jobs...")}
+	}
+
+	// Scroll so the selected job stays in view.
+	if len(lines) > height {
+		start := min(max(selectedLine-height/2, 0), len(lines)-height)
+		lines = lines[start : start+height]
+	}
+	return lines
+}
+
+func (m watchModel) renderJobLine(j buildkite.Job, parallel bool) string {
+	job := watch.NewFormattedJob(j)
+	name := job.DisplayName()
+	if parallel && j.ParallelGroupIndex != nil {
+		name = fmt.Sprintf("#%d", *j.ParallelGroupIndex+1)
+	}
+
+	line := fmt.Sprintf("%s %s", jobStateIcon(j), name)
+	if d := job.Duration(); d > 0 {
+		line += " " + watchDimStyle.Render(d.String())
+	}
+
+	switch m.highlights[j.ID] {
+	case watchHighlightFailed:
+		style := watchFailureStyle
+		if job.IsSoftFailed() {
+			style = watchSoftFailureStyle
+		}
+		return style.Render(line)
+	case watchHighlightRetryPassed:
+		return watchPassedStyle.Render(line + " (passed on retry)")
+	case watchHighlightPromisedFailure:
+		if job.IsRunning() {
+			return watchSoftFailureStyle.Render(line + " (failing)")
+		}
+		if job.IsFailed() {
+			return watchFailureStyle.Render(line)
+		}
+	}
+	return line
+}
+
+// renderTriggeredBuildLines renders the builds started by a trigger job, and
+// the builds they triggered in turn, beneath the trigger job's line.
+func renderTriggeredBuildLines(trees []*watch.BuildTree, indent string) []string {
+	var lines []string
+	for _, t := range trees {
+		state := t.RollupState
+		if state == "" {
+			state = t.Build.State
+		}
+		line := fmt.Sprintf("%s→ %s %s #%d", indent, buildStateIcon(state), t.Pipeline, t.Build.Number)
+		switch {
+		case t.Err != nil:
+			line += " " + watchFailureStyle.Render("(unavailable)")
+		case state != "":
+			line += " " + watchDimStyle.Render(state)
+		}
+		lines = append(lines, line)
+		lines = append(lines, renderTriggeredBuildLines(t.Children, indent+"  ")...)
+	}
+	return lines
+}
+
+func (m watchModel) renderLog(width, height int) []string {
+	j, ok := m.selectedJob()
+	if !ok {
+		return nil
+	}
+
+	title := watchStatusStyle.Render(ansi.Truncate(watch.NewFormattedJob(j).DisplayName(), max(width-len(j.State)-3, 1), "…")) + " " + watchDimStyle.Render("("+j.State+")")
+	lines := []string{title}
+
+	log, fetched := m.logs[j.ID]
+	switch {
+	case j.Type != "script":
+		lines = append(lines, watchDimStyle.Render("No log for "+j.Type+" steps"))
+	case j.StartedAt == nil:
+		lines = append(lines, watchDimStyle.Render("Job has not started yet"))
+	case !fetched:
+		lines = append(lines, watchDimStyle.Render("Loading log..."))
+	case log.err != nil:
+		lines = append(lines, watchFailureStyle.Render(ansi.Truncate("Error fetching log: "+log.err.Error(), width, "…")))
+	default:
+		logLines := tailLogLines(log.content, height-1)
+		for _, l := range logLines {
+			lines = append(lines, ansi.Truncate(l, width, "…"))
+		}
+	}
+	return lines
+}
+
+// tailLogLines returns the last n displayable lines of a job log, with
+// timestamp markers removed and carriage-return progress output collapsed to
+// its final state.
+func tailLogLines(content string, n int) []string {
+	content = internaljob.StripTimestamps(content)
+	content = strings.TrimRight(content, "\r\n")
+	if content == "" || n <= 0 {
+		return nil
+	}
+
+	lines := strings.Split(content, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for i, l := range lines {
+		l = strings.TrimRight(l, "\r")
+		if idx := strings.LastIndex(l, "\r"); idx >= 0 {
+			l = l[idx+1:]
+		}
+		lines[i] = strings.ReplaceAll(l, "\t", "    ")
+	}
+	return lines
+}
+
+// padLines truncates or pads lines so the block is exactly width x height,
+// which keeps lipgloss.JoinHorizontal from misaligning the panes.
+func padLines(lines []string, width, height int) string {
+	out := make([]string, height)
+	for i := range out {
+		if i < len(lines) {
+			l := ansi.Truncate(lines[i], width, "…")
+			out[i] = l + strings.Repeat(" ", max(width-ansi.StringWidth(l), 0))
+		} else {
+			out[i] = strings.Repeat(" ", width)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+func jobStateIcon(j buildkite.Job) string {
+	job := watch.NewFormattedJob(j)
+	switch {
+	case job.IsSoftFailed():
+		return watchSoftFailureStyle.Render("⚠")
+	case job.IsFailed():
+		return watchFailureStyle.Render("✗")
+	case job.IsRunning():
+		return watchStatusStyle.Render("●")
+	}
+
+	switch j.State {
+	case "passed":
+		return watchPassedStyle.Render("✔")
+	case "blocked", "blocked_failed":
+		return watchStatusStyle.Render("◆")
+	case "skipped", "broken", "not_run":
+		return watchDimStyle.Render("-")
+	default:
+		return watchDimStyle.Render("○")
+	}
+}
+
+func buildStateIcon(state string) string {
+	switch buildstate.State(state) {
+	case buildstate.Passed:
+		return watchPassedStyle.Render("✔")
+	case buildstate.Failed, buildstate.Failing:
+		return watchFailureStyle.Render("✗")
+	case buildstate.Running, buildstate.Canceling:
+		return watchStatusStyle.Render("●")
+	case buildstate.Blocked:
+		return watchStatusStyle.Render("◆")
+	case buildstate.Canceled, buildstate.Skipped, buildstate.NotRun:
+		return watchDimStyle.Render("-")
+	default:
+		return watchDimStyle.Render("○")
+	}
+}
+
+// stepRollupIcon summarises the state of a step's jobs: failed if any job
+// failed, running if any job is still running, passed once all have passed.
+func stepRollupIcon(jobs []buildkite.Job) string {
+	var failed, running bool
+	passed := 0
+	for _, j := range jobs {
+		job := watch.NewFormattedJob(j)
+		switch {
+		case job.IsFailed() && !job.IsSoftFailed():
+			failed = true
+		case job.IsRunning():
+			running = true
+		case j.State == "passed" || job.IsSoftFailed():
+			passed++
+		}
+	}
+	switch {
+	case failed:
+		return watchFailureStyle.Render("✗")
+	case running:
+		return watchStatusStyle.Render("●")
+	case passed == len(jobs):
+		return watchPassedStyle.Render("✔")
+	default:
+		return watchDimStyle.Render("○")
+	}
+}
+
+func stepRollupCounts(jobs []buildkite.Job) string {
+	var passed int
+	for _, j := range jobs {
+		if j.State == "passed" {
+			passed++
+		}
+	}
+	return fmt.Sprintf("(%d/%d passed)", passed, len(jobs))
+}
@@ -0,0 +1,294 @@
+package build
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/build/watch"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGroupWatchSteps(t *testing.T) {
+	jobs := []buildkite.Job{
+		{ID: "lint", Type: "script", Name: "Lint", StepKey: "lint", State: "passed"},
+		{ID: "test-1", Type: "script", Name: "Test", StepKey: "test", State: "failed", Retried: true},
+		{ID: "test-1-retry", Type: "script", Name: "Test", StepKey: "test", State: "passed"},
+		{ID: "test-2", Type: "script", Name: "Test", StepKey: "test", State: "running"},
+		{ID: "wait-1", Type: "waiter"},
+		{ID: "wait-2", Type: "waiter"},
+		{ID: "deploy", Type: "script", Label: "Deploy", State: "waiting"},
+		{ID: "wait-3", Type: "waiter"},
+	}
+
+	steps := groupWatchSteps(jobs)
+	if len(steps) != 4 {
+		t.Fatalf("expected 4 steps (lint, test, wait, deploy), got %d: %+v", len(steps), steps)
+	}
+
+	if steps[0].label != "Lint" || len(steps[0].jobs) != 1 {
+		t.Errorf("unexpected lint step: %+v", steps[0])
+	}
+
+	var ids []string
+	for _, j := range steps[1].jobs {
+		ids = append(ids, j.ID)
+	}
+	if got := strings.Join(ids, ","); got != "test-1-retry,test-2" {
+		t.Errorf("test step jobs = %s, want retried job hidden", got)
+	}
+
+	if !steps[2].wait {
+		t.Errorf("expected consecutive waiters to collapse into one separator, got %+v", steps[2])
+	}
+	if steps[3].label != "Deploy" {
+		t.Errorf("expected deploy step last with trailing waiter trimmed, got %+v", steps[3])
+	}
+}
+
+func TestWatchModel_HighlightsAndFollowsFailures(t *testing.T) {
+	started := &buildkite.Timestamp{Time: time.Now().Add(-time.Minute)}
+	build := buildkite.Build{
+		ID:     "build-id",
+		Number: 42,
+		State:  "running",
+		Jobs: []buildkite.Job{
+			{ID: "a", Type: "script", Name: "Lint", State: "running", StartedAt: started},
+			{ID: "b", Type: "script", Name: "Test", State: "running", StartedAt: started},
+		},
+	}
+
+	var fetched []string
+	fetch := func(buildID, jobID string) (string, error) {
+		fetched = append(fetched, buildID+"/"+jobID)
+		return "", nil
+	}
+
+	var m tea.Model = newWatchModel("acme", "widgets", 42, fetch, nil, nil)
+	m, cmd := m.Update(watchStatusMsg(watch.BuildStatus{Build: build, Running: build.Jobs}))
+	if got := m.(watchModel).selected; got != "a" {
+		t.Fatalf("expected first running job to be selected, got %q", got)
+	}
+	if cmd == nil {
+		t.Fatal("expected a log fetch for the selected job")
+	}
+	m, _ = m.Update(cmd())
+	if len(fetched) != 1 || fetched[0] != "build-id/a" {
+		t.Fatalf("unexpected log fetches: %v", fetched)
+	}
+
+	build.Jobs[1].State = "failed"
+	m, _ = m.Update(watchStatusMsg(watch.BuildStatus{Build: build, NewlyFailed: []buildkite.Job{build.Jobs[1]}}))
+	wm := m.(watchModel)
+	if wm.selected != "b" {
+		t.Errorf("expected selection to follow the newly failed job, got %q", wm.selected)
+	}
+	if wm.highlights["b"] != watchHighlightFailed {
+		t.Errorf("expected job b to be highlighted as failed")
+	}
+
+	// Once the user navigates, the selection stays where they put it.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	build.Jobs[0].State = "failed"
+	m, _ = m.Update(watchStatusMsg(watch.BuildStatus{Build: build, NewlyFailed: []buildkite.Job{build.Jobs[0]}}))
+	if got := m.(watchModel).selected; got != "a" {
+		t.Errorf("expected pinned selection to stay on a, got %q", got)
+	}
+}
+
+func TestWatchModel_ViewShowsTreeAndLog(t *testing.T) {
+	started := &buildkite.Timestamp{Time: time.Now().Add(-time.Minute)}
+	build := buildkite.Build{
+		ID:     "build-id",
+		Number: 42,
+		State:  "failing",
+		Jobs: []buildkite.Job{
+			{ID: "a", Type: "script", Name: "Unit tests", State: "passed", StartedAt: started, FinishedAt: started},
+			{ID: "b", Type: "script", Name: "Integration", State: "running", StartedAt: started, RetriesCount: 1},
+		},
+	}
+
+	var m tea.Model = newWatchModel("acme", "widgets", 42, nil, nil, nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m, _ = m.Update(watchStatusMsg(watch.BuildStatus{
+		Build:            build,
+		Running:          build.Jobs[1:],
+		NewlyRetryPassed: build.Jobs[:1],
+		Summary:          watch.JobSummary{Passed: 1, Running: 1},
+	}))
+	m, _ = m.Update(watchLogMsg{jobID: "b", content: "\x1b_bk;t=1700000000000\x07--- Running tests\nprogress 10%\rprogress 100%\n"})
+
+	view := m.View()
+	for _, want := range []string{"Watching build #42 on acme/widgets (failing)", "1 passed", "1 running", "Unit tests", "passed on retry", "Integration", "--- Running tests", "progress 100%"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "bk;t=") || strings.Contains(view, "progress 10%") {
+		t.Errorf("view contains raw timestamps or overwritten progress output:\n%s", view)
+	}
+}
+
+func TestWatchModel_DoneQuits(t *testing.T) {
+	var m tea.Model = newWatchModel("acme", "widgets", 42, nil, nil, nil)
+	m, cmd := m.Update(watchDoneMsg{build: buildkite.Build{Number: 42, State: "passed"}})
+	if cmd == nil {
+		t.Fatal("expected quit command")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("expected tea.QuitMsg")
+	}
+	wm := m.(watchModel)
+	if !wm.finished || wm.status.Build.State != "passed" {
+		t.Errorf("expected finished model with final build, got %+v", wm.status.Build)
+	}
+	if m.View() != "" {
+		t.Errorf("expected empty view once finished")
+	}
+}
+
+func TestTailLogLines(t *testing.T) {
+	content := "one\ntwo\nthree\r\nfour\n"
+	got := tailLogLines(content, 2)
+	if strings.Join(got, "|") != "three|four" {
+		t.Errorf("tailLogLines = %q", got)
+	}
+	if tailLogLines("", 5) != nil {
+		t.Errorf("expected nil for empty content")
+	}
+}
+
+type fakeWatchActions struct {
+	confirmed bool
+	calls     []string
+}
+
+func (a *fakeWatchActions) confirm(prompt string) (bool, error) {
+	a.calls = append(a.calls, "confirm: "+prompt)
+	return a.confirmed, nil
+}
+
+func (a *fakeWatchActions) retry(j buildkite.Job) (string, error) {
+	a.calls = append(a.calls, "retry "+j.ID)
+	return "retried", nil
+}
+
+func (a *fakeWatchActions) unblock(j buildkite.Job) (string, error) {
+	a.calls = append(a.calls, "unblock "+j.ID)
+	return "unblocked", nil
+}
+
+func (a *fakeWatchActions) cancel() (string, error) {
+	a.calls = append(a.calls, "cancel")
+	return "canceled", nil
+}
+
+func (a *fakeWatchActions) open(url string) error {
+	a.calls = append(a.calls, "open "+url)
+	return nil
+}
+
+func TestWatchModel_KeyActions(t *testing.T) {
+	build := buildkite.Build{
+		ID:     "build-id",
+		Number: 42,
+		State:  "failing",
+		Jobs: []buildkite.Job{
+			{ID: "a", Type: "script", Name: "Lint", State: "passed", WebURL: "https://example.test/42#a"},
+			{ID: "b", Type: "script", Name: "Test", State: "failed"},
+			{ID: "c", Type: "manual", Label: "Deploy?", State: "blocked"},
+		},
+	}
+
+	actions := &fakeWatchActions{confirmed: true}
+	var m tea.Model = newWatchModel("acme", "widgets", 42, nil, actions, nil)
+	m, _ = m.Update(watchStatusMsg(watch.BuildStatus{Build: build}))
+
+	// Retrying a passed job is rejected without prompting.
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd != nil || m.(watchModel).notice.err == nil {
+		t.Fatalf("expected retry of a passed job to be rejected")
+	}
+
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	if cmd == nil {
+		t.Fatal("expected open command")
+	}
+	m, _ = m.Update(cmd())
+	if got := m.(watchModel).notice.text; got != "Opened https://example.test/42#a" {
+		t.Errorf("notice = %q", got)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if _, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}); cmd == nil {
+		t.Fatal("expected retry of a failed job to run an action")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if _, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")}); cmd == nil {
+		t.Fatal("expected unblock of a blocked step to run an action")
+	}
+
+	// Once retried, the selection follows the job to its retry.
+	build.Jobs[1].Retried = true
+	build.Jobs[1].RetriedInJobID = "b2"
+	build.Jobs = append(build.Jobs, buildkite.Job{ID: "b2", Type: "script", Name: "Test", State: "scheduled"})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m, _ = m.Update(watchStatusMsg(watch.BuildStatus{Build: build}))
+	if got := m.(watchModel).selected; got != "b2" {
+		t.Errorf("expected selection to follow the retry, got %q", got)
+	}
+}
+
+func TestWatchAction_Run(t *testing.T) {
+	actions := &fakeWatchActions{}
+	action := &watchAction{actions: actions, prompt: "Cancel build #42 on widgets", run: actions.cancel}
+	if err := action.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if action.result != "" || strings.Join(actions.calls, "|") != "confirm: Cancel build #42 on widgets" {
+		t.Fatalf("expected declined confirmation to skip the action, calls = %v", actions.calls)
+	}
+
+	actions.confirmed = true
+	if err := action.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if action.result != "canceled" {
+		t.Errorf("result = %q, want canceled", action.result)
+	}
+}
+
+func TestWatchModel_ViewShowsTriggeredBuilds(t *testing.T) {
+	build := buildkite.Build{
+		ID:     "build-id",
+		Number: 42,
+		State:  "passed",
+		Jobs: []buildkite.Job{
+			{ID: "deploy", Type: "trigger", Label: "Deploy", State: "passed"},
+		},
+	}
+	triggered := []*watch.BuildTree{{
+		Pipeline:     "deploy",
+		TriggerJobID: "deploy",
+		Build:        buildkite.Build{Number: 7, State: "passed"},
+		RollupState:  "failing",
+		Children: []*watch.BuildTree{{
+			Pipeline:    "infra",
+			Build:       buildkite.Build{Number: 3, State: "failed"},
+			RollupState: "failed",
+		}},
+	}}
+
+	var m tea.Model = newWatchModel("acme", "widgets", 42, nil, nil, nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m, _ = m.Update(watchStatusMsg(watch.BuildStatus{Build: build, Triggered: triggered}))
+
+	view := m.View()
+	for _, want := range []string{"Watching build #42 on acme/widgets (failing)", "Deploy", "deploy #7 failing", "infra #3 failed"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+}
@@ -0,0 +1,466 @@
+package commit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/alecthomas/kong"
+	buildstate "github.com/buildkite/cli/v3/internal/build/state"
+	"github.com/buildkite/cli/v3/internal/build/watch"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkErrors "github.com/buildkite/cli/v3/internal/errors"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	"github.com/buildkite/cli/v3/pkg/output"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	tea "github.com/charmbracelet/bubbletea"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/mattn/go-isatty"
+)
+
+// commitBuildsPageSize is the page size used when listing the builds for a
+// commit. A single commit rarely has more than a handful of builds, so one
+// page almost always suffices.
+const commitBuildsPageSize = 100
+
+type StatusCmd struct {
+	Commit   string        `arg:"" optional:"" help:"Commit SHA or git revision to check. Defaults to HEAD of the local repository."`
+	Watch    bool          `help:"Watch the builds until they all finish." default:"true" negatable:""`
+	Interval int           `help:"Polling interval in seconds" default:"2"`
+	Timeout  time.Duration `help:"Stop watching after this long (e.g. 30m) and exit with the timed out status. Waits indefinitely by default."`
+}
+
+func (c *StatusCmd) Help() string {
+	return `Show the status of every build for a commit across the organization's pipelines.
+
+The most recent build of the commit in each pipeline is watched concurrently
+in a single combined view until all of them finish. The exit status is 0 when
+every build passed, and otherwise follows bk build watch: 13 if any build
+failed, then 14 if any was canceled, 15 if any is blocked and 16 if --timeout
+elapsed first.
+
+Revisions such as HEAD~1 or a short SHA are resolved through the local
+repository when run inside one.
+
+Examples:
+  # Watch the builds for the current HEAD commit
+  $ bk commit status
+
+  # Watch the builds for a specific commit
+  $ bk commit status 4f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39
+
+  # Print the current state without waiting
+  $ bk commit status HEAD~1 --no-watch`
+}
+
+func (c *StatusCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	if c.Interval < 1 {
+		return bkErrors.NewValidationError(nil, "--interval must be at least 1 second")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sha, err := resolveCommit(f.GitRepository, c.Commit)
+	if err != nil {
+		return err
+	}
+
+	org := f.Config.OrganizationSlug()
+
+	var builds []buildkite.Build
+	if err := bkIO.SpinWhile(f, fmt.Sprintf("Finding builds for commit %s", shortSHA(sha)), func() error {
+		var apiErr error
+		builds, apiErr = findCommitBuilds(ctx, f.RestAPIClient, org, sha)
+		return apiErr
+	}); err != nil {
+		return err
+	}
+	if len(builds) == 0 {
+		return bkErrors.NewResourceNotFoundError(
+			nil,
+			fmt.Sprintf("no builds found for commit %s in %s", shortSHA(sha), org),
+			"Check that the commit has been pushed",
+			"Builds are matched on the full commit SHA",
+		)
+	}
+
+	if !c.Watch {
+		return printCommitStatus(os.Stdout, org, sha, builds)
+	}
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	interval := time.Duration(c.Interval) * time.Second
+	status := newCommitStatus(org, sha, builds)
+
+	tty := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+	if tty && !f.NoInput {
+		final, err := watchCommitInteractive(ctx, f.RestAPIClient, status, interval)
+		if err != nil {
+			return err
+		}
+		status = final
+	} else {
+		fmt.Printf("Watching %d builds for commit %s\n", len(builds), shortSHA(sha))
+		var mu sync.Mutex
+		watchCommitBuilds(ctx, f.RestAPIClient, status, interval, func(u commitBuildUpdate) {
+			mu.Lock()
+			defer mu.Unlock()
+			if status.apply(u) {
+				fmt.Printf("[%s] %s\n", time.Now().Format(time.RFC3339), status.builds[u.index].line())
+			}
+		})
+		fmt.Println()
+	}
+	fmt.Println(renderCommitStatusTable(status))
+
+	if err := status.err(); err != nil {
+		return err
+	}
+	if err := commitOutcome(status.finalBuilds()); err != nil {
+		return err
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return bkErrors.NewBuildTimedOutError(
+			nil,
+			fmt.Sprintf("builds for commit %s still running after %s", shortSHA(sha), c.Timeout),
+			"Increase --timeout, or keep waiting with 'bk commit status'",
+		)
+	}
+	return nil
+}
+
+// resolveCommit returns the full SHA to look up builds for. Revisions are
+// resolved through the local repository when there is one, so HEAD (the
+// default), HEAD~1 and short SHAs all work. Outside a repository the revision
+// is used as given and must be a full SHA.
+func resolveCommit(repo *git.Repository, revision string) (string, error) {
+	revision = strings.TrimSpace(revision)
+	if repo == nil {
+		if revision == "" || revision == "HEAD" {
+			return "", bkErrors.NewValidationError(
+				nil,
+				"not in a git repository",
+				"Pass a commit SHA, e.g. 'bk commit status <sha>'",
+			)
+		}
+		return revision, nil
+	}
+
+	if revision == "" {
+		revision = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		if plumbing.IsHash(revision) {
+			// A full SHA that isn't in the local clone can still have builds.
+			return revision, nil
+		}
+		return "", bkErrors.NewValidationError(err, fmt.Sprintf("could not resolve revision %q", revision))
+	}
+	return hash.String(), nil
+}
+
+// findCommitBuilds lists the builds for sha across the organization and keeps
+// the most recent build per pipeline, so a rebuild supersedes earlier
+// attempts. Builds are ordered by pipeline slug.
+func findCommitBuilds(ctx context.Context, client *buildkite.Client, org, sha string) ([]buildkite.Build, error) {
+	opts := &buildkite.BuildsListOptions{
+		Commit:      sha,
+		ExcludeJobs: true,
+		ListOptions: buildkite.ListOptions{PerPage: commitBuildsPageSize},
+	}
+
+	latest := make(map[string]buildkite.Build)
+	for {
+		builds, resp, err := client.Builds.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return nil, bkErrors.WrapAPIError(err, "listing builds for commit")
+		}
+		for _, b := range builds {
+			slug := buildPipelineSlug(b)
+			if existing, ok := latest[slug]; !ok || b.Number > existing.Number {
+				latest[slug] = b
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	result := make([]buildkite.Build, 0, len(latest))
+	for _, b := range latest {
+		result = append(result, b)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return buildPipelineSlug(result[i]) < buildPipelineSlug(result[j])
+	})
+	return result, nil
+}
+
+func buildPipelineSlug(b buildkite.Build) string {
+	if b.Pipeline == nil {
+		return ""
+	}
+	return b.Pipeline.Slug
+}
+
+// commitBuildUpdate reports the latest state of one of the watched builds.
+type commitBuildUpdate struct {
+	index   int
+	build   buildkite.Build
+	summary watch.JobSummary
+	done    bool
+	err     error
+}
+
+// watchCommitBuilds watches every build in status concurrently until they
+// have all finished or ctx is done, calling report after each poll and once
+// more when a build's watch ends. report may be called from several
+// goroutines at once.
+func watchCommitBuilds(ctx context.Context, client *buildkite.Client, status commitStatus, interval time.Duration, report func(commitBuildUpdate)) {
+	var wg sync.WaitGroup
+	for i, cb := range status.builds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			tracker := watch.NewJobTracker()
+			var summary watch.JobSummary
+			b, err := watch.WatchBuild(ctx, client, status.organization, cb.pipeline, cb.build.Number, interval, func(b buildkite.Build) error {
+				summary = tracker.Update(b).Summary
+				report(commitBuildUpdate{index: i, build: b, summary: summary})
+				return nil
+			})
+			if b.Number == 0 {
+				b = cb.build
+			}
+			report(commitBuildUpdate{index: i, build: b, summary: summary, done: true, err: err})
+		}()
+	}
+	wg.Wait()
+}
+
+// watchCommitInteractive shows the combined live view until every build has
+// finished or the user quits, returning the final status.
+func watchCommitInteractive(ctx context.Context, client *buildkite.Client, status commitStatus, interval time.Duration) (commitStatus, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	program := tea.NewProgram(newCommitStatusModel(status, cancel))
+	go func() {
+		watchCommitBuilds(ctx, client, status, interval, func(u commitBuildUpdate) {
+			program.Send(u)
+		})
+		program.Send(commitWatchDoneMsg{})
+	}()
+
+	final, err := program.Run()
+	if err != nil {
+		return status, err
+	}
+	return final.(commitStatusModel).status, nil
+}
+
+// printCommitStatus prints the current state of the builds for a commit
+// without watching them, for --no-watch, and returns their combined outcome.
+func printCommitStatus(w io.Writer, org, sha string, builds []buildkite.Build) error {
+	fmt.Fprintln(w, renderCommitStatusTable(newCommitStatus(org, sha, builds)))
+	return commitOutcome(builds)
+}
+
+// commitOutcome combines the outcomes of the finished builds for a commit
+// into a single error, reporting the most severe category: failed, then
+// canceled, then blocked. Failing builds count as failed, as they cannot
+// pass; other builds still in progress are ignored.
+func commitOutcome(builds []buildkite.Build) error {
+	categories := []struct {
+		is     func(error) bool
+		newErr func(error, string, ...string) error
+		verb   string
+	}{
+		{bkErrors.IsBuildFailed, bkErrors.NewBuildFailedError, "failed"},
+		{bkErrors.IsBuildCanceled, bkErrors.NewBuildCanceledError, "canceled"},
+		{bkErrors.IsBuildBlocked, bkErrors.NewBuildBlockedError, "blocked"},
+	}
+
+	var outcomes []error
+	for _, b := range builds {
+		switch state := buildstate.State(b.State); {
+		case state == buildstate.Failing:
+			outcomes = append(outcomes, bkErrors.NewBuildFailedError(nil, fmt.Sprintf("build #%d is %s", b.Number, b.State)))
+		case buildstate.IsIncomplete(state) && state != buildstate.Blocked:
+			outcomes = append(outcomes, nil)
+		default:
+			outcomes = append(outcomes, watch.OutcomeError(b))
+		}
+	}
+
+	for _, category := range categories {
+		var matched []string
+		var urls []string
+		for i, err := range outcomes {
+			if err != nil && category.is(err) {
+				b := builds[i]
+				matched = append(matched, fmt.Sprintf("%s #%d", buildPipelineSlug(b), b.Number))
+				if b.WebURL != "" {
+					urls = append(urls, b.WebURL)
+				}
+			}
+		}
+		if len(matched) > 0 {
+			return category.newErr(
+				nil,
+				fmt.Sprintf("%d of %d builds %s: %s", len(matched), len(builds), category.verb, strings.Join(matched, ", ")),
+				urls...,
+			)
+		}
+	}
+	return nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 10 {
+		return sha[:10]
+	}
+	return sha
+}
+
+// commitStatus is the combined state of the builds for a commit.
+type commitStatus struct {
+	organization string
+	commit       string
+	builds       []commitBuild
+}
+
+// commitBuild is the latest known state of one build for the commit.
+type commitBuild struct {
+	pipeline string
+	build    buildkite.Build
+	summary  watch.JobSummary
+	polled   bool
+	done     bool
+	err      error
+}
+
+func newCommitStatus(org, sha string, builds []buildkite.Build) commitStatus {
+	status := commitStatus{organization: org, commit: sha}
+	for _, b := range builds {
+		status.builds = append(status.builds, commitBuild{pipeline: buildPipelineSlug(b), build: b})
+	}
+	return status
+}
+
+// apply records an update and reports whether the build's state or job
+// counts changed.
+func (s *commitStatus) apply(u commitBuildUpdate) bool {
+	cb := &s.builds[u.index]
+	changed := !cb.polled || cb.build.State != u.build.State || cb.summary != u.summary
+	cb.build = u.build
+	cb.summary = u.summary
+	cb.polled = true
+	if u.done {
+		cb.done = true
+		if u.err != nil && !errors.Is(u.err, context.Canceled) && !errors.Is(u.err, context.DeadlineExceeded) {
+			cb.err = u.err
+			changed = true
+		}
+	}
+	return changed
+}
+
+// counts returns the number of builds that passed, failed and are still in
+// progress.
+func (s commitStatus) counts() (passed, failed, running int) {
+	for _, cb := range s.builds {
+		switch buildstate.State(cb.build.State) {
+		case buildstate.Passed:
+			passed++
+		case buildstate.Failed, buildstate.Failing:
+			failed++
+		default:
+			if !buildstate.IsTerminal(buildstate.State(cb.build.State)) {
+				running++
+			}
+		}
+	}
+	return passed, failed, running
+}
+
+// err returns the first error that stopped a build from being watched.
+func (s commitStatus) err() error {
+	for _, cb := range s.builds {
+		if cb.err != nil {
+			return fmt.Errorf("watching %s #%d: %w", cb.pipeline, cb.build.Number, cb.err)
+		}
+	}
+	return nil
+}
+
+func (s commitStatus) finalBuilds() []buildkite.Build {
+	builds := make([]buildkite.Build, 0, len(s.builds))
+	for _, cb := range s.builds {
+		builds = append(builds, cb.build)
+	}
+	return builds
+}
+
+// line renders a one-line description of the build for plain output.
+func (cb commitBuild) line() string {
+	line := fmt.Sprintf("%s #%d %s", cb.pipeline, cb.build.Number, cb.build.State)
+	if summary := cb.summary.String(); summary != "" {
+		line += " (" + summary + ")"
+	}
+	return line
+}
+
+func renderCommitStatusTable(s commitStatus) string {
+	rows := make([][]string, 0, len(s.builds))
+	for _, cb := range s.builds {
+		rows = append(rows, []string{
+			cb.pipeline,
+			fmt.Sprint(cb.build.Number),
+			cb.build.State,
+			output.ValueOrDash(cb.summary.String()),
+			output.ValueOrDash(cb.build.WebURL),
+		})
+	}
+	passed, failed, running := s.counts()
+	table := output.Table(
+		[]string{"Pipeline", "Build", "State", "Jobs", "URL"},
+		rows,
+		map[string]string{"pipeline": "bold", "state": "bold", "jobs": "dim", "url": "dim"},
+	)
+	return fmt.Sprintf("Commit %s: %d builds (%d passed, %d failed, %d running)\n\n%s",
+		shortSHA(s.commit), len(s.builds), passed, failed, running, table)
+}
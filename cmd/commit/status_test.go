@@ -0,0 +1,193 @@
+package commit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/build/watch"
+	bkErrors "github.com/buildkite/cli/v3/internal/errors"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	tea "github.com/charmbracelet/bubbletea"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func commitFile(t *testing.T, repo *git.Repository, dir, name string) string {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add(name); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := wt.Commit("add "+name, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.String()
+}
+
+func TestResolveCommit(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("PlainInit returned error: %v", err)
+	}
+	first := commitFile(t, repo, dir, "a.txt")
+	head := commitFile(t, repo, dir, "b.txt")
+
+	tests := []struct {
+		name     string
+		revision string
+		want     string
+	}{
+		{name: "defaults to HEAD", revision: "", want: head},
+		{name: "relative revision", revision: "HEAD~1", want: first},
+		{name: "full SHA", revision: first, want: first},
+		{name: "unknown full SHA is used as given", revision: strings.Repeat("a", 40), want: strings.Repeat("a", 40)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveCommit(repo, tt.revision)
+			if err != nil {
+				t.Fatalf("resolveCommit(%q) error = %v", tt.revision, err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveCommit(%q) = %q, want %q", tt.revision, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := resolveCommit(repo, "no-such-branch"); !bkErrors.IsValidationError(err) {
+		t.Errorf("expected validation error for unknown revision, got %v", err)
+	}
+	if _, err := resolveCommit(nil, ""); !bkErrors.IsValidationError(err) {
+		t.Errorf("expected validation error outside a repository, got %v", err)
+	}
+}
+
+func TestFindCommitBuilds(t *testing.T) {
+	sha := strings.Repeat("c", 40)
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/organizations/acme/builds" {
+			http.NotFound(w, r)
+			return
+		}
+		gotQuery = r.URL.RawQuery
+		_ = json.NewEncoder(w).Encode([]buildkite.Build{
+			{Number: 7, State: "failed", Commit: sha, Pipeline: &buildkite.Pipeline{Slug: "web"}},
+			{Number: 3, State: "running", Commit: sha, Pipeline: &buildkite.Pipeline{Slug: "api"}},
+			{Number: 8, State: "running", Commit: sha, Pipeline: &buildkite.Pipeline{Slug: "web"}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := buildkite.NewOpts(buildkite.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builds, err := findCommitBuilds(t.Context(), client, "acme", sha)
+	if err != nil {
+		t.Fatalf("findCommitBuilds error = %v", err)
+	}
+	if !strings.Contains(gotQuery, "commit="+sha) {
+		t.Errorf("expected commit filter in query, got %q", gotQuery)
+	}
+	if len(builds) != 2 {
+		t.Fatalf("expected one build per pipeline, got %+v", builds)
+	}
+	if builds[0].Pipeline.Slug != "api" || builds[1].Pipeline.Slug != "web" || builds[1].Number != 8 {
+		t.Errorf("expected latest build per pipeline ordered by slug, got api #%d, %s #%d", builds[0].Number, builds[1].Pipeline.Slug, builds[1].Number)
+	}
+}
+
+func TestCommitOutcome(t *testing.T) {
+	build := func(slug string, number int, state string) buildkite.Build {
+		return buildkite.Build{Number: number, State: state, Pipeline: &buildkite.Pipeline{Slug: slug}}
+	}
+
+	if err := commitOutcome([]buildkite.Build{build("api", 1, "passed"), build("web", 2, "running")}); err != nil {
+		t.Errorf("expected builds in progress to be ignored, got %v", err)
+	}
+
+	err := commitOutcome([]buildkite.Build{
+		build("api", 1, "canceled"),
+		build("docs", 4, "passed"),
+		build("web", 2, "failed"),
+	})
+	if bkErrors.GetExitCodeForError(err) != bkErrors.ExitCodeBuildFailed {
+		t.Fatalf("expected failure to take precedence, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "1 of 3 builds failed: web #2") {
+		t.Errorf("unexpected error message: %v", err)
+	}
+
+	err = commitOutcome([]buildkite.Build{build("api", 1, "blocked"), build("web", 2, "passed")})
+	if !bkErrors.IsBuildBlocked(err) {
+		t.Errorf("expected blocked build error, got %v", err)
+	}
+}
+
+func TestPrintCommitStatusFailing(t *testing.T) {
+	builds := []buildkite.Build{
+		{Number: 1, State: "passed", Pipeline: &buildkite.Pipeline{Slug: "api"}},
+		{Number: 2, State: "failing", Pipeline: &buildkite.Pipeline{Slug: "web"}},
+		{Number: 3, State: "running", Pipeline: &buildkite.Pipeline{Slug: "docs"}},
+	}
+
+	var out strings.Builder
+	err := printCommitStatus(&out, "acme", strings.Repeat("e", 40), builds)
+	if bkErrors.GetExitCodeForError(err) != bkErrors.ExitCodeBuildFailed {
+		t.Fatalf("expected a failing build to fail --no-watch, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "1 of 3 builds failed: web #2") {
+		t.Errorf("unexpected error message: %v", err)
+	}
+	if !strings.Contains(out.String(), "1 passed, 1 failed, 1 running") {
+		t.Errorf("expected the status table to be printed, got:\n%s", out.String())
+	}
+}
+
+func TestCommitStatusModel(t *testing.T) {
+	status := newCommitStatus("acme", strings.Repeat("d", 40), []buildkite.Build{
+		{Number: 1, State: "scheduled", Pipeline: &buildkite.Pipeline{Slug: "api"}},
+		{Number: 2, State: "scheduled", Pipeline: &buildkite.Pipeline{Slug: "web"}},
+	})
+
+	var m tea.Model = newCommitStatusModel(status, nil)
+	m, _ = m.Update(commitBuildUpdate{index: 0, build: buildkite.Build{Number: 1, State: "passed"}, summary: watch.JobSummary{Passed: 3}, done: true})
+	m, _ = m.Update(commitBuildUpdate{index: 1, build: buildkite.Build{Number: 2, State: "failing"}, summary: watch.JobSummary{Failed: 1, Running: 2}})
+
+	view := m.View()
+	for _, want := range []string{"Watching 2 builds for commit dddddddddd", "1 failed", "1 passed, 0 running", "api #1", "3 passed", "web #2", "failing", "1 failed, 2 running"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+
+	m, cmd := m.Update(commitWatchDoneMsg{})
+	if cmd == nil {
+		t.Fatal("expected quit command once all builds finish")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("expected tea.QuitMsg")
+	}
+	if got := m.(commitStatusModel).status.finalBuilds()[1].State; got != "failing" {
+		t.Errorf("final state = %q, want failing", got)
+	}
+}
@@ -0,0 +1,145 @@
+package commit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	buildstate "github.com/buildkite/cli/v3/internal/build/state"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	statusDimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	statusTitleStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFBA03")).Bold(true)
+	statusBorderStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+	statusFailureStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	statusPassedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+)
+
+// commitWatchDoneMsg is sent once every build's watch has ended.
+type commitWatchDoneMsg struct{}
+
+// commitStatusModel is the combined live view of all the builds for a commit.
+type commitStatusModel struct {
+	status     commitStatus
+	spinner    spinner.Model
+	cancelFunc context.CancelFunc
+	finished   bool
+}
+
+func newCommitStatusModel(status commitStatus, cancel context.CancelFunc) commitStatusModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#DE8F0C"))
+	// The watchers read the initial builds while the model records updates,
+	// so the model keeps its own copy.
+	status.builds = slices.Clone(status.builds)
+	return commitStatusModel{status: status, spinner: s, cancelFunc: cancel}
+}
+
+func (m commitStatusModel) Init() tea.Cmd {
+	return m.spinner.Tick
+}
+
+func (m commitStatusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			if m.cancelFunc != nil {
+				m.cancelFunc()
+			}
+			return m, tea.Quit
+		}
+
+	case commitBuildUpdate:
+		m.status.apply(msg)
+		return m, nil
+
+	case commitWatchDoneMsg:
+		m.finished = true
+		return m, tea.Quit
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m commitStatusModel) View() string {
+	if m.finished {
+		return ""
+	}
+
+	var sb strings.Builder
+	separator := statusBorderStyle.Render(strings.Repeat("─", 45))
+	passed, failed, running := m.status.counts()
+
+	sb.WriteString(separator + "\n")
+	fmt.Fprintf(&sb, "  %s %s\n", m.spinner.View(), statusTitleStyle.Render(fmt.Sprintf("Watching %d builds for commit %s", len(m.status.builds), shortSHA(m.status.commit))))
+	counts := fmt.Sprintf("%d passed, %d running", passed, running)
+	if failed > 0 {
+		counts = statusFailureStyle.Render(fmt.Sprintf("%d failed", failed)) + statusDimStyle.Render(", "+counts)
+	} else {
+		counts = statusDimStyle.Render(counts)
+	}
+	sb.WriteString("  " + counts + "\n")
+	sb.WriteString(separator + "\n")
+
+	width := 0
+	for _, cb := range m.status.builds {
+		width = max(width, len(fmt.Sprintf("%s #%d", cb.pipeline, cb.build.Number)))
+	}
+	for _, cb := range m.status.builds {
+		name := fmt.Sprintf("%s #%d", cb.pipeline, cb.build.Number)
+		line := fmt.Sprintf("  %s %-*s  %-9s", buildStateIcon(cb.build.State), width, name, cb.build.State)
+		if summary := cb.summary.String(); summary != "" {
+			line += "  " + statusDimStyle.Render(summary)
+		}
+		if d := buildDuration(cb.build); d > 0 {
+			line += "  " + statusDimStyle.Render(d.Round(time.Second).String())
+		}
+		if cb.err != nil {
+			line += "  " + statusFailureStyle.Render(cb.err.Error())
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	sb.WriteString("\n" + statusDimStyle.Render("  q quit"))
+	return sb.String()
+}
+
+func buildStateIcon(state string) string {
+	switch buildstate.State(state) {
+	case buildstate.Passed:
+		return statusPassedStyle.Render("✔")
+	case buildstate.Failed, buildstate.Failing:
+		return statusFailureStyle.Render("✗")
+	case buildstate.Running, buildstate.Canceling:
+		return statusTitleStyle.Render("●")
+	case buildstate.Blocked:
+		return statusTitleStyle.Render("◆")
+	case buildstate.Canceled, buildstate.Skipped, buildstate.NotRun:
+		return statusDimStyle.Render("-")
+	default:
+		return statusDimStyle.Render("○")
+	}
+}
+
+func buildDuration(b buildkite.Build) time.Duration {
+	if b.StartedAt == nil {
+		return 0
+	}
+	if b.FinishedAt != nil {
+		return b.FinishedAt.Sub(b.StartedAt.Time)
+	}
+	return time.Since(b.StartedAt.Time)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/Khan/genqlient/graphql"
 	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/build"
 	"github.com/buildkite/cli/v3/internal/cli"
 	bkGraphQL "github.com/buildkite/cli/v3/internal/graphql"
 	bkIO "github.com/buildkite/cli/v3/internal/io"
@@ -115,6 +116,9 @@ type jobListOptions struct {
 	orderBy  string
 	limit    int
 	noLimit  bool
+	// branch and maxBuilds are only set by bk job stats.
+	branch    []string
+	maxBuilds int
 }
 
 func (opts jobListOptions) withoutQueue() jobListOptions {
@@ -320,6 +324,8 @@ func fetchJobs(ctx context.Context, f *factory.Factory, org string, opts jobList
 	if opts.noLimit {
 		// When --no-limit is set, fetch all available builds (no upper bound)
 		maxBuildsToFetch = 0 // 0 means unlimited
+	} else if opts.maxBuilds > 0 {
+		maxBuildsToFetch = opts.maxBuilds
 	} else {
 		// By default, fetch a reasonable number of builds (200 = 2 pages)
 		// This provides a good pool for filtering without being tied to --limit
@@ -335,6 +341,8 @@ func fetchJobs(ctx context.Context, f *factory.Factory, org string, opts jobList
 		maxPages = (maxBuildsToFetch + pageSize - 1) / pageSize
 	}
 
+	branches := build.BranchPattern(opts.branch)
+
 	for page := 1; ; page++ {
 		// Check page limit if set
 		if maxPages > 0 && page > maxPages {
@@ -363,6 +371,9 @@ func fetchJobs(ctx context.Context, f *factory.Factory, org string, opts jobList
 		buildsFetched += len(builds)
 
 		for _, build := range builds {
+			if branches != nil && !branches.MatchString(build.Branch) {
+				continue
+			}
 			if len(allJobs)+len(build.Jobs) > cap(allJobs) {
 				newJobs := make([]buildkite.Job, len(allJobs), len(allJobs)+len(build.Jobs)+100)
 				copy(newJobs, allJobs)
@@ -836,6 +847,10 @@ func jobListOptionsFromFlags(opts *jobListOptions) (*buildkite.BuildsListOptions
 		}
 		listOpts.CreatedTo = now.Add(-d)
 	}
+	// Wildcard branches are matched by fetchJobs instead.
+	if !build.HasBranchWildcard(opts.branch) {
+		listOpts.Branch = opts.branch
+	}
 
 	return listOpts, nil
 }
@@ -357,6 +357,64 @@ func TestFetchJobListWithoutBuildRetainsCrossBuildEndpoint(t *testing.T) {
 	}
 }
 
+func TestFetchJobsFiltersBranchAndCapsBuilds(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got := r.URL.Query()["branch[]"]; len(got) != 1 || got[0] != "main" {
+			t.Fatalf("branch[] = %v, want main", got)
+		}
+		builds := make([]buildkite.Build, pageSize)
+		for i := range builds {
+			builds[i].Jobs = []buildkite.Job{{ID: fmt.Sprint(i)}}
+		}
+		_ = json.NewEncoder(w).Encode(builds)
+	}))
+	t.Cleanup(server.Close)
+
+	f := newJobListTestFactory(t, server.URL, nil)
+	opts := jobListOptions{branch: []string{"main"}, maxBuilds: 3 * pageSize}
+	listOpts, err := jobListOptionsFromFlags(&opts)
+	if err != nil {
+		t.Fatalf("jobListOptionsFromFlags() error = %v", err)
+	}
+	jobs, err := fetchJobs(context.Background(), f, "test-org", opts, listOpts)
+	if err != nil {
+		t.Fatalf("fetchJobs() error = %v", err)
+	}
+	if requests != 3 || len(jobs) != 3*pageSize {
+		t.Fatalf("fetched %d jobs in %d requests, want %d in 3", len(jobs), requests, 3*pageSize)
+	}
+}
+
+func TestFetchJobsMatchesBranchWildcards(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query()["branch[]"]; len(got) != 0 {
+			t.Errorf("branch[] = %v, want wildcards matched client-side", got)
+		}
+		_ = json.NewEncoder(w).Encode([]buildkite.Build{
+			{Branch: "main", Jobs: []buildkite.Job{{ID: "main-job"}}},
+			{Branch: "feature/login", Jobs: []buildkite.Job{{ID: "login-job"}}},
+			{Branch: "fix-1", Jobs: []buildkite.Job{{ID: "fix-job"}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	f := newJobListTestFactory(t, server.URL, nil)
+	opts := jobListOptions{branch: []string{"main", "feature/*"}, maxBuilds: pageSize}
+	listOpts, err := jobListOptionsFromFlags(&opts)
+	if err != nil {
+		t.Fatalf("jobListOptionsFromFlags() error = %v", err)
+	}
+	jobs, err := fetchJobs(context.Background(), f, "test-org", opts, listOpts)
+	if err != nil {
+		t.Fatalf("fetchJobs() error = %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != "main-job" || jobs[1].ID != "login-job" {
+		t.Fatalf("jobs = %#v, want those of main and feature/login", jobs)
+	}
+}
+
 func TestJobListOptionsRejectBuildWithBuildTimeFilters(t *testing.T) {
 	tests := []jobListOptions{
 		{build: "429", since: "1h"},
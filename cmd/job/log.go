@@ -2,26 +2,49 @@ package job
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"regexp"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/buildkite/cli/v3/internal/cli"
 	bkIO "github.com/buildkite/cli/v3/internal/io"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	"github.com/buildkite/cli/v3/internal/job/classify"
 	"github.com/buildkite/cli/v3/pkg/cmd/factory"
 	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
 	"github.com/mcncl/terminal-to-llm/digest"
 )
 
+// logFollowMaxConsecutiveErrors is the number of consecutive polling failures
+// before --follow gives up.
+const logFollowMaxConsecutiveErrors = 10
+
 type LogCmd struct {
-	JobID        string `arg:"" help:"Job UUID to get logs for"`
-	Pipeline     string `help:"Deprecated; ignored because job UUIDs no longer require pipeline or build context" short:"p"`
-	BuildNumber  string `help:"Deprecated; ignored because job UUIDs no longer require pipeline or build context" short:"b"`
-	NoTimestamps bool   `help:"Strip timestamp prefixes from log output" name:"no-timestamps"`
-	LLMOptimized bool   `help:"Format output to be optimal for LLM consumption (strips ANSI, deduplicates loops)" name:"agent" aliases:"llm"`
-	Format       string `help:"Output rendering for --agent: plain or Markdown" name:"format" enum:"plain,markdown" default:"plain"`
-	MaxTokens    int    `help:"Hard ceiling on the estimated token count of --agent output (0 = unlimited)" name:"max-tokens"`
-	NoWindow     bool   `help:"Disable failure-focused windowing in --agent output (keep all lines)" name:"no-window"`
+	JobID        string   `arg:"" help:"Job UUID to get logs for"`
+	Pipeline     string   `help:"Deprecated; ignored because job UUIDs no longer require pipeline or build context" short:"p"`
+	BuildNumber  string   `help:"Deprecated; ignored because job UUIDs no longer require pipeline or build context" short:"b"`
+	NoTimestamps bool     `help:"Strip timestamp prefixes from log output" name:"no-timestamps"`
+	LLMOptimized bool     `help:"Format output to be optimal for LLM consumption (strips ANSI, deduplicates loops)" name:"agent" aliases:"llm" xor:"mode"`
+	Format       string   `help:"Output rendering: plain or markdown for --agent, or html for a standalone page with collapsible sections" name:"format" enum:"plain,markdown,html" default:"plain"`
+	MaxTokens    int      `help:"Hard ceiling on the estimated token count of --agent output (0 = unlimited)" name:"max-tokens"`
+	NoWindow     bool     `help:"Disable failure-focused windowing in --agent output (keep all lines)" name:"no-window"`
+	Follow       bool     `help:"Keep polling and print new log output until the job finishes" short:"f" xor:"mode"`
+	Interval     int      `help:"Polling interval in seconds for --follow" default:"2"`
+	SinceSection bool     `help:"Start output at the most recent --- or +++ section header" name:"since-section"`
+	Sections     bool     `help:"List the log's sections instead of printing the log" xor:"mode"`
+	Section      string   `help:"Print only the section with this name, or number from --sections" xor:"mode"`
+	Interactive  bool     `help:"Browse the log in a viewer where sections can be expanded and collapsed" short:"i" xor:"mode"`
+	Classify     bool     `help:"Label the likely cause of the job's failure, with the log lines that point to it" xor:"mode"`
+	Redact       bool     `help:"Mask credentials such as AWS keys, GitHub and Buildkite tokens, JWTs and private keys, plus redact_patterns from config"`
+	Rules        []string `help:"Extra ruleset files for --classify, on top of the built-in rules and the repository's .buildkite/classify.yaml"`
 }
 
 func (c *LogCmd) Help() string {
@@ -33,6 +56,30 @@ Examples:
   # Strip timestamp prefixes from output
   $ bk job log 0190046e-e199-453b-a302-a21a4d649d31 --no-timestamps
 
+  # Stream a running job's log until it finishes, like tail -f
+  $ bk job log 0190046e-e199-453b-a302-a21a4d649d31 --follow
+
+  # Show only the most recent section, then keep following it
+  $ bk job log 0190046e-e199-453b-a302-a21a4d649d31 -f --since-section --no-timestamps
+
+  # List the log's sections, then print one of them by name or number
+  $ bk job log 0190046e-e199-453b-a302-a21a4d649d31 --sections
+  $ bk job log 0190046e-e199-453b-a302-a21a4d649d31 --section "Running tests"
+  $ bk job log 0190046e-e199-453b-a302-a21a4d649d31 --section 3
+
+  # Browse the log with collapsible sections: enter toggles a section, + jumps
+  # to the first expanded section and ! to the first failing one
+  $ bk job log 0190046e-e199-453b-a302-a21a4d649d31 --interactive
+
+  # Label the likely cause of a failure (OOM, timeout, test failure, ...)
+  $ bk job log 0190046e-e199-453b-a302-a21a4d649d31 --classify
+
+  # Mask credentials before pasting the log elsewhere
+  $ bk job log 0190046e-e199-453b-a302-a21a4d649d31 --agent --redact
+
+  # Save the log as an HTML page with colours and collapsible sections
+  $ bk job log 0190046e-e199-453b-a302-a21a4d649d31 --format html > job.html
+
   # Format for LLM consumption
   $ bk job log 0190046e-e199-453b-a302-a21a4d649d31 --agent
 
@@ -61,10 +108,43 @@ func (c *LogCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 	}
 	warnIgnoredJobContextFlags(kongCtx.Stderr, c.Pipeline, c.BuildNumber)
 
+	if c.Format == "html" && (c.LLMOptimized || c.Follow || c.Sections || c.Section != "" || c.Interactive || c.Classify) {
+		return fmt.Errorf("--format html can't be combined with --agent, --follow, --sections, --section, --interactive or --classify")
+	}
+
+	if c.Follow && c.Interval < 1 {
+		return fmt.Errorf("--interval must be at least 1 second (requested: %d)", c.Interval)
+	}
+
+	var redactor *internaljob.Redactor
+	if c.Redact {
+		if redactor, err = internaljob.NewRedactor(f.Config.RedactPatterns()); err != nil {
+			return err
+		}
+	}
+
+	if c.Follow {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err := c.followLog(ctx, f.RestAPIClient, organization, redactor, os.Stdout)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+
 	ctx := context.Background()
 
 	var logContent string
+	var job buildkite.Job
 	if err = bkIO.SpinWhile(f, "Fetching job log", func() error {
+		if c.Classify || c.Format == "html" {
+			var apiErr error
+			if job, apiErr = getJob(ctx, f.RestAPIClient, organization, c.JobID); apiErr != nil {
+				return apiErr
+			}
+		}
 		jobLog, apiErr := getJobLog(
 			ctx,
 			f.RestAPIClient,
@@ -80,8 +160,47 @@ func (c *LogCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 		return err
 	}
 
+	if redactor != nil {
+		logContent = redactor.Redact(logContent)
+	}
+
+	switch {
+	case c.Sections:
+		return writeLogSections(os.Stdout, internaljob.ParseLogSections(logContent))
+	case c.Section != "":
+		section, err := findLogSection(internaljob.ParseLogSections(logContent), c.Section)
+		if err != nil {
+			return err
+		}
+		writer, cleanup := bkIO.Pager(f.NoPager)
+		defer func() { _ = cleanup() }()
+		return writeLogSection(writer, section)
+	case c.Interactive:
+		if !isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd()) {
+			return fmt.Errorf("--interactive requires a terminal")
+		}
+		model := newLogViewerModel(c.JobID, internaljob.ParseLogSections(logContent))
+		_, err := tea.NewProgram(model, tea.WithAltScreen()).Run()
+		return err
+	case c.Classify:
+		rules, err := classify.LoadForRepository(f.GitRepository, c.Rules...)
+		if err != nil {
+			return err
+		}
+		results := rules.ClassifyJob(job, internaljob.LogLineTexts(internaljob.SplitLogLines(logContent)))
+		return writeClassification(os.Stdout, job, results)
+	}
+
+	if c.SinceSection {
+		logContent = logContent[internaljob.LastSectionOffset(logContent):]
+	}
+
+	if c.Format == "html" {
+		return internaljob.WriteLogHTML(os.Stdout, jobLogHTMLDocument(job, logContent, !c.NoTimestamps))
+	}
+
 	if c.NoTimestamps {
-		logContent = stripTimestamps(logContent)
+		logContent = internaljob.StripTimestamps(logContent)
 	}
 
 	if c.LLMOptimized {
@@ -99,11 +218,106 @@ func (c *LogCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 	return nil
 }
 
-// timestampRegex matches Buildkite's inline timestamp markers, including the
-// optional APC introducer (`\x1b_`) so the whole sequence is removed rather than
-// leaving a dangling escape byte behind.
-var timestampRegex = regexp.MustCompile(`(?:\x1b_)?bk;t=\d+\x07`)
+// followLog polls the job and its log, writing new log output to w as it
+// appears, until the job reaches a terminal state. The job is fetched before
+// its log on each poll, so the final poll's log is complete. Secrets are
+// masked when redactor is non-nil.
+func (c *LogCmd) followLog(ctx context.Context, client *buildkite.Client, organization string, redactor *internaljob.Redactor, w io.Writer) error {
+	var tail *internaljob.LogTail
+	var redact *internaljob.RedactorStream
+	if redactor != nil {
+		redact = redactor.Stream()
+	}
+	consecutiveErrors := 0
+
+	for {
+		job, err := getJob(ctx, client, organization, c.JobID)
+		var jobLog buildkite.JobLog
+		if err == nil {
+			jobLog, err = getJobLog(ctx, client, organization, c.JobID)
+		}
+
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil && tail == nil:
+			// Fail fast on the first poll, e.g. for an unknown job.
+			return err
+		case err != nil:
+			consecutiveErrors++
+			if consecutiveErrors >= logFollowMaxConsecutiveErrors {
+				return fmt.Errorf("following job log (%d consecutive errors): %w", consecutiveErrors, err)
+			}
+		default:
+			consecutiveErrors = 0
+			if tail == nil {
+				offset := 0
+				if c.SinceSection {
+					offset = internaljob.LastSectionOffset(jobLog.Content)
+				}
+				tail = internaljob.NewLogTail(offset)
+			}
+
+			finished := jobFinished(job)
+			if chunk := tail.Next(jobLog.Content, finished); chunk != "" {
+				if redact != nil {
+					chunk = redact.Redact(chunk)
+				}
+				if c.NoTimestamps {
+					chunk = internaljob.StripTimestamps(chunk)
+				}
+				if _, err := io.WriteString(w, chunk); err != nil {
+					return err
+				}
+			}
+			if finished {
+				return nil
+			}
+		}
 
-func stripTimestamps(content string) string {
-	return timestampRegex.ReplaceAllString(content, "")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(c.Interval) * time.Second):
+		}
+	}
+}
+
+// jobLogHTMLDocument returns a job's log as an HTML page.
+func jobLogHTMLDocument(j buildkite.Job, content string, timestamps bool) internaljob.HTMLDocument {
+	title := j.Label
+	if title == "" {
+		title = j.Name
+	}
+	if title == "" {
+		title = "Job " + j.ID
+	}
+	meta := j.State
+	if j.ExitStatus != nil {
+		meta = fmt.Sprintf("%s, exit status %d", meta, *j.ExitStatus)
+	}
+	return internaljob.HTMLDocument{
+		Title:      title,
+		Subtitle:   j.WebURL,
+		Timestamps: timestamps,
+		Logs: []internaljob.HTMLLog{{
+			Title:    title,
+			Meta:     meta,
+			Open:     true,
+			Sections: internaljob.ParseLogSections(content),
+		}},
+	}
+}
+
+// jobFinished reports whether a job has reached a terminal state, after which
+// its log no longer grows.
+func jobFinished(j buildkite.Job) bool {
+	if j.FinishedAt != nil {
+		return true
+	}
+	switch j.State {
+	case "passed", "failed", "canceled", "timed_out", "expired", "skipped", "broken", "finished", "not_run":
+		return true
+	}
+	return false
 }
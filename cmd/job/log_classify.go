@@ -0,0 +1,47 @@
+package job
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/buildkite/cli/v3/internal/job/classify"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+// writeClassification writes the likely cause of a job's failure, the
+// evidence for it, and any other categories that matched.
+func writeClassification(w io.Writer, j buildkite.Job, results []classify.Result) error {
+	var b strings.Builder
+
+	if len(results) == 0 {
+		if j.State == "passed" {
+			b.WriteString("The job passed; nothing to classify.\n")
+		} else {
+			b.WriteString("No rule matched this job's log. Add patterns for your toolchain to .buildkite/classify.yaml.\n")
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	top := results[0]
+	fmt.Fprintf(&b, "Likely cause: %s (score %d)\n\nEvidence:\n", top.Label, top.Score)
+	for _, e := range top.Evidence {
+		if e.Line > 0 {
+			fmt.Fprintf(&b, "  %6d  %s\n", e.Line, strings.TrimSpace(e.Text))
+		} else {
+			fmt.Fprintf(&b, "  %6s  %s\n", "-", e.Text)
+		}
+	}
+
+	if len(results) > 1 {
+		others := make([]string, len(results)-1)
+		for i, r := range results[1:] {
+			others[i] = fmt.Sprintf("%s (score %d)", r.Label, r.Score)
+		}
+		fmt.Fprintf(&b, "\nAlso matched: %s\n", strings.Join(others, ", "))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,40 @@
+package job
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/buildkite/cli/v3/internal/job/classify"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestWriteClassification(t *testing.T) {
+	t.Parallel()
+
+	exit := 137
+	job := buildkite.Job{State: "failed", ExitStatus: &exit}
+	results := classify.Default().ClassifyJob(job, []string{"building", "fatal error: runtime: out of memory"})
+
+	var b strings.Builder
+	if err := writeClassification(&b, job, results); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{"Likely cause: OOM-killed", "exit status 137", "     2  fatal error: runtime: out of memory"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteClassificationNoMatch(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	if err := writeClassification(&b, buildkite.Job{State: "failed"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "No rule matched") {
+		t.Errorf("unexpected output:\n%s", b.String())
+	}
+}
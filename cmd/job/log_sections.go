@@ -0,0 +1,113 @@
+package job
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/emoji"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	"github.com/buildkite/cli/v3/pkg/output"
+)
+
+// writeLogSections writes a table of a log's sections, numbered for use with
+// --section.
+func writeLogSections(w io.Writer, sections []internaljob.LogSection) error {
+	if len(sections) == 0 {
+		_, err := fmt.Fprintln(w, "No log output.")
+		return err
+	}
+
+	rows := make([][]string, 0, len(sections))
+	for i, s := range sections {
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			logSectionState(s),
+			emoji.Render(s.Title()),
+			strconv.Itoa(len(s.Lines)),
+			formatSectionDuration(s.Duration),
+		})
+	}
+
+	table := output.Table(
+		[]string{"#", "State", "Section", "Lines", "Duration"},
+		rows,
+		map[string]string{"#": "dim", "state": "bold", "section": "italic", "lines": "dim", "duration": "dim"},
+	)
+	_, err := fmt.Fprintln(w, table)
+	return err
+}
+
+func logSectionState(s internaljob.LogSection) string {
+	switch {
+	case s.Failed:
+		return "failed"
+	case s.Expanded:
+		return "expanded"
+	case s.Marker == "":
+		return "-"
+	default:
+		return "collapsed"
+	}
+}
+
+func formatSectionDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// findLogSection finds the section named by query: its number in the
+// --sections listing, its exact name, or a unique part of its name, ignoring
+// case.
+func findLogSection(sections []internaljob.LogSection, query string) (internaljob.LogSection, error) {
+	if n, err := strconv.Atoi(query); err == nil {
+		if n < 1 || n > len(sections) {
+			return internaljob.LogSection{}, fmt.Errorf("section %d does not exist; the log has %d sections (see --sections)", n, len(sections))
+		}
+		return sections[n-1], nil
+	}
+
+	q := strings.ToLower(strings.TrimSpace(query))
+	var partial []internaljob.LogSection
+	for _, s := range sections {
+		name := strings.ToLower(s.Name)
+		if name == q {
+			return s, nil
+		}
+		if strings.Contains(name, q) {
+			partial = append(partial, s)
+		}
+	}
+
+	switch len(partial) {
+	case 0:
+		return internaljob.LogSection{}, fmt.Errorf("no section matches %q (see --sections)", query)
+	case 1:
+		return partial[0], nil
+	default:
+		names := make([]string, len(partial))
+		for i, s := range partial {
+			names[i] = fmt.Sprintf("%q", s.Title())
+		}
+		return internaljob.LogSection{}, fmt.Errorf("%q matches %d sections: %s; use its number from --sections", query, len(partial), strings.Join(names, ", "))
+	}
+}
+
+// writeLogSection writes a section's header, if it has one, and its body.
+func writeLogSection(w io.Writer, s internaljob.LogSection) error {
+	if s.Marker != "" {
+		if _, err := fmt.Fprintf(w, "%s %s\n", s.Marker, s.Name); err != nil {
+			return err
+		}
+	}
+	for _, l := range s.Lines {
+		if _, err := fmt.Fprintln(w, l.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
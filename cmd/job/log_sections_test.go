@@ -0,0 +1,163 @@
+package job
+
+import (
+	"strings"
+	"testing"
+
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const sectionedLog = "preamble\n" +
+	"--- :git: Checkout\n" +
+	"cloning\n" +
+	"--- Install dependencies\n" +
+	"installing\n" +
+	"+++ Running tests\n" +
+	"ok\n" +
+	"--- Running lint\n" +
+	"FAIL\n" +
+	"^^^ +++\n" +
+	"~~~ Cleanup\n" +
+	"done\n"
+
+func TestFindLogSection(t *testing.T) {
+	t.Parallel()
+
+	sections := internaljob.ParseLogSections(sectionedLog)
+
+	tests := []struct {
+		query   string
+		want    string
+		wantErr string
+	}{
+		{query: "3", want: "Install dependencies"},
+		{query: "running tests", want: "Running tests"},
+		{query: "checkout", want: ":git: Checkout"},
+		{query: "Running", wantErr: "matches 2 sections"},
+		{query: "deploy", wantErr: "no section matches"},
+		{query: "9", wantErr: "section 9 does not exist"},
+	}
+	for _, tt := range tests {
+		got, err := findLogSection(sections, tt.query)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("findLogSection(%q) error = %v, want %q", tt.query, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("findLogSection(%q) returned error: %v", tt.query, err)
+			continue
+		}
+		if got.Name != tt.want {
+			t.Errorf("findLogSection(%q) = %q, want %q", tt.query, got.Name, tt.want)
+		}
+	}
+}
+
+func TestWriteLogSection(t *testing.T) {
+	t.Parallel()
+
+	section, err := findLogSection(internaljob.ParseLogSections(sectionedLog), "lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := writeLogSection(&b, section); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), "--- Running lint\nFAIL\n"; got != want {
+		t.Errorf("writeLogSection() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteLogSections(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	if err := writeLogSections(&b, internaljob.ParseLogSections(sectionedLog)); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{"(preamble)", "Install dependencies", "expanded", "collapsed", "failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected listing to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func pressKey(m logViewerModel, key string) logViewerModel {
+	var msg tea.KeyMsg
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	next, _ := m.Update(msg)
+	return next.(logViewerModel)
+}
+
+func TestLogViewerModel(t *testing.T) {
+	t.Parallel()
+
+	m := newLogViewerModel("job-1", internaljob.ParseLogSections(sectionedLog))
+
+	// The preamble, the +++ section and the failed section start expanded:
+	// preamble line, 5 headers, and one line each for the two open sections.
+	if len(m.rows) != 8 {
+		t.Fatalf("expected 8 rows, got %d", len(m.rows))
+	}
+
+	m = pressKey(m, "!")
+	if s := m.sections[m.currentSection()]; s.Name != "Running lint" || m.rows[m.cursor].line != -1 {
+		t.Errorf("expected ! to jump to the failing section header, got %q", s.Name)
+	}
+
+	m = pressKey(m, "+")
+	if s := m.sections[m.currentSection()]; s.Name != "Running tests" {
+		t.Errorf("expected + to jump to the first expanded section, got %q", s.Name)
+	}
+
+	m = pressKey(m, "enter")
+	if m.expanded[m.currentSection()] || len(m.rows) != 7 {
+		t.Errorf("expected enter to collapse the section, got %d rows", len(m.rows))
+	}
+
+	m = pressKey(m, "N")
+	if s := m.sections[m.currentSection()]; s.Name != "Install dependencies" {
+		t.Errorf("expected N to move to the previous section, got %q", s.Name)
+	}
+
+	m = pressKey(m, "enter")
+	if !m.expanded[m.currentSection()] || len(m.rows) != 8 {
+		t.Errorf("expected enter to expand the section, got %d rows", len(m.rows))
+	}
+
+	m = pressKey(m, "e")
+	if len(m.rows) != 11 {
+		t.Errorf("expected every section expanded, got %d rows", len(m.rows))
+	}
+
+	m = pressKey(m, "c")
+	if len(m.rows) != 6 {
+		t.Errorf("expected every section collapsed but the preamble, got %d rows", len(m.rows))
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "1 failing") || !strings.Contains(view, "Cleanup") {
+		t.Errorf("unexpected view:\n%s", view)
+	}
+}
+
+func TestLogViewerModelNoFailingSection(t *testing.T) {
+	t.Parallel()
+
+	m := newLogViewerModel("job-1", internaljob.ParseLogSections("--- Setup\nok\n"))
+	m = pressKey(m, "!")
+	if !strings.Contains(m.View(), "No failing sections") {
+		t.Errorf("expected a notice when there is no failing section, got:\n%s", m.View())
+	}
+}
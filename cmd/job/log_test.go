@@ -1,12 +1,78 @@
 package job
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
 
-func TestStripTimestamps(t *testing.T) {
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestFollowLogPrintsOnlyNewOutput(t *testing.T) {
+	t.Parallel()
+
+	// Each poll fetches the job, then its log; the job finishes on the third.
+	polls := []struct {
+		state string
+		log   string
+	}{
+		{"running", "\x1b_bk;t=1\x07--- Setup\nsetup\n\x1b_bk;t=2\x07+++ Tests\nrun"},
+		{"running", "\x1b_bk;t=1\x07--- Setup\nsetup\n\x1b_bk;t=2\x07+++ Tests\nrunning\n\x1b_bk;t=3\x07ok"},
+		{"passed", "\x1b_bk;t=1\x07--- Setup\nsetup\n\x1b_bk;t=2\x07+++ Tests\nrunning\n\x1b_bk;t=3\x07ok\ndone"},
+	}
+	poll := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/organizations/buildkite/jobs/job-1":
+			_ = json.NewEncoder(w).Encode(buildkite.Job{ID: "job-1", State: polls[poll].state})
+		case "/v2/organizations/buildkite/jobs/job-1/log":
+			_ = json.NewEncoder(w).Encode(buildkite.JobLog{Content: polls[poll].log})
+			poll++
+		default:
+			t.Errorf("unexpected request path %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := buildkite.NewOpts(buildkite.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	cmd := &LogCmd{JobID: "job-1", Interval: 1, NoTimestamps: true, SinceSection: true}
+	var out strings.Builder
+	if err := cmd.followLog(context.Background(), client, "buildkite", nil, &out); err != nil {
+		t.Fatalf("followLog() error = %v", err)
+	}
+
+	if want := "+++ Tests\nrunning\nok\ndone"; out.String() != want {
+		t.Errorf("followLog() output = %q, want %q", out.String(), want)
+	}
+	if poll != len(polls) {
+		t.Errorf("expected %d polls, got %d", len(polls), poll)
+	}
+}
+
+func TestFollowLogFailsFastOnFirstPoll(t *testing.T) {
 	t.Parallel()
 
-	in := "\x1b_bk;t=1700000000000\x07hello"
-	if got := stripTimestamps(in); got != "hello" {
-		t.Errorf("stripTimestamps(%q) = %q, want %q", in, got, "hello")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	client, err := buildkite.NewOpts(buildkite.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	cmd := &LogCmd{JobID: "missing", Interval: 1}
+	if err := cmd.followLog(context.Background(), client, "buildkite", nil, &strings.Builder{}); err == nil {
+		t.Fatal("expected an error for an unknown job")
 	}
 }
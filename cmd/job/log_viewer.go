@@ -0,0 +1,285 @@
+package job
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/buildkite/cli/v3/internal/emoji"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var (
+	logViewerTitleStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFBA03")).Bold(true)
+	logViewerDimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	logViewerHeaderStyle   = lipgloss.NewStyle().Bold(true)
+	logViewerFailedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
+	logViewerSelectedStyle = lipgloss.NewStyle().Reverse(true)
+	logViewerNoticeStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+)
+
+// logViewerRow is a visible row of the viewer: a section header, or a line of
+// an expanded section's body.
+type logViewerRow struct {
+	section int
+	line    int // -1 for the section header
+}
+
+// logViewerModel shows a job log as a list of sections that can be expanded
+// and collapsed, the way the Buildkite web UI does.
+type logViewerModel struct {
+	title    string
+	sections []internaljob.LogSection
+	expanded []bool
+	rows     []logViewerRow
+	cursor   int
+	offset   int
+	notice   string
+
+	width  int
+	height int
+}
+
+func newLogViewerModel(jobID string, sections []internaljob.LogSection) logViewerModel {
+	m := logViewerModel{
+		title:    "Job " + jobID,
+		sections: sections,
+		expanded: make([]bool, len(sections)),
+		width:    80,
+		height:   24,
+	}
+	for i, s := range sections {
+		// The preamble has no header to expand it with, so always show it.
+		m.expanded[i] = s.Expanded || s.Marker == ""
+	}
+	m.layout()
+	return m
+}
+
+func (m logViewerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m logViewerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+		return m, nil
+
+	case tea.KeyMsg:
+		m.notice = ""
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "up", "k":
+			m.move(-1)
+		case "down", "j":
+			m.move(1)
+		case "pgup", "b":
+			m.move(-m.bodyHeight())
+		case "pgdown", "f", " ":
+			m.move(m.bodyHeight())
+		case "g", "home":
+			m.move(-len(m.rows))
+		case "G", "end":
+			m.move(len(m.rows))
+		case "enter", "tab":
+			m.toggle(m.currentSection())
+		case "e":
+			m.setAll(true)
+		case "c":
+			m.setAll(false)
+		case "n":
+			m.jumpSection(1)
+		case "N":
+			m.jumpSection(-1)
+		case "+":
+			m.jumpTo(func(s internaljob.LogSection) bool { return s.Marker == internaljob.SectionExpanded }, "No expanded sections")
+		case "!":
+			m.jumpTo(func(s internaljob.LogSection) bool { return s.Failed }, "No failing sections")
+		}
+	}
+	return m, nil
+}
+
+// layout rebuilds the visible rows from the expanded state of each section.
+func (m *logViewerModel) layout() {
+	m.rows = nil
+	for i, s := range m.sections {
+		if s.Marker != "" {
+			m.rows = append(m.rows, logViewerRow{section: i, line: -1})
+		}
+		if m.expanded[i] {
+			for j := range s.Lines {
+				m.rows = append(m.rows, logViewerRow{section: i, line: j})
+			}
+		}
+	}
+	m.cursor = min(m.cursor, max(len(m.rows)-1, 0))
+}
+
+func (m *logViewerModel) move(delta int) {
+	m.cursor = min(max(m.cursor+delta, 0), max(len(m.rows)-1, 0))
+	m.scroll()
+}
+
+// scroll keeps the cursor within the visible part of the log.
+func (m *logViewerModel) scroll() {
+	height := m.bodyHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+	m.offset = max(min(m.offset, len(m.rows)-height), 0)
+}
+
+func (m logViewerModel) bodyHeight() int {
+	// Leave room for the title and the key help.
+	return max(m.height-2, 1)
+}
+
+func (m logViewerModel) currentSection() int {
+	if len(m.rows) == 0 {
+		return -1
+	}
+	return m.rows[m.cursor].section
+}
+
+// headerRow returns the row of a section's header, or of its first line for
+// the preamble.
+func (m logViewerModel) headerRow(section int) int {
+	for i, r := range m.rows {
+		if r.section == section {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m *logViewerModel) toggle(section int) {
+	if section < 0 || m.sections[section].Marker == "" {
+		return
+	}
+	m.expanded[section] = !m.expanded[section]
+	m.layout()
+	m.cursor = m.headerRow(section)
+	m.scroll()
+}
+
+func (m *logViewerModel) setAll(expanded bool) {
+	section := m.currentSection()
+	for i, s := range m.sections {
+		m.expanded[i] = expanded || s.Marker == ""
+	}
+	m.layout()
+	if section >= 0 {
+		m.cursor = m.headerRow(section)
+	}
+	m.scroll()
+}
+
+// jumpSection moves the cursor to the header of the next (or previous)
+// section.
+func (m *logViewerModel) jumpSection(dir int) {
+	section := m.currentSection()
+	if section < 0 {
+		return
+	}
+	if dir < 0 && m.rows[m.cursor].line >= 0 && m.sections[section].Marker != "" {
+		// Inside a section, N first goes back to its own header.
+		m.cursor = m.headerRow(section)
+		m.scroll()
+		return
+	}
+	next := section + dir
+	if next < 0 || next >= len(m.sections) {
+		return
+	}
+	m.cursor = m.headerRow(next)
+	m.scroll()
+}
+
+// jumpTo expands the first section matching pred and moves the cursor to its
+// header, showing notice when no section matches.
+func (m *logViewerModel) jumpTo(pred func(internaljob.LogSection) bool, notice string) {
+	for i, s := range m.sections {
+		if !pred(s) {
+			continue
+		}
+		m.expanded[i] = true
+		m.layout()
+		m.cursor = m.headerRow(i)
+		// Put the header at the top so as much of the section as possible
+		// is visible.
+		m.offset = m.cursor
+		m.scroll()
+		return
+	}
+	m.notice = notice
+}
+
+func (m logViewerModel) View() string {
+	var b strings.Builder
+
+	failed := 0
+	for _, s := range m.sections {
+		if s.Failed {
+			failed++
+		}
+	}
+	title := fmt.Sprintf("%s · %d sections", m.title, len(m.sections))
+	if failed > 0 {
+		title += fmt.Sprintf(" · %d failing", failed)
+	}
+	b.WriteString(logViewerTitleStyle.Render(ansi.Truncate(title, m.width, "…")))
+	b.WriteString("\n")
+
+	height := m.bodyHeight()
+	end := min(m.offset+height, len(m.rows))
+	for i := m.offset; i < end; i++ {
+		line := ansi.Truncate(m.renderRow(m.rows[i]), m.width, "…")
+		if i == m.cursor {
+			line = logViewerSelectedStyle.Render(ansi.Strip(line))
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	for i := end - m.offset; i < height; i++ {
+		b.WriteString("\n")
+	}
+
+	help := "↑/↓ move · enter toggle · e/c expand/collapse all · n/N next/prev · + first expanded · ! first failing · q quit"
+	if m.notice != "" {
+		b.WriteString(logViewerNoticeStyle.Render(ansi.Truncate(m.notice, m.width, "…")))
+	} else {
+		b.WriteString(logViewerDimStyle.Render(ansi.Truncate(help, m.width, "…")))
+	}
+	return b.String()
+}
+
+func (m logViewerModel) renderRow(r logViewerRow) string {
+	s := m.sections[r.section]
+	if r.line >= 0 {
+		return "  " + s.Lines[r.line].Text
+	}
+
+	icon := "▸"
+	if m.expanded[r.section] {
+		icon = "▾"
+	}
+	meta := fmt.Sprintf("%d lines", len(s.Lines))
+	if s.Duration > 0 {
+		meta += ", " + formatSectionDuration(s.Duration)
+	}
+	style := logViewerHeaderStyle
+	if s.Failed {
+		style = logViewerFailedStyle
+		meta += ", failed"
+	}
+	return style.Render(icon+" "+emoji.Render(s.Title())) + " " + logViewerDimStyle.Render("("+meta+")")
+}
@@ -2,28 +2,14 @@ package job
 
 import (
 	"context"
-	"fmt"
 	"net/http"
-	"net/url"
 
+	internaljob "github.com/buildkite/cli/v3/internal/job"
 	buildkite "github.com/buildkite/go-buildkite/v5"
 )
 
-type unblockJobOptions struct {
-	Fields map[string]any `json:"fields,omitempty"`
-}
-
-func organizationJobPath(organization, jobID, action string) string {
-	return fmt.Sprintf(
-		"v2/organizations/%s/jobs/%s/%s",
-		url.PathEscape(organization),
-		url.PathEscape(jobID),
-		action,
-	)
-}
-
 func getJobLog(ctx context.Context, client *buildkite.Client, organization, jobID string) (buildkite.JobLog, error) {
-	req, err := client.NewRequest(ctx, "GET", organizationJobPath(organization, jobID, "log"), nil)
+	req, err := client.NewRequest(ctx, "GET", internaljob.OrganizationJobPath(organization, jobID, "log"), nil)
 	if err != nil {
 		return buildkite.JobLog{}, err
 	}
@@ -37,8 +23,23 @@ func getJobLog(ctx context.Context, client *buildkite.Client, organization, jobI
 	return jobLog, nil
 }
 
+func getJob(ctx context.Context, client *buildkite.Client, organization, jobID string) (buildkite.Job, error) {
+	req, err := client.NewRequest(ctx, "GET", internaljob.OrganizationJobPath(organization, jobID, ""), nil)
+	if err != nil {
+		return buildkite.Job{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	var job buildkite.Job
+	if _, err := client.Do(req, &job); err != nil {
+		return buildkite.Job{}, err
+	}
+
+	return job, nil
+}
+
 func createVNCSession(ctx context.Context, client *buildkite.Client, organization, jobID string) (vncSession, error) {
-	req, err := client.NewRequest(ctx, http.MethodPost, organizationJobPath(organization, jobID, "vnc-session"), nil)
+	req, err := client.NewRequest(ctx, http.MethodPost, internaljob.OrganizationJobPath(organization, jobID, "vnc-session"), nil)
 	if err != nil {
 		return vncSession{}, err
 	}
@@ -56,7 +57,7 @@ func createVNCSession(ctx context.Context, client *buildkite.Client, organizatio
 }
 
 func createSSHSession(ctx context.Context, client *buildkite.Client, organization, jobID string) (sshSession, error) {
-	req, err := client.NewRequest(ctx, http.MethodPost, organizationJobPath(organization, jobID, "ssh-session"), nil)
+	req, err := client.NewRequest(ctx, http.MethodPost, internaljob.OrganizationJobPath(organization, jobID, "ssh-session"), nil)
 	if err != nil {
 		return sshSession{}, err
 	}
@@ -74,7 +75,7 @@ func createSSHSession(ctx context.Context, client *buildkite.Client, organizatio
 }
 
 func reprioritizeJob(ctx context.Context, client *buildkite.Client, organization, jobID string, priority int) (buildkite.Job, error) {
-	req, err := client.NewRequest(ctx, "PUT", organizationJobPath(organization, jobID, "reprioritize"), &buildkite.JobReprioritizationOptions{
+	req, err := client.NewRequest(ctx, "PUT", internaljob.OrganizationJobPath(organization, jobID, "reprioritize"), &buildkite.JobReprioritizationOptions{
 		Priority: priority,
 	})
 	if err != nil {
@@ -88,33 +89,3 @@ func reprioritizeJob(ctx context.Context, client *buildkite.Client, organization
 
 	return job, nil
 }
-
-func retryJob(ctx context.Context, client *buildkite.Client, organization, jobID string) (buildkite.Job, error) {
-	req, err := client.NewRequest(ctx, "PUT", organizationJobPath(organization, jobID, "retry"), nil)
-	if err != nil {
-		return buildkite.Job{}, err
-	}
-
-	var job buildkite.Job
-	if _, err := client.Do(req, &job); err != nil {
-		return buildkite.Job{}, err
-	}
-
-	return job, nil
-}
-
-func unblockJob(ctx context.Context, client *buildkite.Client, organization, jobID string, fields map[string]any) (buildkite.Job, error) {
-	req, err := client.NewRequest(ctx, "PUT", organizationJobPath(organization, jobID, "unblock"), &unblockJobOptions{
-		Fields: fields,
-	})
-	if err != nil {
-		return buildkite.Job{}, err
-	}
-
-	var job buildkite.Job
-	if _, err := client.Do(req, &job); err != nil {
-		return buildkite.Job{}, err
-	}
-
-	return job, nil
-}
@@ -7,6 +7,7 @@ import (
 	"github.com/alecthomas/kong"
 	"github.com/buildkite/cli/v3/internal/cli"
 	bkIO "github.com/buildkite/cli/v3/internal/io"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
 	"github.com/buildkite/cli/v3/pkg/cmd/factory"
 	"github.com/buildkite/cli/v3/pkg/cmd/validation"
 	buildkite "github.com/buildkite/go-buildkite/v5"
@@ -48,7 +49,7 @@ func (c *RetryCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 	var job buildkite.Job
 	if err = bkIO.SpinWhile(f, "Retrying job", func() error {
 		var apiErr error
-		job, apiErr = retryJob(
+		job, apiErr = internaljob.Retry(
 			ctx,
 			f.RestAPIClient,
 			organization,
@@ -0,0 +1,153 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/build/stats"
+	"github.com/buildkite/cli/v3/internal/cli"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	"github.com/buildkite/cli/v3/pkg/cmd/factory"
+	"github.com/buildkite/cli/v3/pkg/cmd/validation"
+	"github.com/buildkite/cli/v3/pkg/output"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+// maxStatsBuilds caps the builds whose jobs bk job stats aggregates.
+const maxStatsBuilds = 5000
+
+type StatsCmd struct {
+	Pipeline string   `help:"Filter by pipeline slug" short:"p"`
+	Since    string   `help:"Include jobs from builds created since this time (e.g. 24h, 720h)" default:"168h"`
+	Branch   []string `help:"Filter by branch name. Supports * and ? wildcards, e.g. feature/*" short:"b"`
+	State    []string `help:"Filter by job state"`
+	Limit    int      `help:"Maximum number of builds whose jobs to include" default:"1000"`
+	By       string   `help:"Group the trend by day or week" enum:"day,week" default:"day"`
+	Format   string   `help:"Export the stats as a spreadsheet instead: csv. Cannot be combined with -o, --json, --yaml or --text" enum:",csv" default:""`
+	output.OutputFlags
+}
+
+func (c *StatsCmd) Validate() error {
+	if c.Format == "csv" && (c.Output != "" || c.JSON || c.YAML || c.Text) {
+		return fmt.Errorf("--format csv cannot be combined with -o, --json, --yaml or --text")
+	}
+	return nil
+}
+
+func (c *StatsCmd) Help() string {
+	return `Report job statistics over a time window, step by step.
+
+Aggregates the command jobs of the builds matching the filters, including
+jobs that were retried: their fail rate (a job that soft failed counts as
+passed), percentiles of how long they ran and how long they waited for an
+agent, the same for each step, and a trend by day or week. Steps are listed
+by how many of their jobs failed.
+
+Use --format csv for one row per step, for pasting into a spreadsheet.
+
+Examples:
+  # Which steps failed or queued the most over the last week
+  $ bk job stats -p my-pipeline
+
+  # The last 30 days of jobs on main, by week
+  $ bk job stats -p my-pipeline --since 720h --branch main --by week
+
+  # Per-step stats as CSV
+  $ bk job stats -p my-pipeline --format csv > steps.csv
+
+  # Jobs of builds on release branches, as JSON
+  $ bk job stats -p my-pipeline --branch "release/*" -o json`
+}
+
+func (c *StatsCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
+	f, err := factory.New(factory.WithDebug(globals.EnableDebug()))
+	if err != nil {
+		return err
+	}
+
+	f.SkipConfirm = globals.SkipConfirmation()
+	f.NoInput = globals.DisableInput()
+	f.Quiet = globals.IsQuiet()
+	f.NoPager = f.NoPager || globals.DisablePager()
+
+	if err := validation.ValidateConfiguration(f.Config, kongCtx.Command()); err != nil {
+		return err
+	}
+
+	format := output.ResolveFormat(c.Output, f.Config.OutputFormat())
+
+	if c.Limit < 1 || c.Limit > maxStatsBuilds {
+		return fmt.Errorf("--limit must be between 1 and %d (requested: %d)", maxStatsBuilds, c.Limit)
+	}
+
+	org := f.Config.OrganizationSlug()
+	s, err := c.collect(context.Background(), f, org)
+	if err != nil {
+		return err
+	}
+
+	if c.Format == "csv" || format != output.FormatText {
+		return c.export(os.Stdout, s, format)
+	}
+
+	writer, cleanup := bkIO.Pager(f.NoPager, f.Config.Pager())
+	defer func() { _ = cleanup() }()
+
+	target := org
+	if c.Pipeline != "" {
+		target = fmt.Sprintf("%s/%s", org, c.Pipeline)
+	}
+	fmt.Fprintf(writer, "Showing stats for jobs of %s from builds created in the last %s\n\n", target, c.Since)
+	return stats.WriteJobs(writer, s, stats.Period(c.By))
+}
+
+// listOptions maps the filters onto those of bk job list.
+func (c *StatsCmd) listOptions() (jobListOptions, *buildkite.BuildsListOptions, error) {
+	opts := jobListOptions{
+		pipeline:  c.Pipeline,
+		since:     c.Since,
+		state:     c.State,
+		branch:    c.Branch,
+		maxBuilds: c.Limit,
+	}
+	listOpts, err := jobListOptionsFromFlags(&opts)
+	if err != nil {
+		return opts, nil, err
+	}
+	// Retried jobs count towards how often a step failed.
+	listOpts.IncludeRetriedJobs = true
+	return opts, listOpts, nil
+}
+
+// collect fetches the jobs matching the filters and aggregates them.
+func (c *StatsCmd) collect(ctx context.Context, f *factory.Factory, org string) (stats.JobStats, error) {
+	opts, listOpts, err := c.listOptions()
+	if err != nil {
+		return stats.JobStats{}, err
+	}
+
+	var jobs []buildkite.Job
+	if err = bkIO.SpinWhile(f, "Loading jobs", func() error {
+		jobs, err = fetchJobs(ctx, f, org, opts, listOpts)
+		return err
+	}); err != nil {
+		return stats.JobStats{}, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	jobs, err = applyClientSideFilters(jobs, opts, nil)
+	if err != nil {
+		return stats.JobStats{}, fmt.Errorf("failed to apply filters: %w", err)
+	}
+	return stats.Jobs(jobs, stats.Period(c.By)), nil
+}
+
+// export writes s as CSV if --format csv was given, or else in format.
+func (c *StatsCmd) export(w io.Writer, s stats.JobStats, format output.Format) error {
+	if c.Format == "csv" {
+		return stats.WriteJobsCSV(w, s)
+	}
+	return output.Write(w, s, format)
+}
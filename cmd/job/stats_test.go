@@ -0,0 +1,135 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/buildkite/cli/v3/internal/build/stats"
+	"github.com/buildkite/cli/v3/pkg/output"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestStatsListOptions(t *testing.T) {
+	cmd := &StatsCmd{Pipeline: "my-app", Since: "24h", Branch: []string{"main"}, State: []string{"failed"}, Limit: 50}
+
+	opts, listOpts, err := cmd.listOptions()
+	if err != nil {
+		t.Fatalf("listOptions() error = %v", err)
+	}
+	if opts.pipeline != "my-app" || opts.maxBuilds != 50 || len(opts.state) != 1 || opts.state[0] != "failed" {
+		t.Errorf("job options = %+v", opts)
+	}
+	if len(listOpts.Branch) != 1 || listOpts.Branch[0] != "main" {
+		t.Errorf("branch = %v, want [main]", listOpts.Branch)
+	}
+	if len(listOpts.State) != 0 {
+		t.Errorf("state = %v, want job states filtered client-side", listOpts.State)
+	}
+	if since := time.Since(listOpts.CreatedFrom); since < 23*time.Hour || since > 25*time.Hour {
+		t.Errorf("created from = %v, want about 24h ago", listOpts.CreatedFrom)
+	}
+	if !listOpts.ExcludePipeline || !listOpts.IncludeRetriedJobs {
+		t.Errorf("options = %+v, want pipeline excluded and retried jobs included", listOpts)
+	}
+
+	cmd.Branch = []string{"release/*"}
+	if _, listOpts, err = cmd.listOptions(); err != nil {
+		t.Fatalf("listOptions() error = %v", err)
+	}
+	if len(listOpts.Branch) != 0 {
+		t.Errorf("branch = %v, want wildcards left to the client", listOpts.Branch)
+	}
+}
+
+func TestStatsFormatFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{[]string{"--format", "csv"}, false},
+		{[]string{"--yaml"}, false},
+		{[]string{"--format", "csv", "-o", "text"}, true},
+		{[]string{"--format", "csv", "--yaml"}, true},
+		{[]string{"-o", "csv"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var cmd StatsCmd
+			parser, err := kong.New(&cmd, kong.Vars{"output_default_format": ""})
+			if err != nil {
+				t.Fatalf("kong.New() error = %v", err)
+			}
+			if _, err := parser.Parse(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStatsCollectAndExport(t *testing.T) {
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	statsJob := func(id, key, state string) buildkite.Job {
+		return buildkite.Job{
+			ID:         id,
+			Type:       "script",
+			StepKey:    key,
+			Label:      key,
+			State:      state,
+			CreatedAt:  buildkite.NewTimestamp(created),
+			RunnableAt: buildkite.NewTimestamp(created),
+			StartedAt:  buildkite.NewTimestamp(created.Add(time.Second)),
+			FinishedAt: buildkite.NewTimestamp(created.Add(time.Minute)),
+		}
+	}
+
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_ = json.NewEncoder(w).Encode([]buildkite.Build{
+			{Branch: "main", Jobs: []buildkite.Job{statsJob("1", "tests", "failed")}},
+			{Branch: "feature/login", Jobs: []buildkite.Job{statsJob("2", "tests", "failed"), statsJob("3", "lint", "passed")}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	cmd := &StatsCmd{Since: "168h", Branch: []string{"feature/*"}, Limit: pageSize, By: "day"}
+	s, err := cmd.collect(context.Background(), newJobListTestFactory(t, server.URL, nil), "test-org")
+	if err != nil {
+		t.Fatalf("collect() error = %v", err)
+	}
+	if !strings.Contains(query, "include_retried_jobs=true") {
+		t.Errorf("unexpected query %q", query)
+	}
+	if s.Jobs != 2 || s.Failed != 1 || len(s.Steps) != 2 {
+		t.Fatalf("stats = %+v, want the two jobs of the feature build", s)
+	}
+
+	var out bytes.Buffer
+	if err := cmd.export(&out, s, output.FormatJSON); err != nil {
+		t.Fatalf("export() error = %v", err)
+	}
+	var got stats.JobStats
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal JSON output: %v\n%s", err, out.String())
+	}
+	if got.Jobs != 2 || got.FailRate != 0.5 {
+		t.Errorf("JSON stats = %+v, want 2 jobs at a 0.5 fail rate", got)
+	}
+
+	cmd.Format = "csv"
+	out.Reset()
+	if err := cmd.export(&out, s, output.FormatJSON); err != nil {
+		t.Fatalf("export() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "step,jobs,passed,failed,fail_rate") || !strings.HasPrefix(lines[1], "tests,1,0,1,") {
+		t.Errorf("unexpected CSV output:\n%s", out.String())
+	}
+}
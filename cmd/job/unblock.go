@@ -3,7 +3,6 @@ package job
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,6 +11,7 @@ import (
 	"github.com/alecthomas/kong"
 	"github.com/buildkite/cli/v3/internal/cli"
 	bkIO "github.com/buildkite/cli/v3/internal/io"
+	internaljob "github.com/buildkite/cli/v3/internal/job"
 	"github.com/buildkite/cli/v3/pkg/cmd/factory"
 	"github.com/buildkite/cli/v3/pkg/cmd/validation"
 	buildkite "github.com/buildkite/go-buildkite/v5"
@@ -68,11 +68,11 @@ func (c *UnblockCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 	var job buildkite.Job
 	err = bkIO.SpinWhile(f, "Unblocking job", func() error {
 		var apiErr error
-		job, apiErr = unblockJob(ctx, f.RestAPIClient, organization, c.JobID, fields)
+		job, apiErr = internaljob.Unblock(ctx, f.RestAPIClient, organization, c.JobID, fields)
 		return apiErr
 	})
 	if err != nil {
-		if isAlreadyUnblocked(err) {
+		if internaljob.IsAlreadyUnblocked(err) {
 			fmt.Println("This job is already unblocked")
 			return nil
 		}
@@ -118,12 +118,3 @@ func parseUnblockFields(input string) (map[string]any, error) {
 
 	return fields, nil
 }
-
-func isAlreadyUnblocked(err error) bool {
-	var apiErr *buildkite.ErrorResponse
-	if !errors.As(err, &apiErr) {
-		return false
-	}
-
-	return apiErr.Message == "The job's state must be blocked" || apiErr.Message == "The job's state must be blocked."
-}
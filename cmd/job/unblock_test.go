@@ -3,7 +3,6 @@ package job
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -86,49 +85,6 @@ func TestParseUnblockFields(t *testing.T) {
 	}
 }
 
-func TestUnblockJobUsesRESTEndpoint(t *testing.T) {
-	t.Parallel()
-
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPut {
-			t.Fatalf("method = %s, want PUT", r.Method)
-		}
-		if r.URL.Path != "/v2/organizations/buildkite/jobs/job-1/unblock" {
-			t.Fatalf("path = %s", r.URL.Path)
-		}
-
-		var body struct {
-			Fields map[string]any `json:"fields"`
-		}
-		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-			t.Fatalf("decode body: %v", err)
-		}
-		if body.Fields["release"] != "v1.2.3" {
-			t.Fatalf("fields = %#v", body.Fields)
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"id":"job-1","state":"unblocked","web_url":"https://buildkite.com/buildkite/cli/builds/42#job-1"}`))
-	}))
-	defer server.Close()
-
-	client, err := buildkite.NewOpts(
-		buildkite.WithBaseURL(server.URL),
-		buildkite.WithTokenAuth("test-token"),
-	)
-	if err != nil {
-		t.Fatalf("new client: %v", err)
-	}
-
-	job, err := unblockJob(context.Background(), client, "buildkite", "job-1", map[string]any{"release": "v1.2.3"})
-	if err != nil {
-		t.Fatalf("unblockJob() error = %v", err)
-	}
-	if job.ID != "job-1" || job.State != "unblocked" {
-		t.Fatalf("job = %#v", job)
-	}
-}
-
 func TestGetJobLogUsesOrganizationEndpoint(t *testing.T) {
 	t.Parallel()
 
@@ -207,44 +163,3 @@ func TestReprioritizeJobUsesOrganizationEndpoint(t *testing.T) {
 		t.Fatalf("job = %#v", job)
 	}
 }
-
-func TestIsAlreadyUnblocked(t *testing.T) {
-	t.Parallel()
-
-	tests := []struct {
-		name string
-		err  error
-		want bool
-	}{
-		{
-			name: "blocked state API error",
-			err:  &buildkite.ErrorResponse{Message: "The job's state must be blocked"},
-			want: true,
-		},
-		{
-			name: "blocked state API error with period",
-			err:  &buildkite.ErrorResponse{Message: "The job's state must be blocked."},
-			want: true,
-		},
-		{
-			name: "other API error",
-			err:  &buildkite.ErrorResponse{Message: "This job type cannot be unblocked"},
-			want: false,
-		},
-		{
-			name: "other error",
-			err:  errors.New("boom"),
-			want: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			if got := isAlreadyUnblocked(tt.err); got != tt.want {
-				t.Fatalf("isAlreadyUnblocked() = %t, want %t", got, tt.want)
-			}
-		})
-	}
-}
@@ -237,7 +237,7 @@ func (c *RunCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 		// tracker falls back to the client-side declaration heuristic for this
 		// poll rather than trusting a stale set from an earlier poll.
 		var serverFailing map[string]bool
-		if hasRunningScriptJob(b) {
+		if watch.HasRunningScriptJob(b) {
 			if failing, ferr := watch.FetchPromisedHardFailures(ctx, f.RestAPIClient, resolvedPipeline.Org, resolvedPipeline.Name, build.Number); ferr == nil {
 				serverFailing = failing
 			}
@@ -369,18 +369,6 @@ func (c *RunCmd) Run(kongCtx *kong.Context, globals cli.GlobalFlags) error {
 	return finalErr
 }
 
-// hasRunningScriptJob reports whether the build has at least one still-running
-// script job. Used to avoid the extra jobs-index request when nothing is
-// running and so no promised failure could be declared yet.
-func hasRunningScriptJob(b buildkite.Build) bool {
-	for _, j := range b.Jobs {
-		if j.Type == "script" && (j.State == "running" || j.State == "canceling" || j.State == "timing_out") {
-			return true
-		}
-	}
-	return false
-}
-
 func cleanupRemoteBranch(renderer renderer, repoRoot, branch, ref, preflightID string, debug bool) {
 	_ = renderer.Render(Event{Type: EventOperation, Time: time.Now(), PreflightID: preflightID, Title: fmt.Sprintf("Cleaning up remote branch %s...", branch)})
 	if cleanupErr := internalpreflight.Cleanup(repoRoot, ref, debug); cleanupErr != nil {
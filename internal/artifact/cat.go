@@ -0,0 +1,113 @@
+package artifact
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	tarMagic  = []byte("ustar")
+)
+
+// tarMagicOffset is where a tar header's magic starts.
+const tarMagicOffset = 257
+
+// Open streams the content of the artifact at url. Closing the reader before
+// the end stops the download.
+func Open(ctx context.Context, client *buildkite.Client, url string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := client.Artifacts.DownloadArtifactByURL(ctx, url, pw)
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// Decompress returns a reader of r's content with gzip or zstd compression
+// removed, detecting the format from the first bytes. Other content is
+// returned unchanged.
+func Decompress(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(len(zstdMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(head, zstdMagic):
+		d, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+// IsTar reports whether r starts with a tar header, and returns a reader of
+// all of r's content.
+func IsTar(r io.Reader) (bool, io.Reader, error) {
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(tarMagicOffset + len(tarMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, br, err
+	}
+	return len(head) == tarMagicOffset+len(tarMagic) && bytes.Equal(head[tarMagicOffset:], tarMagic), br, nil
+}
+
+// WriteTarMembers writes the content of the regular files in the tar archive
+// r whose names match pattern (see path.Match; empty matches every file).
+// header, when non-nil, is called before each member is written. It returns
+// the number of members written.
+func WriteTarMembers(w io.Writer, r io.Reader, pattern string, header func(name string) error) (int, error) {
+	if pattern != "" {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return 0, fmt.Errorf("invalid member pattern %q: %w", pattern, err)
+		}
+	}
+
+	written := 0
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return written, nil
+		}
+		if err != nil {
+			return written, fmt.Errorf("reading tar archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if pattern != "" {
+			if ok, _ := path.Match(pattern, hdr.Name); !ok {
+				continue
+			}
+		}
+
+		if header != nil {
+			if err := header(hdr.Name); err != nil {
+				return written, err
+			}
+		}
+		if _, err := io.Copy(w, tr); err != nil {
+			return written, err
+		}
+		written++
+	}
+}
@@ -0,0 +1,111 @@
+package artifact
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestDecompress(t *testing.T) {
+	t.Parallel()
+
+	const text = "hello, artifact\n"
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, _ = gw.Write([]byte(text))
+	_ = gw.Close()
+
+	zw, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zst := zw.EncodeAll([]byte(text), nil)
+
+	tests := []struct {
+		name string
+		in   []byte
+	}{
+		{"plain", []byte(text)},
+		{"gzip", gz.Bytes()},
+		{"zstd", zst},
+	}
+	for _, tt := range tests {
+		r, err := Decompress(bytes.NewReader(tt.in))
+		if err != nil {
+			t.Fatalf("%s: Decompress() error = %v", tt.name, err)
+		}
+		got, err := io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			t.Fatalf("%s: read error = %v", tt.name, err)
+		}
+		if string(got) != text {
+			t.Errorf("%s: got %q, want %q", tt.name, got, text)
+		}
+	}
+
+	r, err := Decompress(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("Decompress(empty) error = %v", err)
+	}
+	if got, _ := io.ReadAll(r); len(got) != 0 {
+		t.Errorf("expected empty content, got %q", got)
+	}
+}
+
+func tarOf(t *testing.T, files map[string]string, order []string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range order {
+		body := files[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte(body))
+	}
+	_ = tw.Close()
+	return buf.Bytes()
+}
+
+func TestIsTarAndWriteTarMembers(t *testing.T) {
+	t.Parallel()
+
+	archive := tarOf(t, map[string]string{
+		"reports/junit.xml": "<testsuite/>\n",
+		"reports/cover.out": "mode: set\n",
+	}, []string{"reports/junit.xml", "reports/cover.out"})
+
+	isTar, r, err := IsTar(bytes.NewReader(archive))
+	if err != nil || !isTar {
+		t.Fatalf("IsTar() = %v, %v; want true", isTar, err)
+	}
+
+	var out strings.Builder
+	var headers []string
+	n, err := WriteTarMembers(&out, r, "*/junit.xml", func(name string) error {
+		headers = append(headers, name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || out.String() != "<testsuite/>\n" || len(headers) != 1 || headers[0] != "reports/junit.xml" {
+		t.Errorf("unexpected members: n=%d headers=%v out=%q", n, headers, out.String())
+	}
+
+	isTar, _, err = IsTar(strings.NewReader("just text"))
+	if err != nil || isTar {
+		t.Errorf("IsTar(text) = %v, %v; want false", isTar, err)
+	}
+
+	if _, err := WriteTarMembers(io.Discard, bytes.NewReader(archive), "[", nil); err == nil {
+		t.Error("expected an invalid pattern to be rejected")
+	}
+}
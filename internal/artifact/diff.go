@@ -0,0 +1,91 @@
+package artifact
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+// Change is an artifact that differs between two builds. Old is nil for an
+// added artifact and New is nil for a removed one.
+type Change struct {
+	Path string              `json:"path"`
+	Old  *buildkite.Artifact `json:"old,omitempty"`
+	New  *buildkite.Artifact `json:"new,omitempty"`
+
+	// Diff is a unified diff of the artifact's content, when it was asked
+	// for and the artifact is text.
+	Diff string `json:"diff,omitempty"`
+}
+
+// Diff is the difference between the artifacts of two builds.
+type Diff struct {
+	Added     []Change `json:"added"`
+	Removed   []Change `json:"removed"`
+	Changed   []Change `json:"changed"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// Compare matches the artifacts of two builds by path and reports which were
+// added, removed, or changed in size or SHA-1. When several artifacts share a
+// path (e.g. from parallel jobs), they are matched in order of checksum and
+// the later ones are labelled "path [2]", "path [3]" and so on.
+func Compare(from, to []buildkite.Artifact) Diff {
+	oldByKey := artifactsByKey(from)
+	newByKey := artifactsByKey(to)
+
+	var d Diff
+	for key, o := range oldByKey {
+		n, ok := newByKey[key]
+		switch {
+		case !ok:
+			d.Removed = append(d.Removed, Change{Path: key, Old: o})
+		case artifactChanged(*o, *n):
+			d.Changed = append(d.Changed, Change{Path: key, Old: o, New: n})
+		default:
+			d.Unchanged++
+		}
+	}
+	for key, n := range newByKey {
+		if _, ok := oldByKey[key]; !ok {
+			d.Added = append(d.Added, Change{Path: key, New: n})
+		}
+	}
+
+	for _, changes := range [][]Change{d.Added, d.Removed, d.Changed} {
+		slices.SortFunc(changes, func(a, b Change) int { return strings.Compare(a.Path, b.Path) })
+	}
+	return d
+}
+
+func artifactsByKey(artifacts []buildkite.Artifact) map[string]*buildkite.Artifact {
+	sorted := slices.Clone(artifacts)
+	slices.SortStableFunc(sorted, func(a, b buildkite.Artifact) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.SHA1, b.SHA1)
+	})
+
+	byKey := make(map[string]*buildkite.Artifact, len(sorted))
+	seen := make(map[string]int)
+	for i := range sorted {
+		a := &sorted[i]
+		seen[a.Path]++
+		key := a.Path
+		if n := seen[a.Path]; n > 1 {
+			key = fmt.Sprintf("%s [%d]", a.Path, n)
+		}
+		byKey[key] = a
+	}
+	return byKey
+}
+
+func artifactChanged(from, to buildkite.Artifact) bool {
+	if from.SHA1 != "" && to.SHA1 != "" {
+		return !strings.EqualFold(from.SHA1, to.SHA1)
+	}
+	return from.FileSize != to.FileSize
+}
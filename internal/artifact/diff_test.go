@@ -0,0 +1,125 @@
+package artifact
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	from := []buildkite.Artifact{
+		{Path: "same.txt", SHA1: "aaa", FileSize: 10},
+		{Path: "changed.txt", SHA1: "bbb", FileSize: 10},
+		{Path: "resized.bin", FileSize: 10},
+		{Path: "removed.txt", SHA1: "ccc", FileSize: 5},
+		{Path: "parallel.log", SHA1: "d1"},
+		{Path: "parallel.log", SHA1: "d2"},
+	}
+	to := []buildkite.Artifact{
+		{Path: "same.txt", SHA1: "AAA", FileSize: 10},
+		{Path: "changed.txt", SHA1: "bbc", FileSize: 10},
+		{Path: "resized.bin", FileSize: 12},
+		{Path: "added.txt", SHA1: "eee", FileSize: 7},
+		{Path: "parallel.log", SHA1: "d2"},
+		{Path: "parallel.log", SHA1: "d1"},
+		{Path: "parallel.log", SHA1: "d3"},
+	}
+
+	d := Compare(from, to)
+
+	paths := func(changes []Change) []string {
+		var p []string
+		for _, c := range changes {
+			p = append(p, c.Path)
+		}
+		return p
+	}
+	assertPaths := func(name string, got, want []string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s = %v, want %v", name, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("%s = %v, want %v", name, got, want)
+			}
+		}
+	}
+
+	assertPaths("Added", paths(d.Added), []string{"added.txt", "parallel.log [3]"})
+	assertPaths("Removed", paths(d.Removed), []string{"removed.txt"})
+	assertPaths("Changed", paths(d.Changed), []string{"changed.txt", "resized.bin"})
+	if d.Unchanged != 3 {
+		t.Errorf("Unchanged = %d, want 3", d.Unchanged)
+	}
+	if d.Changed[0].Old.SHA1 != "bbb" || d.Changed[0].New.SHA1 != "bbc" {
+		t.Errorf("Changed[0] = %+v, want old and new versions of changed.txt", d.Changed[0])
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{"identical", "a\nb\n", "a\nb\n", ""},
+		{
+			"changed line",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			"--- a/f\n+++ b/f\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			"separate hunks",
+			"a\n1\n2\n3\n4\n5\n6\n7\n8\nz\n",
+			"A\n1\n2\n3\n4\n5\n6\n7\n8\nZ\n",
+			"--- a/f\n+++ b/f\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -7,4 +7,4 @@\n 6\n 7\n 8\n-z\n+Z\n",
+		},
+		{"from empty", "", "new\n", "--- a/f\n+++ b/f\n@@ -0,0 +1 @@\n+new\n"},
+		{"missing newline", "a\n", "a\nb", "--- a/f\n+++ b/f\n@@ -1 +1,2 @@\n a\n+b\n\\ No newline at end of file\n"},
+	}
+	for _, tt := range tests {
+		if got := UnifiedDiff("a/f", "b/f", tt.old, tt.new); got != tt.want {
+			t.Errorf("%s: UnifiedDiff() =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnifiedDiffEveryLineChanged(t *testing.T) {
+	t.Parallel()
+
+	// Every line differs, the worst case for memory when diffing.
+	var old, new strings.Builder
+	for i := range 20000 {
+		fmt.Fprintf(&old, "old %d\n", i)
+		fmt.Fprintf(&new, "new %d\n", i)
+	}
+	got := UnifiedDiff("a/f", "b/f", old.String(), new.String())
+	if !strings.HasPrefix(got, "--- a/f\n+++ b/f\n@@ -1,20000 +1,20000 @@\n-old 0\n") {
+		t.Errorf("UnifiedDiff() starts %q, want one hunk replacing every line", got[:min(len(got), 80)])
+	}
+	if n := strings.Count(got, "\n+new "); n != 20000 {
+		t.Errorf("UnifiedDiff() inserts %d lines, want 20000", n)
+	}
+}
+
+func TestIsText(t *testing.T) {
+	t.Parallel()
+
+	if !IsText([]byte("plain text ✓\n")) {
+		t.Error("expected UTF-8 text to be text")
+	}
+	if IsText([]byte{'a', 0, 'b'}) {
+		t.Error("expected content with a NUL byte not to be text")
+	}
+	if IsText([]byte{0xff, 0xfe}) {
+		t.Error("expected invalid UTF-8 not to be text")
+	}
+}
@@ -2,12 +2,28 @@ package artifact
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	buildkite "github.com/buildkite/go-buildkite/v5"
 )
 
+// partialSuffix is appended to the destination path of a download in
+// progress. An interrupted download leaves the partial file behind so the
+// next attempt can resume it.
+const partialSuffix = ".partial"
+
+// ErrChecksumMismatch is returned when a downloaded artifact's SHA-1 doesn't
+// match the one Buildkite recorded for it.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
 // DownloadToFile creates destPath (including any missing parent directories)
 // and streams the artifact at url into it.
 func DownloadToFile(ctx context.Context, client *buildkite.Client, url, destPath string) error {
@@ -24,3 +40,179 @@ func DownloadToFile(ctx context.Context, client *buildkite.Client, url, destPath
 	_, err = client.Artifacts.DownloadArtifactByURL(ctx, url, out)
 	return err
 }
+
+// DownloadOutcome describes what Download did.
+type DownloadOutcome int
+
+const (
+	// Downloaded means the artifact was downloaded but had no checksum to
+	// verify it against.
+	Downloaded DownloadOutcome = iota
+	// Verified means the artifact was downloaded and its checksum matched.
+	Verified
+	// Skipped means destPath already held the artifact, so nothing was
+	// downloaded.
+	Skipped
+)
+
+// Download downloads an artifact to destPath, creating any missing parent
+// directories. The artifact is written to a partial file next to destPath
+// and only moved into place once complete and verified against its SHA-1,
+// so an interrupted download never leaves a truncated file at destPath and
+// is resumed by the next call. An existing file whose checksum matches is
+// left alone.
+func Download(ctx context.Context, client *buildkite.Client, a buildkite.Artifact, destPath string) (DownloadOutcome, error) {
+	if a.SHA1 != "" {
+		if sum, err := fileSHA1(destPath); err == nil && strings.EqualFold(sum, a.SHA1) {
+			return Skipped, nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), os.ModePerm); err != nil {
+		return Downloaded, err
+	}
+
+	partial := destPath + partialSuffix
+	resumed, err := downloadPartial(ctx, client, a, partial)
+	if err != nil {
+		return Downloaded, err
+	}
+
+	err = verify(partial, a.SHA1)
+	if errors.Is(err, ErrChecksumMismatch) && resumed {
+		// The partial file may have come from a different version of the
+		// artifact, so start again from scratch once.
+		if err := os.Remove(partial); err != nil {
+			return Downloaded, err
+		}
+		if _, err := downloadPartial(ctx, client, a, partial); err != nil {
+			return Downloaded, err
+		}
+		err = verify(partial, a.SHA1)
+	}
+	if err != nil {
+		_ = os.Remove(partial)
+		return Downloaded, err
+	}
+
+	if err := os.Rename(partial, destPath); err != nil {
+		return Downloaded, err
+	}
+	if a.SHA1 == "" {
+		return Downloaded, nil
+	}
+	return Verified, nil
+}
+
+// downloadPartial downloads the rest of an artifact into path, resuming from
+// the end of any existing content with a range request. It reports whether
+// it resumed.
+func downloadPartial(ctx context.Context, client *buildkite.Client, a buildkite.Artifact, path string) (bool, error) {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return false, err
+	}
+	defer out.Close()
+
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return false, err
+	}
+	if offset > 0 && a.FileSize > 0 && offset >= a.FileSize {
+		// Already complete, or longer than the artifact; verification
+		// decides which.
+		return true, nil
+	}
+
+	resp, err := getRange(ctx, client, a.DownloadURL, offset, out)
+	var errResp *buildkite.ErrorResponse
+	if offset > 0 && errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// The partial file doesn't fit the artifact; start again.
+		if err := restart(out); err != nil {
+			return false, err
+		}
+		offset = 0
+		resp, err = getRange(ctx, client, a.DownloadURL, 0, out)
+	}
+	if err != nil {
+		return false, fmt.Errorf("downloading %s: %w", a.Path, err)
+	}
+
+	if offset > 0 && resp.StatusCode != http.StatusPartialContent {
+		// The server ignored the range and sent the whole artifact after
+		// the content we already had, so drop the old content.
+		if err := discardPrefix(out, offset); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+	return offset > 0, nil
+}
+
+// getRange requests the content at url from offset onwards, writing it to w.
+func getRange(ctx context.Context, client *buildkite.Client, url string, offset int64, w io.Writer) (*buildkite.Response, error) {
+	req, err := client.NewRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	return client.Do(req, w)
+}
+
+func restart(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.Seek(0, io.SeekStart)
+	return err
+}
+
+// discardPrefix removes the first n bytes of f.
+func discardPrefix(f *os.File, n int64) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(f.Name())
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if _, err := io.Copy(io.NewOffsetWriter(f, 0), io.NewSectionReader(src, n, info.Size()-n)); err != nil {
+		return err
+	}
+	return f.Truncate(info.Size() - n)
+}
+
+// verify checks the file at path against a SHA-1 checksum. An empty checksum
+// can't be checked and always passes.
+func verify(path, want string) error {
+	if want == "" {
+		return nil
+	}
+	got, err := fileSHA1(path)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("%w: expected SHA-1 %s, got %s", ErrChecksumMismatch, want, got)
+	}
+	return nil
+}
+
+func fileSHA1(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha1.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
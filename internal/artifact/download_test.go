@@ -2,11 +2,18 @@ package artifact
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
 )
 
 func TestDownloadToFileCreatesParentDirAndWritesBody(t *testing.T) {
@@ -32,3 +39,171 @@ func TestDownloadToFileCreatesParentDirAndWritesBody(t *testing.T) {
 		t.Fatalf("file contents = %q, want %q", got, body)
 	}
 }
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRangeServer serves body, honouring Range requests when ranges is set,
+// and records the Range header of each request.
+func newRangeServer(t *testing.T, body string, ranges bool, requested *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rng := r.Header.Get("Range")
+		*requested = append(*requested, rng)
+		var start int
+		if ranges && rng != "" {
+			if _, err := fmt.Sscanf(rng, "bytes=%d-", &start); err != nil || start > len(body) {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(body)-1, len(body)))
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		_, _ = w.Write([]byte(body[start:]))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloadVerifiesChecksum(t *testing.T) {
+	t.Parallel()
+
+	const body = "artifact-bytes"
+	var requested []string
+	server := newRangeServer(t, body, true, &requested)
+	dest := filepath.Join(t.TempDir(), "out", "file.bin")
+	a := buildkite.Artifact{Path: "file.bin", DownloadURL: server.URL, FileSize: int64(len(body)), SHA1: sha1Hex(body)}
+
+	outcome, err := Download(context.Background(), newTestClient(t, server.URL), a, dest)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if outcome != Verified {
+		t.Errorf("outcome = %v, want Verified", outcome)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != body {
+		t.Errorf("file contents = %q, want %q", got, body)
+	}
+	if _, err := os.Stat(dest + partialSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected the partial file to be gone, got %v", err)
+	}
+
+	// A second download finds the file up to date.
+	outcome, err = Download(context.Background(), newTestClient(t, server.URL), a, dest)
+	if err != nil || outcome != Skipped {
+		t.Errorf("second Download() = %v, %v; want Skipped", outcome, err)
+	}
+	if len(requested) != 1 {
+		t.Errorf("expected a single request, got %d", len(requested))
+	}
+}
+
+func TestDownloadResumesPartialFile(t *testing.T) {
+	t.Parallel()
+
+	const body = "0123456789abcdef"
+	var requested []string
+	server := newRangeServer(t, body, true, &requested)
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(dest+partialSuffix, []byte(body[:6]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a := buildkite.Artifact{Path: "file.bin", DownloadURL: server.URL, FileSize: int64(len(body)), SHA1: sha1Hex(body)}
+
+	outcome, err := Download(context.Background(), newTestClient(t, server.URL), a, dest)
+	if err != nil || outcome != Verified {
+		t.Fatalf("Download() = %v, %v; want Verified", outcome, err)
+	}
+	if len(requested) != 1 || requested[0] != "bytes=6-" {
+		t.Errorf("expected a single range request from byte 6, got %q", requested)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != body {
+		t.Errorf("file contents = %q, want %q", got, body)
+	}
+}
+
+func TestDownloadRestartsWhenRangeIgnored(t *testing.T) {
+	t.Parallel()
+
+	const body = "0123456789abcdef"
+	var requested []string
+	server := newRangeServer(t, body, false, &requested)
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(dest+partialSuffix, []byte(body[:6]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a := buildkite.Artifact{Path: "file.bin", DownloadURL: server.URL, SHA1: sha1Hex(body)}
+
+	if _, err := Download(context.Background(), newTestClient(t, server.URL), a, dest); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != body {
+		t.Errorf("file contents = %q, want %q", got, body)
+	}
+}
+
+func TestDownloadRetriesStalePartialFile(t *testing.T) {
+	t.Parallel()
+
+	const body = "0123456789abcdef"
+	var requested []string
+	server := newRangeServer(t, body, true, &requested)
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	// The partial file is from a different version of the artifact.
+	if err := os.WriteFile(dest+partialSuffix, []byte("XXXXXX"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a := buildkite.Artifact{Path: "file.bin", DownloadURL: server.URL, FileSize: int64(len(body)), SHA1: sha1Hex(body)}
+
+	outcome, err := Download(context.Background(), newTestClient(t, server.URL), a, dest)
+	if err != nil || outcome != Verified {
+		t.Fatalf("Download() = %v, %v; want Verified", outcome, err)
+	}
+	if len(requested) != 2 || requested[1] != "" {
+		t.Errorf("expected a resumed request then a full one, got %q", requested)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != body {
+		t.Errorf("file contents = %q, want %q", got, body)
+	}
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	t.Parallel()
+
+	var requested []string
+	server := newRangeServer(t, "corrupted", true, &requested)
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	a := buildkite.Artifact{Path: "file.bin", DownloadURL: server.URL, SHA1: sha1Hex("original")}
+
+	_, err := Download(context.Background(), newTestClient(t, server.URL), a, dest)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Download() error = %v, want ErrChecksumMismatch", err)
+	}
+	for _, path := range []string{dest, dest + partialSuffix} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to exist, got %v", path, err)
+		}
+	}
+}
+
+func TestDownloadReport(t *testing.T) {
+	t.Parallel()
+
+	var r DownloadReport
+	r.Add("a", Verified, nil)
+	r.Add("b", Skipped, nil)
+	r.Add("c", Downloaded, nil)
+	r.Add("d", Downloaded, errors.New("boom"))
+
+	var out strings.Builder
+	r.Write(&out)
+	want := "Verified: 1, skipped (already up to date): 1, downloaded without a checksum: 1, failed: 1\n  d: boom\n"
+	if out.String() != want {
+		t.Errorf("Write() = %q, want %q", out.String(), want)
+	}
+	if err := r.Err(); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Err() = %v", err)
+	}
+}
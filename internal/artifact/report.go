@@ -0,0 +1,71 @@
+package artifact
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// DownloadReport collects the outcome of downloading several artifacts. It
+// is safe for concurrent use.
+type DownloadReport struct {
+	mu         sync.Mutex
+	Verified   []string
+	Unverified []string
+	Skipped    []string
+	Failed     []DownloadFailure
+}
+
+// DownloadFailure is an artifact that couldn't be downloaded.
+type DownloadFailure struct {
+	Path string
+	Err  error
+}
+
+// Add records the outcome of downloading the artifact at path.
+func (r *DownloadReport) Add(path string, outcome DownloadOutcome, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case err != nil:
+		r.Failed = append(r.Failed, DownloadFailure{Path: path, Err: err})
+	case outcome == Skipped:
+		r.Skipped = append(r.Skipped, path)
+	case outcome == Verified:
+		r.Verified = append(r.Verified, path)
+	default:
+		r.Unverified = append(r.Unverified, path)
+	}
+}
+
+// Err returns an error summarising the failed downloads, or nil if there
+// were none.
+func (r *DownloadReport) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch len(r.Failed) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("failed to download %s: %w", r.Failed[0].Path, r.Failed[0].Err)
+	default:
+		return fmt.Errorf("failed to download %d artifacts", len(r.Failed))
+	}
+}
+
+// Write writes a summary of the downloads, listing each failure.
+func (r *DownloadReport) Write(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(w, "Verified: %d, skipped (already up to date): %d", len(r.Verified), len(r.Skipped))
+	if len(r.Unverified) > 0 {
+		fmt.Fprintf(w, ", downloaded without a checksum: %d", len(r.Unverified))
+	}
+	fmt.Fprintf(w, ", failed: %d\n", len(r.Failed))
+	for _, f := range r.Failed {
+		fmt.Fprintf(w, "  %s: %v\n", f.Path, f.Err)
+	}
+}
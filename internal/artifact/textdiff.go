@@ -0,0 +1,146 @@
+package artifact
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+// in a unified diff.
+const diffContextLines = 3
+
+// IsText reports whether content looks like text: valid UTF-8 without NUL
+// bytes.
+func IsText(content []byte) bool {
+	return utf8.Valid(content) && !bytes.ContainsRune(content, 0)
+}
+
+type diffOpKind byte
+
+const (
+	diffEqual  diffOpKind = ' '
+	diffDelete diffOpKind = '-'
+	diffInsert diffOpKind = '+'
+)
+
+type diffOp struct {
+	kind diffOpKind
+	// Line numbers (0-based) in the old and new text. For an insert, old is
+	// the position the line is inserted at, and likewise new for a delete.
+	old, new int
+	text     string
+}
+
+// UnifiedDiff returns a unified diff between two texts, or "" when they are
+// the same.
+func UnifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := diffLines(oldText, newText)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range diffHunks(ops) {
+		writeHunk(&b, ops[h[0]:h[1]])
+	}
+	return b.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the edit script turning oldText into newText, line by
+// line. It uses go-diff, whose Myers implementation needs memory linear in the
+// size of the texts and gives up on finding the shortest script after a second,
+// so large artifacts with many changes still compare quickly.
+func diffLines(oldText, newText string) []diffOp {
+	dmp := diffmatchpatch.New()
+	a, b, lineArray := dmp.DiffLinesToRunes(oldText, newText)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMainRunes(a, b, false), lineArray)
+
+	var ops []diffOp
+	x, y := 0, 0
+	for _, d := range diffs {
+		for _, line := range splitLines(d.Text) {
+			switch d.Type {
+			case diffmatchpatch.DiffEqual:
+				ops = append(ops, diffOp{kind: diffEqual, old: x, new: y, text: line})
+				x++
+				y++
+			case diffmatchpatch.DiffDelete:
+				ops = append(ops, diffOp{kind: diffDelete, old: x, new: y, text: line})
+				x++
+			case diffmatchpatch.DiffInsert:
+				ops = append(ops, diffOp{kind: diffInsert, old: x, new: y, text: line})
+				y++
+			}
+		}
+	}
+	return ops
+}
+
+// diffHunks groups the changes in ops, with their context, into hunks given
+// as [start, end) ranges of ops.
+func diffHunks(ops []diffOp) [][2]int {
+	var hunks [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == diffEqual {
+			continue
+		}
+		start := max(i-diffContextLines, 0)
+		end := min(i+1+diffContextLines, len(ops))
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1][1] {
+			hunks[len(hunks)-1][1] = end
+		} else {
+			hunks = append(hunks, [2]int{start, end})
+		}
+	}
+	return hunks
+}
+
+func writeHunk(b *strings.Builder, ops []diffOp) {
+	oldStart, newStart := ops[0].old, ops[0].new
+	oldLines, newLines := 0, 0
+	for _, op := range ops {
+		if op.kind != diffInsert {
+			oldLines++
+		}
+		if op.kind != diffDelete {
+			newLines++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldLines), hunkRange(newStart, newLines))
+	for _, op := range ops {
+		b.WriteByte(byte(op.kind))
+		b.WriteString(op.text)
+		if !strings.HasSuffix(op.text, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the 0-based start and length of a hunk's lines in the
+// unified diff style.
+func hunkRange(start, lines int) string {
+	switch lines {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, lines)
+	}
+}
@@ -0,0 +1,216 @@
+// Package archive reads and writes portable build archives: a gzipped tar of
+// a build's JSON, job logs, annotations, artifact listing, Test Engine summary
+// and, optionally, its artifact files. Archives outlive the retention of logs
+// and artifacts on Buildkite and can be viewed without API access.
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/preflight"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+// FormatVersion is the version of the archive layout written by Writer.
+// Archives with a newer version can't be read.
+const FormatVersion = 1
+
+// Entry names within an archive.
+const (
+	ManifestFile    = "manifest.json"
+	BuildFile       = "build.json"
+	AnnotationsFile = "annotations.json"
+	ArtifactsFile   = "artifacts.json"
+	TestsFile       = "tests.json"
+	LogsDir         = "logs"
+	ArtifactFileDir = "artifacts"
+)
+
+// Manifest describes an archive.
+type Manifest struct {
+	FormatVersion     int       `json:"format_version"`
+	ExportedAt        time.Time `json:"exported_at"`
+	Organization      string    `json:"organization"`
+	Pipeline          string    `json:"pipeline"`
+	BuildNumber       int       `json:"build_number"`
+	IncludesArtifacts bool      `json:"includes_artifacts"`
+	Redacted          bool      `json:"redacted"`
+}
+
+// Archive is the content of a build archive, apart from artifact files.
+type Archive struct {
+	Manifest    Manifest
+	Build       buildkite.Build
+	Annotations []buildkite.Annotation
+	Artifacts   []buildkite.Artifact
+
+	// Tests is the build's Test Engine summary, or nil if it had none.
+	Tests *preflight.SummaryResult
+
+	// Logs holds each job's raw log content by job ID.
+	Logs map[string]string
+
+	// ArtifactFiles lists the artifact files in the archive, by entry name.
+	ArtifactFiles []string
+}
+
+// LogFile returns the entry name of a job's log.
+func LogFile(jobID string) string {
+	return path.Join(LogsDir, jobID+".log")
+}
+
+// ArtifactFile returns the entry name of an artifact's file.
+func ArtifactFile(a buildkite.Artifact) string {
+	return path.Join(ArtifactFileDir, a.ID, path.Clean("/" + a.Path)[1:])
+}
+
+// Writer writes the entries of an archive.
+type Writer struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+// NewWriter returns a Writer that writes a gzipped tar to w.
+func NewWriter(w io.Writer) *Writer {
+	gz := gzip.NewWriter(w)
+	return &Writer{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+// WriteJSON writes v as an indented JSON entry.
+func (w *Writer) WriteJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", name, err)
+	}
+	return w.WriteFile(name, data)
+}
+
+// WriteFile writes an entry with the given content.
+func (w *Writer) WriteFile(name string, data []byte) error {
+	return w.WriteFrom(name, int64(len(data)), bytes.NewReader(data))
+}
+
+// WriteFrom writes an entry of size bytes read from r.
+func (w *Writer) WriteFrom(name string, size int64, r io.Reader) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    size,
+		ModTime: time.Now(),
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	if _, err := io.CopyN(w.tw, r, size); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+// Close finishes the archive. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
+
+// Open reads the archive at name.
+func Open(name string) (*Archive, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	a, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading archive %s: %w", name, err)
+	}
+	return a, nil
+}
+
+// Read reads an archive from r. Artifact files are listed but not loaded.
+func Read(r io.Reader) (*Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	a := &Archive{Logs: make(map[string]string)}
+	seenManifest, seenBuild := false, false
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(hdr.Name)
+		switch {
+		case name == ManifestFile:
+			if err := decodeJSON(tr, name, &a.Manifest); err != nil {
+				return nil, err
+			}
+			if a.Manifest.FormatVersion > FormatVersion {
+				return nil, fmt.Errorf("archive format version %d is newer than this version of bk supports (%d); upgrade bk to read it", a.Manifest.FormatVersion, FormatVersion)
+			}
+			seenManifest = true
+		case name == BuildFile:
+			if err := decodeJSON(tr, name, &a.Build); err != nil {
+				return nil, err
+			}
+			seenBuild = true
+		case name == AnnotationsFile:
+			if err := decodeJSON(tr, name, &a.Annotations); err != nil {
+				return nil, err
+			}
+		case name == ArtifactsFile:
+			if err := decodeJSON(tr, name, &a.Artifacts); err != nil {
+				return nil, err
+			}
+		case name == TestsFile:
+			a.Tests = &preflight.SummaryResult{}
+			if err := decodeJSON(tr, name, a.Tests); err != nil {
+				return nil, err
+			}
+		case path.Dir(name) == LogsDir && path.Ext(name) == ".log":
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", name, err)
+			}
+			a.Logs[strings.TrimSuffix(path.Base(name), ".log")] = string(content)
+		case strings.HasPrefix(name, ArtifactFileDir+"/"):
+			a.ArtifactFiles = append(a.ArtifactFiles, name)
+		}
+	}
+
+	if !seenManifest || !seenBuild {
+		return nil, errors.New("not a build archive: missing manifest.json or build.json")
+	}
+	return a, nil
+}
+
+func decodeJSON(r io.Reader, name string, v any) error {
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("decoding %s: %w", name, err)
+	}
+	return nil
+}
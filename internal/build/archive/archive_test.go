@@ -0,0 +1,105 @@
+package archive
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/cli/v3/internal/preflight"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	artifact := buildkite.Artifact{ID: "a1", Path: "coverage/report.txt"}
+	for _, entry := range []struct {
+		name string
+		v    any
+	}{
+		{ManifestFile, Manifest{FormatVersion: FormatVersion, Organization: "acme", Pipeline: "app", BuildNumber: 7}},
+		{BuildFile, buildkite.Build{ID: "build-uuid", Number: 7}},
+		{AnnotationsFile, []buildkite.Annotation{{Context: "summary"}}},
+		{ArtifactsFile, []buildkite.Artifact{artifact}},
+		{TestsFile, preflight.SummaryResult{Tests: preflight.SummaryTests{Failures: []preflight.SummaryTestFailure{{Name: "TestThing"}}}}},
+	} {
+		if err := w.WriteJSON(entry.name, entry.v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteFile(LogFile("job-1"), []byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteFrom(ArtifactFile(artifact), 5, strings.NewReader("12345")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if a.Manifest.Organization != "acme" || a.Manifest.BuildNumber != 7 {
+		t.Errorf("unexpected manifest %+v", a.Manifest)
+	}
+	if a.Build.ID != "build-uuid" {
+		t.Errorf("unexpected build %+v", a.Build)
+	}
+	if len(a.Annotations) != 1 || len(a.Artifacts) != 1 {
+		t.Errorf("expected an annotation and an artifact, got %d and %d", len(a.Annotations), len(a.Artifacts))
+	}
+	if a.Tests == nil || len(a.Tests.Tests.Failures) != 1 {
+		t.Errorf("expected the test summary, got %+v", a.Tests)
+	}
+	if a.Logs["job-1"] != "hello\n" {
+		t.Errorf("expected the job log, got %q", a.Logs["job-1"])
+	}
+	if len(a.ArtifactFiles) != 1 || a.ArtifactFiles[0] != "artifacts/a1/coverage/report.txt" {
+		t.Errorf("expected the artifact file to be listed, got %v", a.ArtifactFiles)
+	}
+}
+
+func TestArtifactFileStaysInsideArchiveDirectory(t *testing.T) {
+	t.Parallel()
+
+	if got := ArtifactFile(buildkite.Artifact{ID: "a1", Path: "../../etc/passwd"}); got != "artifacts/a1/etc/passwd" {
+		t.Errorf("ArtifactFile() = %q", got)
+	}
+}
+
+func TestReadRejectsNewerFormat(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	_ = w.WriteJSON(ManifestFile, Manifest{FormatVersion: FormatVersion + 1})
+	_ = w.WriteJSON(BuildFile, buildkite.Build{})
+	_ = w.Close()
+
+	if _, err := Read(&buf); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("expected a newer format to be rejected, got %v", err)
+	}
+}
+
+func TestOpenRejectsOtherArchives(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "other.tar.gz")
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	_ = w.WriteFile("README", []byte("not a build"))
+	_ = w.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "not a build archive") {
+		t.Errorf("expected a missing manifest to be rejected, got %v", err)
+	}
+}
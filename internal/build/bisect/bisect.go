@@ -0,0 +1,203 @@
+// Package bisect narrows down the commit that broke a pipeline by building
+// the commits between a good and a bad one.
+package bisect
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// maxCommits caps the commits between good and bad, so that a good commit
+// that isn't an ancestor of bad doesn't walk the whole history.
+const maxCommits = 10000
+
+// Outcome is what a build says about a commit.
+type Outcome int
+
+const (
+	// Skip means the build doesn't say whether the commit is good or bad,
+	// e.g. because it was canceled.
+	Skip Outcome = iota
+	Good
+	Bad
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Good:
+		return "good"
+	case Bad:
+		return "bad"
+	default:
+		return "skipped"
+	}
+}
+
+// Commits returns the commits after good up to and including bad, oldest
+// first, following the first parent of each commit back from bad.
+func Commits(repo *git.Repository, good, bad plumbing.Hash) ([]*object.Commit, error) {
+	if good == bad {
+		return nil, errors.New("the good and bad commits are the same")
+	}
+
+	c, err := repo.CommitObject(bad)
+	if err != nil {
+		return nil, fmt.Errorf("reading commit %s: %w", bad, err)
+	}
+
+	var commits []*object.Commit
+	for c.Hash != good {
+		commits = append(commits, c)
+		if len(commits) > maxCommits {
+			return nil, fmt.Errorf("%s is more than %d commits before %s", good, maxCommits, bad)
+		}
+		if c.NumParents() == 0 {
+			return nil, fmt.Errorf("%s is not an ancestor of %s on its first-parent history", good, bad)
+		}
+		if c, err = c.Parent(0); err != nil {
+			return nil, fmt.Errorf("reading the parent of %s: %w", commits[len(commits)-1].Hash, err)
+		}
+	}
+
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	return commits, nil
+}
+
+// Bisection tracks which commits are known good or bad. The commits are
+// oldest first; the last is bad and the one before the first is good.
+type Bisection struct {
+	commits []string
+	good    int
+	bad     int
+	skipped map[int]bool
+}
+
+// New starts a bisection of commits, which are oldest first and end with
+// the bad commit.
+func New(commits []string) *Bisection {
+	return &Bisection{commits: commits, good: -1, bad: len(commits) - 1, skipped: make(map[int]bool)}
+}
+
+// Next returns the commit to test next: the untested commit nearest the
+// middle of those left. It returns false when there's nothing left to test.
+func (b *Bisection) Next() (string, bool) {
+	mid := (b.good + b.bad) / 2
+	for d := 0; ; d++ {
+		lo, hi := mid-d, mid+d
+		if lo <= b.good && hi >= b.bad {
+			return "", false
+		}
+		if b.untested(lo) {
+			return b.commits[lo], true
+		}
+		if b.untested(hi) {
+			return b.commits[hi], true
+		}
+	}
+}
+
+// untested reports whether the commit at i is between the good and bad ones
+// and hasn't been skipped.
+func (b *Bisection) untested(i int) bool {
+	return i > b.good && i < b.bad && !b.skipped[i]
+}
+
+// Mark records the outcome of testing commit.
+func (b *Bisection) Mark(commit string, outcome Outcome) {
+	for i, c := range b.commits {
+		if c != commit {
+			continue
+		}
+		switch outcome {
+		case Good:
+			b.good = max(b.good, i)
+		case Bad:
+			b.bad = min(b.bad, i)
+		default:
+			b.skipped[i] = true
+		}
+		return
+	}
+}
+
+// Remaining returns the number of commits that could still be the first bad
+// one, and roughly how many more builds it will take to find it.
+func (b *Bisection) Remaining() (commits, steps int) {
+	commits = b.bad - b.good
+	return commits, bits.Len(uint(commits - 1))
+}
+
+// Result returns the commits that could be the first bad one: just one once
+// the bisection is done, or several if skipped commits stopped it narrowing
+// down further.
+func (b *Bisection) Result() []string {
+	return b.commits[b.good+1 : b.bad+1]
+}
+
+// Judge returns what b says about its commit. With a step key, only that
+// step's jobs are considered, and the outcome can be known before the build
+// finishes; otherwise it's the build's own state.
+func Judge(b buildkite.Build, stepKey string) Outcome {
+	if stepKey == "" {
+		switch b.State {
+		case "passed":
+			return Good
+		case "failed":
+			return Bad
+		default:
+			return Skip
+		}
+	}
+
+	found, inconclusive := false, false
+	for _, j := range b.Jobs {
+		if j.StepKey != stepKey || j.Retried {
+			continue
+		}
+		found = true
+		switch j.State {
+		case "failed", "timed_out":
+			return Bad
+		case "passed":
+		default:
+			// The job hasn't finished, or was canceled or skipped, or is
+			// broken: it never ran, e.g. because a step it depends on failed.
+			inconclusive = true
+		}
+	}
+	if !found || inconclusive {
+		return Skip
+	}
+	return Good
+}
+
+// Decided reports whether b has a final outcome for stepKey: the build has
+// finished or, with a step key, all of that step's jobs have.
+func Decided(b buildkite.Build, stepKey string) bool {
+	if b.FinishedAt != nil {
+		return true
+	}
+	if stepKey == "" {
+		return false
+	}
+
+	found := false
+	for _, j := range b.Jobs {
+		if j.StepKey != stepKey || j.Retried {
+			continue
+		}
+		found = true
+		if j.FinishedAt == nil {
+			return false
+		}
+	}
+	return found
+}
@@ -0,0 +1,175 @@
+package bisect
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+func TestCommits(t *testing.T) {
+	t.Parallel()
+
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hashes []plumbing.Hash
+	parent := plumbing.ZeroHash
+	for i := range 5 {
+		c := &object.Commit{
+			Author:    object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(int64(i), 0)},
+			Committer: object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(int64(i), 0)},
+			Message:   string(rune('a' + i)),
+			TreeHash:  plumbing.ZeroHash,
+		}
+		if !parent.IsZero() {
+			c.ParentHashes = []plumbing.Hash{parent}
+		}
+		obj := repo.Storer.NewEncodedObject()
+		if err := c.Encode(obj); err != nil {
+			t.Fatal(err)
+		}
+		if parent, err = repo.Storer.SetEncodedObject(obj); err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, parent)
+	}
+
+	commits, err := Commits(repo, hashes[1], hashes[4])
+	if err != nil {
+		t.Fatalf("Commits() error = %v", err)
+	}
+	var messages []string
+	for _, c := range commits {
+		messages = append(messages, c.Message)
+	}
+	if got := strings.Join(messages, ""); got != "cde" {
+		t.Errorf("Commits() = %q, want cde", got)
+	}
+
+	if _, err := Commits(repo, hashes[4], hashes[1]); err == nil {
+		t.Error("expected an error when good is after bad")
+	}
+	if _, err := Commits(repo, hashes[2], hashes[2]); err == nil {
+		t.Error("expected an error when good and bad are the same")
+	}
+}
+
+func TestBisection(t *testing.T) {
+	t.Parallel()
+
+	commits := []string{"c1", "c2", "c3", "c4", "c5", "c6", "c7", "c8"}
+	firstBad := 5 // c6
+
+	b := New(commits)
+	if n, steps := b.Remaining(); n != 8 || steps != 3 {
+		t.Errorf("Remaining() = %d, %d, want 8, 3", n, steps)
+	}
+
+	var tested []string
+	for {
+		commit, ok := b.Next()
+		if !ok {
+			break
+		}
+		tested = append(tested, commit)
+		outcome := Good
+		for i, c := range commits {
+			if c == commit && i >= firstBad {
+				outcome = Bad
+			}
+		}
+		b.Mark(commit, outcome)
+	}
+
+	if got := strings.Join(tested, ","); got != "c4,c6,c5" {
+		t.Errorf("tested %s, want c4,c6,c5", got)
+	}
+	if got := b.Result(); len(got) != 1 || got[0] != "c6" {
+		t.Errorf("Result() = %v, want [c6]", got)
+	}
+}
+
+func TestBisectionSkips(t *testing.T) {
+	t.Parallel()
+
+	b := New([]string{"c1", "c2", "c3", "c4"})
+	for {
+		commit, ok := b.Next()
+		if !ok {
+			break
+		}
+		if commit == "c1" {
+			b.Mark(commit, Good)
+		} else {
+			b.Mark(commit, Skip)
+		}
+	}
+
+	if got := strings.Join(b.Result(), ","); got != "c2,c3,c4" {
+		t.Errorf("Result() = %s, want c2,c3,c4", got)
+	}
+}
+
+func TestJudge(t *testing.T) {
+	t.Parallel()
+
+	finished := buildkite.NewTimestamp(time.Now())
+	tests := []struct {
+		name        string
+		build       buildkite.Build
+		stepKey     string
+		want        Outcome
+		wantDecided bool
+	}{
+		{"passed build", buildkite.Build{State: "passed", FinishedAt: finished}, "", Good, true},
+		{"failed build", buildkite.Build{State: "failed", FinishedAt: finished}, "", Bad, true},
+		{"canceled build", buildkite.Build{State: "canceled", FinishedAt: finished}, "", Skip, true},
+		{"running build", buildkite.Build{State: "running"}, "", Skip, false},
+		{
+			"step passed while the build runs",
+			buildkite.Build{State: "running", Jobs: []buildkite.Job{
+				{StepKey: "tests", State: "passed", FinishedAt: finished},
+				{StepKey: "deploy", State: "running"},
+			}},
+			"tests", Good, true,
+		},
+		{
+			"step failed, ignoring a retried attempt",
+			buildkite.Build{State: "failed", FinishedAt: finished, Jobs: []buildkite.Job{
+				{StepKey: "tests", State: "passed", Retried: true, FinishedAt: finished},
+				{StepKey: "tests", State: "timed_out", FinishedAt: finished},
+			}},
+			"tests", Bad, true,
+		},
+		{
+			"step broken by an earlier failure",
+			buildkite.Build{State: "failed", FinishedAt: finished, Jobs: []buildkite.Job{
+				{StepKey: "build", State: "failed", FinishedAt: finished},
+				{StepKey: "tests", State: "broken", FinishedAt: finished},
+			}},
+			"tests", Skip, true,
+		},
+		{
+			"step still running",
+			buildkite.Build{State: "running", Jobs: []buildkite.Job{{StepKey: "tests", State: "running"}}},
+			"tests", Skip, false,
+		},
+		{"step missing", buildkite.Build{State: "passed", FinishedAt: finished}, "tests", Skip, true},
+	}
+	for _, tt := range tests {
+		if got := Judge(tt.build, tt.stepKey); got != tt.want {
+			t.Errorf("%s: Judge() = %s, want %s", tt.name, got, tt.want)
+		}
+		if got := Decided(tt.build, tt.stepKey); got != tt.wantDecided {
+			t.Errorf("%s: Decided() = %v, want %v", tt.name, got, tt.wantDecided)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package build
+
+import (
+	"regexp"
+	"strings"
+)
+
+// HasBranchWildcard reports whether any of branches contains a * or ?
+// wildcard.
+func HasBranchWildcard(branches []string) bool {
+	for _, b := range branches {
+		if strings.ContainsAny(b, "*?") {
+			return true
+		}
+	}
+	return false
+}
+
+// BranchPattern returns a regexp matching a branch that matches any of
+// patterns, where * matches any run of characters (including /) and ? any
+// single character. It returns nil if none of patterns has a wildcard, as
+// then the API can do the filtering.
+func BranchPattern(patterns []string) *regexp.Regexp {
+	if !HasBranchWildcard(patterns) {
+		return nil
+	}
+	exprs := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		expr := regexp.QuoteMeta(pattern)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		exprs = append(exprs, expr)
+	}
+	return regexp.MustCompile("^(?:" + strings.Join(exprs, "|") + ")$")
+}
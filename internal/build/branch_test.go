@@ -0,0 +1,27 @@
+package build
+
+import "testing"
+
+func TestBranchPattern(t *testing.T) {
+	t.Parallel()
+
+	if re := BranchPattern([]string{"main", "release"}); re != nil {
+		t.Errorf("BranchPattern() without wildcards = %v, want nil", re)
+	}
+
+	re := BranchPattern([]string{"main", "feature/*", "fix-?", "v1.0"})
+	for branch, want := range map[string]bool{
+		"main":             true,
+		"feature/login":    true,
+		"feature/auth/sso": true,
+		"fix-1":            true,
+		"fix-12":           false,
+		"v1.0":             true,
+		"v1x0":             false,
+		"mainline":         false,
+	} {
+		if got := re.MatchString(branch); got != want {
+			t.Errorf("BranchPattern().MatchString(%q) = %v, want %v", branch, got, want)
+		}
+	}
+}
@@ -1,7 +1,18 @@
 package build
 
+import "fmt"
+
 type Build struct {
 	Organization string
 	Pipeline     string
 	BuildNumber  int
 }
+
+// Label describes b as "org/pipeline #number", leaving out the pipeline when
+// it's the same as other's.
+func (b *Build) Label(other *Build) string {
+	if other != nil && other.Organization == b.Organization && other.Pipeline == b.Pipeline {
+		return fmt.Sprintf("#%d", b.BuildNumber)
+	}
+	return fmt.Sprintf("%s/%s #%d", b.Organization, b.Pipeline, b.BuildNumber)
+}
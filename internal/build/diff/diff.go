@@ -0,0 +1,289 @@
+// Package diff compares two builds: their attributes, environment and
+// meta-data, the jobs they ran and the artifacts they uploaded.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/artifact"
+	"github.com/buildkite/cli/v3/internal/build/timeline"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+const (
+	// minDurationDelta and minDurationShare are how much a job's duration
+	// must change by, in time and as a share of its earlier duration, to be
+	// reported.
+	minDurationDelta = 10 * time.Second
+	minDurationShare = 0.2
+)
+
+// Diff is the difference between two builds. Each list holds only what
+// changed.
+type Diff struct {
+	From      Build         `json:"from"`
+	To        Build         `json:"to"`
+	Fields    []Change      `json:"fields"`
+	Env       []Change      `json:"env"`
+	MetaData  []Change      `json:"meta_data"`
+	Jobs      Jobs          `json:"jobs"`
+	Artifacts artifact.Diff `json:"artifacts"`
+}
+
+// Build is the identity of one of the builds compared.
+type Build struct {
+	Number   int           `json:"number"`
+	State    string        `json:"state"`
+	Branch   string        `json:"branch"`
+	Commit   string        `json:"commit"`
+	WebURL   string        `json:"web_url"`
+	Duration time.Duration `json:"-"`
+}
+
+// MarshalJSON adds the build's duration in seconds.
+func (b Build) MarshalJSON() ([]byte, error) {
+	type build Build
+	return json.Marshal(struct {
+		build
+		DurationSeconds float64 `json:"duration_seconds"`
+	}{build(b), b.Duration.Seconds()})
+}
+
+// Change is a build attribute, environment variable or meta-data key that
+// was "added", "removed" or "changed".
+type Change struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// Jobs is the difference between the jobs of two builds. Jobs are matched
+// by step key (or label, for steps without one) and parallel job index.
+type Jobs struct {
+	Added     []Job       `json:"added"`
+	Removed   []Job       `json:"removed"`
+	Changed   []JobChange `json:"changed"`
+	Unchanged int         `json:"unchanged"`
+}
+
+// Job is a job of one of the builds.
+type Job struct {
+	Key      string        `json:"key"`
+	Label    string        `json:"label"`
+	State    string        `json:"state"`
+	Agent    string        `json:"agent,omitempty"`
+	Queue    string        `json:"queue,omitempty"`
+	Duration time.Duration `json:"-"`
+}
+
+// MarshalJSON adds the job's duration in seconds.
+func (j Job) MarshalJSON() ([]byte, error) {
+	type job Job
+	return json.Marshal(struct {
+		job
+		DurationSeconds float64 `json:"duration_seconds"`
+	}{job(j), j.Duration.Seconds()})
+}
+
+// JobChange is a job in both builds that differs in any of its state,
+// duration, agent or queue, which are listed in Changes.
+type JobChange struct {
+	Key     string   `json:"key"`
+	Label   string   `json:"label"`
+	From    Job      `json:"from"`
+	To      Job      `json:"to"`
+	Changes []string `json:"changes"`
+}
+
+// DurationDelta is how much longer the job took in the later build.
+func (c JobChange) DurationDelta() time.Duration {
+	return c.To.Duration - c.From.Duration
+}
+
+// Has reports whether what ("state", "duration", "agent" or "queue")
+// changed.
+func (c JobChange) Has(what string) bool {
+	return slices.Contains(c.Changes, what)
+}
+
+// Compare returns the difference between builds from and to, and their
+// artifacts. Unfinished builds and jobs are measured up to now.
+func Compare(from, to buildkite.Build, fromArtifacts, toArtifacts []buildkite.Artifact, now time.Time) Diff {
+	d := Diff{
+		From:      newBuild(from, now),
+		To:        newBuild(to, now),
+		Env:       compareValues(stringValues(from.Env), stringValues(to.Env)),
+		MetaData:  compareValues(from.MetaData, to.MetaData),
+		Jobs:      compareJobs(from.Jobs, to.Jobs, now),
+		Artifacts: artifact.Compare(fromArtifacts, toArtifacts),
+	}
+
+	for _, f := range []struct{ name, from, to string }{
+		{"state", from.State, to.State},
+		{"commit", from.Commit, to.Commit},
+		{"branch", from.Branch, to.Branch},
+		{"message", firstLine(from.Message), firstLine(to.Message)},
+		{"duration", timeline.FormatDuration(d.From.Duration), timeline.FormatDuration(d.To.Duration)},
+	} {
+		if f.from != f.to {
+			d.Fields = append(d.Fields, Change{Name: f.name, Kind: "changed", From: f.from, To: f.to})
+		}
+	}
+	return d
+}
+
+// Empty reports whether the builds differ in nothing but their identity.
+func (d Diff) Empty() bool {
+	return len(d.Fields)+len(d.Env)+len(d.MetaData)+
+		len(d.Jobs.Added)+len(d.Jobs.Removed)+len(d.Jobs.Changed)+
+		len(d.Artifacts.Added)+len(d.Artifacts.Removed)+len(d.Artifacts.Changed) == 0
+}
+
+func newBuild(b buildkite.Build, now time.Time) Build {
+	return Build{
+		Number:   b.Number,
+		State:    b.State,
+		Branch:   b.Branch,
+		Commit:   b.Commit,
+		WebURL:   b.WebURL,
+		Duration: elapsed(b.StartedAt, b.FinishedAt, now),
+	}
+}
+
+// elapsed returns the time from started to finished, or to now if it
+// hasn't finished, or zero if it hasn't started.
+func elapsed(started, finished *buildkite.Timestamp, now time.Time) time.Duration {
+	if started == nil {
+		return 0
+	}
+	if finished != nil {
+		return finished.Sub(started.Time)
+	}
+	return now.Sub(started.Time)
+}
+
+func stringValues(env map[string]any) map[string]string {
+	values := make(map[string]string, len(env))
+	for k, v := range env {
+		values[k] = fmt.Sprint(v)
+	}
+	return values
+}
+
+// compareValues returns the keys added, removed or changed between from and
+// to, sorted by key.
+func compareValues(from, to map[string]string) []Change {
+	var changes []Change
+	for k, v := range from {
+		n, ok := to[k]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Name: k, Kind: "removed", From: v})
+		case n != v:
+			changes = append(changes, Change{Name: k, Kind: "changed", From: v, To: n})
+		}
+	}
+	for k, v := range to {
+		if _, ok := from[k]; !ok {
+			changes = append(changes, Change{Name: k, Kind: "added", To: v})
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int { return strings.Compare(a.Name, b.Name) })
+	return changes
+}
+
+func compareJobs(from, to []buildkite.Job, now time.Time) Jobs {
+	fromJobs := newJobs(from, now)
+	toJobs := newJobs(to, now)
+
+	byKey := make(map[string]Job, len(fromJobs))
+	for _, j := range fromJobs {
+		byKey[j.Key] = j
+	}
+
+	var d Jobs
+	matched := make(map[string]bool, len(toJobs))
+	for _, n := range toJobs {
+		o, ok := byKey[n.Key]
+		if !ok {
+			d.Added = append(d.Added, n)
+			continue
+		}
+		matched[n.Key] = true
+		if changes := jobChanges(o, n); len(changes) > 0 {
+			d.Changed = append(d.Changed, JobChange{Key: n.Key, Label: n.Label, From: o, To: n, Changes: changes})
+		} else {
+			d.Unchanged++
+		}
+	}
+	for _, o := range fromJobs {
+		if !matched[o.Key] {
+			d.Removed = append(d.Removed, o)
+		}
+	}
+	return d
+}
+
+func jobChanges(from, to Job) []string {
+	var changes []string
+	if from.State != to.State {
+		changes = append(changes, "state")
+	}
+	delta := to.Duration - from.Duration
+	if delta.Abs() >= minDurationDelta && delta.Abs().Seconds() >= minDurationShare*from.Duration.Seconds() {
+		changes = append(changes, "duration")
+	}
+	if from.Agent != to.Agent {
+		changes = append(changes, "agent")
+	}
+	if from.Queue != to.Queue {
+		changes = append(changes, "queue")
+	}
+	return changes
+}
+
+// newJobs returns the jobs that ran, leaving out wait steps and jobs that
+// were retried, keyed by step and parallel index. When several jobs share a
+// key, the later ones are labelled "key [2]", "key [3]" and so on.
+func newJobs(jobs []buildkite.Job, now time.Time) []Job {
+	var out []Job
+	seen := make(map[string]int)
+	for _, j := range jobs {
+		if j.Type == "waiter" || j.Retried {
+			continue
+		}
+
+		label := timeline.JobLabel(j)
+		key := j.StepKey
+		if key == "" {
+			key = label
+		}
+		if j.ParallelGroupIndex != nil {
+			key = fmt.Sprintf("%s #%d", key, *j.ParallelGroupIndex)
+		}
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			key = fmt.Sprintf("%s [%d]", key, n)
+		}
+
+		out = append(out, Job{
+			Key:      key,
+			Label:    label,
+			State:    j.State,
+			Agent:    j.Agent.Name,
+			Queue:    timeline.JobQueue(j.AgentQueryRules),
+			Duration: elapsed(j.StartedAt, j.FinishedAt, now),
+		})
+	}
+	return out
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
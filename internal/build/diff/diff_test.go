@@ -0,0 +1,223 @@
+package diff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+var start = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func ts(d time.Duration) *buildkite.Timestamp {
+	return buildkite.NewTimestamp(start.Add(d))
+}
+
+func job(key, label, state, agent, queue string, run time.Duration) buildkite.Job {
+	return buildkite.Job{
+		Type:            "script",
+		StepKey:         key,
+		Label:           label,
+		State:           state,
+		Agent:           buildkite.Agent{Name: agent},
+		AgentQueryRules: []string{"queue=" + queue},
+		StartedAt:       ts(0),
+		FinishedAt:      ts(run),
+	}
+}
+
+func testBuilds() (buildkite.Build, buildkite.Build) {
+	from := buildkite.Build{
+		Number:     428,
+		State:      "passed",
+		Branch:     "main",
+		Commit:     "aaaaaaaaaaaa",
+		Message:    "Fix the thing\n\nLonger description",
+		Env:        map[string]any{"CI": "true", "OLD": "1", "LEVEL": "debug"},
+		MetaData:   map[string]string{"release": "1.0"},
+		StartedAt:  ts(0),
+		FinishedAt: ts(5 * time.Minute),
+		Jobs: []buildkite.Job{
+			job("lint", "Lint", "passed", "agent-1", "default", time.Minute),
+			{Type: "waiter"},
+			job("tests", "Tests", "passed", "agent-2", "default", 2*time.Minute),
+			job("image", "Build image", "passed", "agent-3", "docker", time.Minute),
+			job("docs", "Docs", "passed", "agent-4", "default", 30*time.Second),
+			job("deploy", "Deploy", "passed", "agent-5", "deploy", time.Minute),
+		},
+	}
+	to := buildkite.Build{
+		Number:     431,
+		State:      "failed",
+		Branch:     "main",
+		Commit:     "bbbbbbbbbbbb",
+		Message:    "Fix the thing",
+		Env:        map[string]any{"CI": "true", "LEVEL": "info", "NEW": "2"},
+		MetaData:   map[string]string{"release": "1.0"},
+		StartedAt:  ts(0),
+		FinishedAt: ts(7 * time.Minute),
+		Jobs: []buildkite.Job{
+			job("lint", "Lint", "passed", "agent-1", "default", 65*time.Second),
+			{Type: "waiter"},
+			{Type: "script", StepKey: "tests", Label: "Tests", State: "passed", Retried: true},
+			job("tests", "Tests", "failed", "agent-9", "default", 4*time.Minute),
+			job("image", "Build image", "passed", "agent-3", "docker-large", time.Minute),
+			job("docs", "Docs", "passed", "agent-7", "default", 30*time.Second),
+			job("audit", "Audit", "passed", "agent-6", "default", 10*time.Second),
+		},
+	}
+	return from, to
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	from, to := testBuilds()
+	artifactsFrom := []buildkite.Artifact{{Path: "coverage.xml", FileSize: 10, SHA1: "aa"}, {Path: "old.log", FileSize: 5}}
+	artifactsTo := []buildkite.Artifact{{Path: "coverage.xml", FileSize: 12, SHA1: "bb"}, {Path: "new.log", FileSize: 7}}
+
+	d := Compare(from, to, artifactsFrom, artifactsTo, start.Add(time.Hour))
+
+	var fields []string
+	for _, c := range d.Fields {
+		fields = append(fields, c.Name+":"+c.From+"->"+c.To)
+	}
+	if got, want := strings.Join(fields, " "), "state:passed->failed commit:aaaaaaaaaaaa->bbbbbbbbbbbb duration:5m0s->7m0s"; got != want {
+		t.Errorf("Fields = %s, want %s", got, want)
+	}
+
+	var env []string
+	for _, c := range d.Env {
+		env = append(env, c.Kind+":"+c.Name)
+	}
+	if got, want := strings.Join(env, " "), "changed:LEVEL added:NEW removed:OLD"; got != want {
+		t.Errorf("Env = %s, want %s", got, want)
+	}
+	if len(d.MetaData) != 0 {
+		t.Errorf("MetaData = %+v, want no changes", d.MetaData)
+	}
+
+	if len(d.Jobs.Added) != 1 || d.Jobs.Added[0].Key != "audit" {
+		t.Errorf("Jobs.Added = %+v, want audit", d.Jobs.Added)
+	}
+	if len(d.Jobs.Removed) != 1 || d.Jobs.Removed[0].Key != "deploy" {
+		t.Errorf("Jobs.Removed = %+v, want deploy", d.Jobs.Removed)
+	}
+	if d.Jobs.Unchanged != 1 {
+		t.Errorf("Jobs.Unchanged = %d, want 1 (lint, whose duration barely changed)", d.Jobs.Unchanged)
+	}
+
+	var changed []string
+	for _, c := range d.Jobs.Changed {
+		changed = append(changed, c.Key+":"+strings.Join(c.Changes, ","))
+	}
+	if got, want := strings.Join(changed, " "), "tests:state,duration,agent image:queue docs:agent"; got != want {
+		t.Errorf("Jobs.Changed = %s, want %s", got, want)
+	}
+	if delta := d.Jobs.Changed[0].DurationDelta(); delta != 2*time.Minute {
+		t.Errorf("DurationDelta() = %s, want 2m", delta)
+	}
+
+	if len(d.Artifacts.Added) != 1 || len(d.Artifacts.Removed) != 1 || len(d.Artifacts.Changed) != 1 {
+		t.Errorf("Artifacts = %+v, want one added, removed and changed", d.Artifacts)
+	}
+}
+
+func TestCompareParallelJobs(t *testing.T) {
+	t.Parallel()
+
+	parallel := func(i int, state string) buildkite.Job {
+		j := job("specs", "Specs", state, "agent", "default", time.Minute)
+		j.ParallelGroupIndex = &i
+		return j
+	}
+	from := buildkite.Build{Jobs: []buildkite.Job{parallel(0, "passed"), parallel(1, "passed")}}
+	to := buildkite.Build{Jobs: []buildkite.Job{parallel(0, "passed"), parallel(1, "failed")}}
+
+	d := Compare(from, to, nil, nil, start)
+	if len(d.Jobs.Changed) != 1 || d.Jobs.Changed[0].Key != "specs #1" {
+		t.Errorf("Jobs.Changed = %+v, want specs #1", d.Jobs.Changed)
+	}
+	if d.Jobs.Unchanged != 1 {
+		t.Errorf("Jobs.Unchanged = %d, want 1", d.Jobs.Unchanged)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	from, to := testBuilds()
+	d := Compare(from, to, nil, []buildkite.Artifact{{Path: "report.html", FileSize: 2048}}, start)
+
+	var out strings.Builder
+	if err := Write(&out, d); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+
+	for _, want := range []string{
+		"\nBuild:\n  state     passed -> failed\n  commit    aaaaaaa -> bbbbbbb\n  duration  5m0s -> 7m0s\n",
+		"\nEnvironment:\n  ~ LEVEL: debug -> info\n  + NEW=2\n  - OLD=1\n",
+		"\nJobs: 1 added, 1 removed, 3 changed, 1 unchanged\n  + Audit (passed)\n  - Deploy (passed)\n",
+		"2m0s -> 4m0s (+2m0s)",
+		"agent-2 -> agent-9",
+		"docker -> docker-large",
+		"\n1 other job ran on a different agent.\n",
+		"\nArtifacts: 1 added, 0 removed, 0 changed, 0 unchanged\n  + report.html (2.0KB)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Write() =\n%s\nwant it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "Meta-data") {
+		t.Errorf("Write() =\n%s\nwant no meta-data section", got)
+	}
+}
+
+func TestWriteNoDifferences(t *testing.T) {
+	t.Parallel()
+
+	from, _ := testBuilds()
+	var out strings.Builder
+	if err := Write(&out, Compare(from, from, nil, nil, start)); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "\nNo differences.\n" {
+		t.Errorf("Write() = %q, want no differences", got)
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	t.Parallel()
+
+	from, to := testBuilds()
+	data, err := json.Marshal(Compare(from, to, nil, nil, start))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		To struct {
+			DurationSeconds float64 `json:"duration_seconds"`
+		} `json:"to"`
+		Jobs struct {
+			Changed []struct {
+				Key  string `json:"key"`
+				From struct {
+					DurationSeconds float64 `json:"duration_seconds"`
+				} `json:"from"`
+			} `json:"changed"`
+		} `json:"jobs"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.To.DurationSeconds != 420 {
+		t.Errorf("to.duration_seconds = %v, want 420", decoded.To.DurationSeconds)
+	}
+	if len(decoded.Jobs.Changed) == 0 || decoded.Jobs.Changed[0].From.DurationSeconds != 120 {
+		t.Errorf("jobs.changed = %+v, want tests with from.duration_seconds 120", decoded.Jobs.Changed)
+	}
+}
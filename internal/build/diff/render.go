@@ -0,0 +1,134 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/buildkite/cli/v3/internal/artifact"
+	"github.com/buildkite/cli/v3/internal/build/timeline"
+	"github.com/buildkite/cli/v3/internal/emoji"
+	bkIO "github.com/buildkite/cli/v3/internal/io"
+	"github.com/buildkite/cli/v3/pkg/output"
+)
+
+// Write writes d as text, section by section, each after a blank line,
+// leaving out sections with no differences. Jobs that only ran on a
+// different agent are counted rather than listed, since that's usually the
+// case for most of a build's jobs.
+func Write(w io.Writer, d Diff) error {
+	if d.Empty() {
+		fmt.Fprintln(w, "\nNo differences.")
+		return nil
+	}
+
+	if len(d.Fields) > 0 {
+		fmt.Fprintln(w, "\nBuild:")
+		for _, c := range d.Fields {
+			from, to := c.From, c.To
+			if c.Name == "commit" {
+				from, to = shortSHA(from), shortSHA(to)
+			}
+			fmt.Fprintf(w, "  %-9s %s -> %s\n", c.Name, output.ValueOrDash(from), output.ValueOrDash(to))
+		}
+	}
+	writeValues(w, "Environment", d.Env)
+	writeValues(w, "Meta-data", d.MetaData)
+
+	jobs := d.Jobs
+	if len(jobs.Added)+len(jobs.Removed)+len(jobs.Changed) > 0 {
+		fmt.Fprintf(w, "\nJobs: %d added, %d removed, %d changed, %d unchanged\n",
+			len(jobs.Added), len(jobs.Removed), len(jobs.Changed), jobs.Unchanged)
+		for _, j := range jobs.Added {
+			fmt.Fprintf(w, "  + %s (%s)\n", emoji.Render(j.Label), j.State)
+		}
+		for _, j := range jobs.Removed {
+			fmt.Fprintf(w, "  - %s (%s)\n", emoji.Render(j.Label), j.State)
+		}
+
+		var rows [][]string
+		agentOnly := 0
+		for _, c := range jobs.Changed {
+			if len(c.Changes) == 1 && c.Has("agent") {
+				agentOnly++
+				continue
+			}
+			rows = append(rows, []string{
+				emoji.Render(c.Label),
+				changed(c.Has("state"), c.From.State, c.To.State),
+				durationChange(c),
+				changed(c.Has("agent"), c.From.Agent, c.To.Agent),
+				changed(c.Has("queue"), c.From.Queue, c.To.Queue),
+			})
+		}
+		if len(rows) > 0 {
+			fmt.Fprintln(w)
+			fmt.Fprint(w, output.Table([]string{"Job", "State", "Duration", "Agent", "Queue"}, rows, map[string]string{
+				"job":   "bold",
+				"state": "bold",
+			}))
+		}
+		if agentOnly > 0 {
+			fmt.Fprintf(w, "\n%d other %s ran on a different agent.\n", agentOnly, bkIO.Pluralize("job", agentOnly))
+		}
+	}
+
+	a := d.Artifacts
+	if len(a.Added)+len(a.Removed)+len(a.Changed) > 0 {
+		fmt.Fprintf(w, "\nArtifacts: %d added, %d removed, %d changed, %d unchanged\n",
+			len(a.Added), len(a.Removed), len(a.Changed), a.Unchanged)
+		for _, c := range a.Added {
+			fmt.Fprintf(w, "  + %s (%s)\n", c.Path, artifact.FormatBytes(c.New.FileSize))
+		}
+		for _, c := range a.Removed {
+			fmt.Fprintf(w, "  - %s (%s)\n", c.Path, artifact.FormatBytes(c.Old.FileSize))
+		}
+		for _, c := range a.Changed {
+			fmt.Fprintf(w, "  ~ %s (%s -> %s)\n", c.Path, artifact.FormatBytes(c.Old.FileSize), artifact.FormatBytes(c.New.FileSize))
+		}
+	}
+	return nil
+}
+
+func writeValues(w io.Writer, title string, changes []Change) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s:\n", title)
+	for _, c := range changes {
+		switch c.Kind {
+		case "added":
+			fmt.Fprintf(w, "  + %s=%s\n", c.Name, c.To)
+		case "removed":
+			fmt.Fprintf(w, "  - %s=%s\n", c.Name, c.From)
+		default:
+			fmt.Fprintf(w, "  ~ %s: %s -> %s\n", c.Name, c.From, c.To)
+		}
+	}
+}
+
+// changed returns "from -> to" if the value changed, or a dash.
+func changed(ok bool, from, to string) string {
+	if !ok {
+		return "-"
+	}
+	return output.ValueOrDash(from) + " -> " + output.ValueOrDash(to)
+}
+
+func durationChange(c JobChange) string {
+	if !c.Has("duration") {
+		return "-"
+	}
+	delta := c.DurationDelta()
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s -> %s (%s%s)", timeline.FormatDuration(c.From.Duration), timeline.FormatDuration(c.To.Duration), sign, timeline.FormatDuration(delta.Abs()))
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
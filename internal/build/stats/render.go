@@ -0,0 +1,172 @@
+package stats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/build/timeline"
+	"github.com/buildkite/cli/v3/internal/emoji"
+	"github.com/buildkite/cli/v3/pkg/output"
+)
+
+var percentileHeaders = []string{"", "P50", "P90", "P99"}
+
+// WriteBuilds writes s as text: a summary, a table of duration and queue
+// wait percentiles, the most failing steps and the trend by period.
+func WriteBuilds(w io.Writer, s BuildStats, by Period) error {
+	if s.Builds == 0 {
+		fmt.Fprintln(w, "No builds found.")
+		return nil
+	}
+
+	fmt.Fprintf(w, "Builds: %d (%d passed, %d failed, %d other)\n", s.Builds, s.Passed, s.Failed, s.Other)
+	fmt.Fprintf(w, "Pass rate: %s\n\n", formatRate(s.PassRate))
+	fmt.Fprint(w, output.Table(percentileHeaders, [][]string{
+		percentileRow("Build duration", s.Duration),
+		percentileRow("Queue wait", s.QueueWait),
+	}, nil))
+
+	if len(s.FailingSteps) > 0 {
+		rows := make([][]string, 0, len(s.FailingSteps))
+		for _, f := range s.FailingSteps {
+			rows = append(rows, []string{emoji.Render(f.Step), strconv.Itoa(f.Failures), strconv.Itoa(f.Jobs), formatRate(f.FailRate)})
+		}
+		fmt.Fprintln(w, "\nMost failing steps:")
+		fmt.Fprint(w, output.Table([]string{"Step", "Failures", "Jobs", "Fail rate"}, rows, map[string]string{"step": "bold"}))
+	}
+
+	rows := make([][]string, 0, len(s.Trend))
+	for _, p := range s.Trend {
+		rows = append(rows, []string{
+			formatPeriod(p.Start),
+			strconv.Itoa(p.Builds),
+			strconv.Itoa(p.Passed),
+			strconv.Itoa(p.Failed),
+			formatRate(p.PassRate),
+			formatDuration(p.Duration.P50),
+			formatDuration(p.Duration.P90),
+		})
+	}
+	fmt.Fprintf(w, "\nTrend by %s:\n", by)
+	fmt.Fprint(w, output.Table([]string{periodHeader(by), "Builds", "Passed", "Failed", "Pass rate", "P50", "P90"}, rows, nil))
+	return nil
+}
+
+// WriteJobs writes s as text: a summary, a table of duration and queue wait
+// percentiles, each step's jobs and the trend by period.
+func WriteJobs(w io.Writer, s JobStats, by Period) error {
+	if s.Jobs == 0 {
+		fmt.Fprintln(w, "No jobs found.")
+		return nil
+	}
+
+	fmt.Fprintf(w, "Jobs: %d (%d passed, %d failed)\n", s.Jobs, s.Passed, s.Failed)
+	fmt.Fprintf(w, "Fail rate: %s\n\n", formatRate(s.FailRate))
+	fmt.Fprint(w, output.Table(percentileHeaders, [][]string{
+		percentileRow("Job duration", s.Duration),
+		percentileRow("Queue wait", s.QueueWait),
+	}, nil))
+
+	rows := make([][]string, 0, len(s.Steps))
+	for _, st := range s.Steps {
+		rows = append(rows, []string{
+			emoji.Render(st.Step),
+			strconv.Itoa(st.Jobs),
+			strconv.Itoa(st.Failed),
+			formatRate(st.FailRate),
+			formatDuration(st.Duration.P50),
+			formatDuration(st.Duration.P90),
+			formatDuration(st.QueueWait.P90),
+		})
+	}
+	fmt.Fprintln(w, "\nSteps:")
+	fmt.Fprint(w, output.Table([]string{"Step", "Jobs", "Failed", "Fail rate", "P50", "P90", "Wait P90"}, rows, map[string]string{"step": "bold"}))
+
+	rows = make([][]string, 0, len(s.Trend))
+	for _, p := range s.Trend {
+		rows = append(rows, []string{
+			formatPeriod(p.Start),
+			strconv.Itoa(p.Jobs),
+			strconv.Itoa(p.Failed),
+			formatRate(p.FailRate),
+			formatDuration(p.Duration.P50),
+			formatDuration(p.Duration.P90),
+			formatDuration(p.QueueWait.P90),
+		})
+	}
+	fmt.Fprintf(w, "\nTrend by %s:\n", by)
+	fmt.Fprint(w, output.Table([]string{periodHeader(by), "Jobs", "Failed", "Fail rate", "P50", "P90", "Wait P90"}, rows, nil))
+	return nil
+}
+
+// WriteBuildsCSV writes the trend of s as CSV, one row per period, with
+// durations in seconds.
+func WriteBuildsCSV(w io.Writer, s BuildStats) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"period", "builds", "passed", "failed", "pass_rate", "duration_p50", "duration_p90", "duration_p99"})
+	for _, p := range s.Trend {
+		_ = cw.Write(append([]string{
+			formatPeriod(p.Start),
+			strconv.Itoa(p.Builds),
+			strconv.Itoa(p.Passed),
+			strconv.Itoa(p.Failed),
+			csvFloat(p.PassRate),
+		}, csvPercentiles(p.Duration)...))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJobsCSV writes the steps of s as CSV, one row per step, with
+// durations in seconds.
+func WriteJobsCSV(w io.Writer, s JobStats) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"step", "jobs", "passed", "failed", "fail_rate", "duration_p50", "duration_p90", "duration_p99", "queue_wait_p50", "queue_wait_p90", "queue_wait_p99"})
+	for _, st := range s.Steps {
+		row := []string{st.Step, strconv.Itoa(st.Jobs), strconv.Itoa(st.Passed), strconv.Itoa(st.Failed), csvFloat(st.FailRate)}
+		row = append(row, csvPercentiles(st.Duration)...)
+		_ = cw.Write(append(row, csvPercentiles(st.QueueWait)...))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func percentileRow(name string, p Percentiles) []string {
+	return []string{name, formatDuration(p.P50), formatDuration(p.P90), formatDuration(p.P99)}
+}
+
+func csvPercentiles(p Percentiles) []string {
+	return []string{csvFloat(p.P50.Seconds()), csvFloat(p.P90.Seconds()), csvFloat(p.P99.Seconds())}
+}
+
+func csvFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func periodHeader(by Period) string {
+	if by == Week {
+		return "Week of"
+	}
+	return "Day"
+}
+
+func formatPeriod(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+// formatRate formats a rate as a whole percentage.
+func formatRate(r float64) string {
+	return fmt.Sprintf("%d%%", int(math.Round(100*r)))
+}
+
+// formatDuration formats d, or a dash if there were no durations to measure.
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return timeline.FormatDuration(d)
+}
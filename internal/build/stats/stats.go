@@ -0,0 +1,348 @@
+// Package stats aggregates builds and jobs into pass rates, duration and
+// queue wait percentiles, failing steps and trends over time.
+package stats
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/build/timeline"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+// maxFailingSteps caps the failing steps reported for builds.
+const maxFailingSteps = 10
+
+// Period is how trends are bucketed: "day" or "week".
+type Period string
+
+const (
+	Day  Period = "day"
+	Week Period = "week"
+)
+
+// Start returns the start of the period containing t, in UTC. Weeks start
+// on Monday.
+func (p Period) Start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if p == Week {
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+	return day
+}
+
+// Percentiles are the 50th, 90th and 99th percentiles of a set of durations.
+type Percentiles struct {
+	P50 time.Duration `json:"-" yaml:"-"`
+	P90 time.Duration `json:"-" yaml:"-"`
+	P99 time.Duration `json:"-" yaml:"-"`
+}
+
+// MarshalJSON writes the percentiles in seconds.
+func (p Percentiles) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.seconds())
+}
+
+// MarshalYAML writes the percentiles in seconds, as MarshalJSON does.
+func (p Percentiles) MarshalYAML() (any, error) {
+	return p.seconds(), nil
+}
+
+func (p Percentiles) seconds() any {
+	return struct {
+		P50 float64 `json:"p50_seconds" yaml:"p50_seconds"`
+		P90 float64 `json:"p90_seconds" yaml:"p90_seconds"`
+		P99 float64 `json:"p99_seconds" yaml:"p99_seconds"`
+	}{p.P50.Seconds(), p.P90.Seconds(), p.P99.Seconds()}
+}
+
+// percentiles returns the nearest-rank percentiles of durations, or zero if
+// there are none.
+func percentiles(durations []time.Duration) Percentiles {
+	if len(durations) == 0 {
+		return Percentiles{}
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	rank := func(p float64) time.Duration {
+		i := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return sorted[max(i, 0)]
+	}
+	return Percentiles{P50: rank(0.5), P90: rank(0.9), P99: rank(0.99)}
+}
+
+// rate returns n as a share of total, or zero if total is zero.
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// BuildStats summarises a set of builds. The pass rate is of builds that
+// passed or failed; builds in other states, such as canceled ones, are
+// counted in Other. Durations are of finished builds, and queue waits of
+// their command jobs, from becoming runnable to starting on an agent.
+type BuildStats struct {
+	Builds       int           `json:"builds" yaml:"builds"`
+	Passed       int           `json:"passed" yaml:"passed"`
+	Failed       int           `json:"failed" yaml:"failed"`
+	Other        int           `json:"other" yaml:"other"`
+	PassRate     float64       `json:"pass_rate" yaml:"pass_rate"`
+	Duration     Percentiles   `json:"duration" yaml:"duration"`
+	QueueWait    Percentiles   `json:"queue_wait" yaml:"queue_wait"`
+	FailingSteps []StepFailure `json:"failing_steps" yaml:"failing_steps"`
+	Trend        []BuildPeriod `json:"trend" yaml:"trend"`
+}
+
+// StepFailure is how often a step's jobs failed.
+type StepFailure struct {
+	Step     string  `json:"step" yaml:"step"`
+	Failures int     `json:"failures" yaml:"failures"`
+	Jobs     int     `json:"jobs" yaml:"jobs"`
+	FailRate float64 `json:"fail_rate" yaml:"fail_rate"`
+}
+
+// BuildPeriod is the builds created in one day or week.
+type BuildPeriod struct {
+	Start    time.Time   `json:"start" yaml:"start"`
+	Builds   int         `json:"builds" yaml:"builds"`
+	Passed   int         `json:"passed" yaml:"passed"`
+	Failed   int         `json:"failed" yaml:"failed"`
+	PassRate float64     `json:"pass_rate" yaml:"pass_rate"`
+	Duration Percentiles `json:"duration" yaml:"duration"`
+}
+
+// Builds aggregates builds, with a trend by period of their creation.
+func Builds(builds []buildkite.Build, by Period) BuildStats {
+	s := BuildStats{Builds: len(builds), FailingSteps: []StepFailure{}, Trend: []BuildPeriod{}}
+
+	var durations, waits []time.Duration
+	periods := make(map[time.Time]*BuildPeriod)
+	periodDurations := make(map[time.Time][]time.Duration)
+	var jobs []buildkite.Job
+
+	for _, b := range builds {
+		var p *BuildPeriod
+		if b.CreatedAt != nil {
+			start := by.Start(b.CreatedAt.Time)
+			if p = periods[start]; p == nil {
+				p = &BuildPeriod{Start: start}
+				periods[start] = p
+			}
+			p.Builds++
+		}
+
+		switch b.State {
+		case "passed":
+			s.Passed++
+			if p != nil {
+				p.Passed++
+			}
+		case "failed":
+			s.Failed++
+			if p != nil {
+				p.Failed++
+			}
+		default:
+			s.Other++
+		}
+
+		if b.StartedAt != nil && b.FinishedAt != nil {
+			d := b.FinishedAt.Sub(b.StartedAt.Time)
+			durations = append(durations, d)
+			if p != nil {
+				periodDurations[p.Start] = append(periodDurations[p.Start], d)
+			}
+		}
+
+		for _, j := range b.Jobs {
+			if w, ok := queueWait(j); ok {
+				waits = append(waits, w)
+			}
+		}
+		jobs = append(jobs, b.Jobs...)
+	}
+
+	s.PassRate = rate(s.Passed, s.Passed+s.Failed)
+	s.Duration = percentiles(durations)
+	s.QueueWait = percentiles(waits)
+
+	for _, step := range Jobs(jobs, by).Steps {
+		if step.Failed == 0 {
+			continue
+		}
+		s.FailingSteps = append(s.FailingSteps, StepFailure{Step: step.Step, Failures: step.Failed, Jobs: step.Jobs, FailRate: step.FailRate})
+	}
+	sort.SliceStable(s.FailingSteps, func(i, j int) bool { return s.FailingSteps[i].Failures > s.FailingSteps[j].Failures })
+	if len(s.FailingSteps) > maxFailingSteps {
+		s.FailingSteps = s.FailingSteps[:maxFailingSteps]
+	}
+
+	for _, p := range periods {
+		p.PassRate = rate(p.Passed, p.Passed+p.Failed)
+		p.Duration = percentiles(periodDurations[p.Start])
+		s.Trend = append(s.Trend, *p)
+	}
+	slices.SortFunc(s.Trend, func(a, b BuildPeriod) int { return a.Start.Compare(b.Start) })
+	return s
+}
+
+// JobStats summarises the command jobs of a set of builds, overall, by step
+// and by period. The fail rate is of jobs that passed or failed; a job that
+// soft failed counts as passed.
+type JobStats struct {
+	Jobs      int         `json:"jobs" yaml:"jobs"`
+	Passed    int         `json:"passed" yaml:"passed"`
+	Failed    int         `json:"failed" yaml:"failed"`
+	FailRate  float64     `json:"fail_rate" yaml:"fail_rate"`
+	Duration  Percentiles `json:"duration" yaml:"duration"`
+	QueueWait Percentiles `json:"queue_wait" yaml:"queue_wait"`
+	Steps     []StepStats `json:"steps" yaml:"steps"`
+	Trend     []JobPeriod `json:"trend" yaml:"trend"`
+}
+
+// StepStats summarises the jobs of one step, including retries and
+// parallel jobs.
+type StepStats struct {
+	Step      string      `json:"step" yaml:"step"`
+	Jobs      int         `json:"jobs" yaml:"jobs"`
+	Passed    int         `json:"passed" yaml:"passed"`
+	Failed    int         `json:"failed" yaml:"failed"`
+	FailRate  float64     `json:"fail_rate" yaml:"fail_rate"`
+	Duration  Percentiles `json:"duration" yaml:"duration"`
+	QueueWait Percentiles `json:"queue_wait" yaml:"queue_wait"`
+}
+
+// JobPeriod is the jobs created in one day or week.
+type JobPeriod struct {
+	Start     time.Time   `json:"start" yaml:"start"`
+	Jobs      int         `json:"jobs" yaml:"jobs"`
+	Failed    int         `json:"failed" yaml:"failed"`
+	FailRate  float64     `json:"fail_rate" yaml:"fail_rate"`
+	Duration  Percentiles `json:"duration" yaml:"duration"`
+	QueueWait Percentiles `json:"queue_wait" yaml:"queue_wait"`
+}
+
+// jobGroup collects the jobs of a step or period.
+type jobGroup struct {
+	jobs, passed, failed int
+	durations, waits     []time.Duration
+}
+
+func (g *jobGroup) add(j buildkite.Job) {
+	g.jobs++
+	switch {
+	case jobFailed(j):
+		g.failed++
+	case j.State == "passed" || j.SoftFailed:
+		g.passed++
+	}
+	if j.StartedAt != nil && j.FinishedAt != nil {
+		g.durations = append(g.durations, j.FinishedAt.Sub(j.StartedAt.Time))
+	}
+	if w, ok := queueWait(j); ok {
+		g.waits = append(g.waits, w)
+	}
+}
+
+func (g *jobGroup) failRate() float64 {
+	return rate(g.failed, g.passed+g.failed)
+}
+
+// Jobs aggregates the command jobs among jobs. Steps are listed by their
+// number of failures, then their number of jobs.
+func Jobs(jobs []buildkite.Job, by Period) JobStats {
+	var all jobGroup
+	steps := make(map[string]*jobGroup)
+	labels := make(map[string]string)
+	var order []string
+	periods := make(map[time.Time]*jobGroup)
+
+	for _, j := range jobs {
+		if j.Type != "script" {
+			continue
+		}
+		all.add(j)
+
+		key := timeline.StepKey(j)
+		if steps[key] == nil {
+			steps[key] = &jobGroup{}
+			labels[key] = timeline.JobLabel(j)
+			order = append(order, key)
+		}
+		steps[key].add(j)
+
+		if j.CreatedAt != nil {
+			start := by.Start(j.CreatedAt.Time)
+			if periods[start] == nil {
+				periods[start] = &jobGroup{}
+			}
+			periods[start].add(j)
+		}
+	}
+
+	s := JobStats{
+		Jobs:      all.jobs,
+		Passed:    all.passed,
+		Failed:    all.failed,
+		FailRate:  all.failRate(),
+		Duration:  percentiles(all.durations),
+		QueueWait: percentiles(all.waits),
+		Steps:     []StepStats{},
+		Trend:     []JobPeriod{},
+	}
+
+	for _, key := range order {
+		g := steps[key]
+		s.Steps = append(s.Steps, StepStats{
+			Step:      labels[key],
+			Jobs:      g.jobs,
+			Passed:    g.passed,
+			Failed:    g.failed,
+			FailRate:  g.failRate(),
+			Duration:  percentiles(g.durations),
+			QueueWait: percentiles(g.waits),
+		})
+	}
+	sort.SliceStable(s.Steps, func(i, j int) bool {
+		if s.Steps[i].Failed != s.Steps[j].Failed {
+			return s.Steps[i].Failed > s.Steps[j].Failed
+		}
+		return s.Steps[i].Jobs > s.Steps[j].Jobs
+	})
+
+	for start, g := range periods {
+		s.Trend = append(s.Trend, JobPeriod{
+			Start:     start,
+			Jobs:      g.jobs,
+			Failed:    g.failed,
+			FailRate:  g.failRate(),
+			Duration:  percentiles(g.durations),
+			QueueWait: percentiles(g.waits),
+		})
+	}
+	slices.SortFunc(s.Trend, func(a, b JobPeriod) int { return a.Start.Compare(b.Start) })
+	return s
+}
+
+func jobFailed(j buildkite.Job) bool {
+	return !j.SoftFailed && (j.State == "failed" || j.State == "timed_out")
+}
+
+// queueWait returns how long a command job waited for an agent, from being
+// ready for one to starting.
+func queueWait(j buildkite.Job) (time.Duration, bool) {
+	if j.Type != "script" || j.StartedAt == nil {
+		return 0, false
+	}
+	ready := timeline.ReadyAt(j)
+	if ready == nil {
+		return 0, false
+	}
+	return max(j.StartedAt.Sub(*ready), 0), true
+}
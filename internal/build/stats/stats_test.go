@@ -0,0 +1,274 @@
+package stats
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// monday is the start of a week.
+var monday = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+func at(d time.Duration) *buildkite.Timestamp {
+	return buildkite.NewTimestamp(monday.Add(d))
+}
+
+// job returns a command job created at created, that waited wait for an
+// agent and ran for run.
+func job(key, state string, created, wait, run time.Duration) buildkite.Job {
+	return buildkite.Job{
+		Type:       "script",
+		StepKey:    key,
+		Label:      strings.ToUpper(key[:1]) + key[1:],
+		State:      state,
+		CreatedAt:  at(created),
+		RunnableAt: at(created),
+		StartedAt:  at(created + wait),
+		FinishedAt: at(created + wait + run),
+	}
+}
+
+func build(state string, created, run time.Duration, jobs ...buildkite.Job) buildkite.Build {
+	return buildkite.Build{
+		State:      state,
+		CreatedAt:  at(created),
+		StartedAt:  at(created),
+		FinishedAt: at(created + run),
+		Jobs:       jobs,
+	}
+}
+
+func testBuilds() []buildkite.Build {
+	day := 24 * time.Hour
+	retried := job("tests", "failed", 0, 5*time.Second, time.Minute)
+	retried.Retried = true
+	softFailed := job("lint", "failed", day, 0, 10*time.Second)
+	softFailed.SoftFailed = true
+
+	return []buildkite.Build{
+		build("passed", 0, 5*time.Minute,
+			job("lint", "passed", 0, 10*time.Second, 20*time.Second),
+			retried,
+			job("tests", "passed", 0, 5*time.Second, 2*time.Minute),
+			buildkite.Job{Type: "waiter"},
+		),
+		build("failed", day, 6*time.Minute,
+			softFailed,
+			job("tests", "failed", day, 20*time.Second, 3*time.Minute),
+		),
+		build("canceled", 2*day, time.Minute,
+			job("lint", "passed", 2*day, 30*time.Second, 20*time.Second),
+		),
+		build("passed", 8*day, 4*time.Minute,
+			job("tests", "timed_out", 8*day, time.Minute, 4*time.Minute),
+		),
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	t.Parallel()
+
+	sunday := time.Date(2026, 3, 8, 23, 30, 0, 0, time.UTC)
+	if got := Day.Start(sunday); !got.Equal(time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Day.Start() = %s, want 2026-03-08", got)
+	}
+	if got := Week.Start(sunday); !got.Equal(monday) {
+		t.Errorf("Week.Start() = %s, want %s", got, monday)
+	}
+	if got := Week.Start(monday); !got.Equal(monday) {
+		t.Errorf("Week.Start(monday) = %s, want %s", got, monday)
+	}
+}
+
+func TestPercentiles(t *testing.T) {
+	t.Parallel()
+
+	var durations []time.Duration
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Second)
+	}
+	if got, want := percentiles(durations), (Percentiles{P50: 50 * time.Second, P90: 90 * time.Second, P99: 99 * time.Second}); got != want {
+		t.Errorf("percentiles() = %+v, want %+v", got, want)
+	}
+	if got, want := percentiles([]time.Duration{time.Second}), (Percentiles{P50: time.Second, P90: time.Second, P99: time.Second}); got != want {
+		t.Errorf("percentiles() of one = %+v, want %+v", got, want)
+	}
+	if got := percentiles(nil); got != (Percentiles{}) {
+		t.Errorf("percentiles(nil) = %+v, want zero", got)
+	}
+}
+
+func TestBuilds(t *testing.T) {
+	t.Parallel()
+
+	s := Builds(testBuilds(), Day)
+
+	if s.Builds != 4 || s.Passed != 2 || s.Failed != 1 || s.Other != 1 {
+		t.Errorf("counts = %d builds, %d passed, %d failed, %d other, want 4, 2, 1, 1", s.Builds, s.Passed, s.Failed, s.Other)
+	}
+	if want := 2.0 / 3; s.PassRate != want {
+		t.Errorf("PassRate = %v, want %v", s.PassRate, want)
+	}
+	if want := (Percentiles{P50: 4 * time.Minute, P90: 6 * time.Minute, P99: 6 * time.Minute}); s.Duration != want {
+		t.Errorf("Duration = %+v, want %+v", s.Duration, want)
+	}
+	if s.QueueWait.P99 != time.Minute {
+		t.Errorf("QueueWait.P99 = %s, want 1m", s.QueueWait.P99)
+	}
+
+	if len(s.FailingSteps) != 1 {
+		t.Fatalf("FailingSteps = %+v, want only tests", s.FailingSteps)
+	}
+	if f := s.FailingSteps[0]; f.Step != "Tests" || f.Failures != 3 || f.Jobs != 4 {
+		t.Errorf("FailingSteps[0] = %+v, want Tests with 3 of 4 jobs failed", f)
+	}
+
+	var trend []string
+	for _, p := range s.Trend {
+		trend = append(trend, formatPeriod(p.Start)+":"+formatRate(p.PassRate))
+	}
+	if got, want := strings.Join(trend, " "), "2026-03-02:100% 2026-03-03:0% 2026-03-04:0% 2026-03-10:100%"; got != want {
+		t.Errorf("Trend = %s, want %s", got, want)
+	}
+
+	weekly := Builds(testBuilds(), Week)
+	if len(weekly.Trend) != 2 || weekly.Trend[0].Builds != 3 || weekly.Trend[1].Builds != 1 {
+		t.Errorf("weekly Trend = %+v, want 3 builds then 1", weekly.Trend)
+	}
+}
+
+func TestJobs(t *testing.T) {
+	t.Parallel()
+
+	var jobs []buildkite.Job
+	for _, b := range testBuilds() {
+		jobs = append(jobs, b.Jobs...)
+	}
+	s := Jobs(jobs, Week)
+
+	if s.Jobs != 7 || s.Passed != 4 || s.Failed != 3 {
+		t.Errorf("counts = %d jobs, %d passed, %d failed, want 7, 4, 3", s.Jobs, s.Passed, s.Failed)
+	}
+
+	var steps []string
+	for _, st := range s.Steps {
+		steps = append(steps, st.Step+":"+formatRate(st.FailRate))
+	}
+	if got, want := strings.Join(steps, " "), "Tests:75% Lint:0%"; got != want {
+		t.Errorf("Steps = %s, want %s", got, want)
+	}
+	if lint := s.Steps[1]; lint.QueueWait.P50 != 10*time.Second {
+		t.Errorf("Lint QueueWait.P50 = %s, want 10s", lint.QueueWait.P50)
+	}
+	if len(s.Trend) != 2 || s.Trend[1].Failed != 1 {
+		t.Errorf("Trend = %+v, want two weeks with one failure in the second", s.Trend)
+	}
+}
+
+func TestWriteBuilds(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	if err := WriteBuilds(&out, Builds(testBuilds(), Week), Week); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+
+	for _, want := range []string{
+		"Builds: 4 (2 passed, 1 failed, 1 other)\nPass rate: 67%\n",
+		"Build duration",
+		"Queue wait",
+		"\nMost failing steps:\n",
+		"\nTrend by week:\n",
+		"WEEK OF",
+		"2026-03-09",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteBuilds() =\n%s\nwant it to contain %q", got, want)
+		}
+	}
+}
+
+func TestWriteNoBuilds(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	if err := WriteBuilds(&out, Builds(nil, Day), Day); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "No builds found.\n" {
+		t.Errorf("WriteBuilds() = %q, want no builds found", got)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	if err := WriteBuildsCSV(&out, Builds(testBuilds(), Week)); err != nil {
+		t.Fatal(err)
+	}
+	want := "period,builds,passed,failed,pass_rate,duration_p50,duration_p90,duration_p99\n" +
+		"2026-03-02,3,1,1,0.5,300,360,360\n" +
+		"2026-03-09,1,1,0,1,240,240,240\n"
+	if got := out.String(); got != want {
+		t.Errorf("WriteBuildsCSV() =\n%s\nwant\n%s", got, want)
+	}
+
+	var jobs []buildkite.Job
+	for _, b := range testBuilds() {
+		jobs = append(jobs, b.Jobs...)
+	}
+	out.Reset()
+	if err := WriteJobsCSV(&out, Jobs(jobs, Day)); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "Tests,4,1,3,0.75,") {
+		t.Errorf("WriteJobsCSV() =\n%s\nwant a header and a row per step, Tests first", out.String())
+	}
+}
+
+func TestStatsJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(Builds(testBuilds(), Day))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		PassRate float64 `json:"pass_rate"`
+		Duration struct {
+			P50 float64 `json:"p50_seconds"`
+		} `json:"duration"`
+		FailingSteps []StepFailure `json:"failing_steps"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Duration.P50 != 240 {
+		t.Errorf("duration.p50_seconds = %v, want 240", decoded.Duration.P50)
+	}
+	if len(decoded.FailingSteps) != 1 {
+		t.Errorf("failing_steps = %+v, want one", decoded.FailingSteps)
+	}
+}
+
+func TestStatsYAML(t *testing.T) {
+	t.Parallel()
+
+	data, err := yaml.Marshal(Builds(testBuilds(), Day))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"pass_rate: 0.6666666666666666\n", "duration:\n    p50_seconds: 240\n", "failing_steps:\n    - step: Tests\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("yaml.Marshal() =\n%s\nwant it to contain %q", data, want)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package timeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/emoji"
+	"github.com/buildkite/cli/v3/pkg/output"
+)
+
+const (
+	// dependencyTolerance is how long before a job ends another job can
+	// become runnable and still be taken to have been waiting for it.
+	dependencyTolerance = 5 * time.Second
+
+	// minSuggestedWait is the least time a critical job must wait for an
+	// agent, and the least share of the build, to suggest adding agents.
+	minSuggestedWait      = time.Minute
+	minSuggestedWaitShare = 0.1
+
+	// minSuggestedRunShare is the least share of the build a critical job
+	// must run for to suggest parallelism.
+	minSuggestedRunShare = 0.25
+)
+
+// CriticalPath is the chain of jobs that determined how long a build took:
+// each one couldn't be scheduled until the one before it finished.
+type CriticalPath struct {
+	Build    int           `json:"build" yaml:"build"`
+	Start    time.Time     `json:"start" yaml:"start"`
+	Duration time.Duration `json:"-" yaml:"-"`
+	Jobs     []Job         `json:"jobs" yaml:"jobs"`
+
+	// Wait and Run are the time the jobs on the path spent waiting for an
+	// agent and running. Other is the rest of the build's duration, spent
+	// outside jobs, e.g. on block steps or between one job and the next.
+	Wait  time.Duration `json:"-" yaml:"-"`
+	Run   time.Duration `json:"-" yaml:"-"`
+	Other time.Duration `json:"-" yaml:"-"`
+
+	Suggestions []Suggestion `json:"suggestions" yaml:"suggestions"`
+}
+
+// MarshalJSON adds the path's durations in seconds.
+func (p CriticalPath) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.withSeconds())
+}
+
+// MarshalYAML adds the path's durations in seconds, as MarshalJSON does.
+func (p CriticalPath) MarshalYAML() (any, error) {
+	return p.withSeconds(), nil
+}
+
+func (p CriticalPath) withSeconds() any {
+	type path CriticalPath
+	return struct {
+		path            `yaml:",inline"`
+		DurationSeconds float64 `json:"duration_seconds" yaml:"duration_seconds"`
+		WaitSeconds     float64 `json:"wait_seconds" yaml:"wait_seconds"`
+		RunSeconds      float64 `json:"run_seconds" yaml:"run_seconds"`
+		OtherSeconds    float64 `json:"other_seconds" yaml:"other_seconds"`
+	}{path(p), p.Duration.Seconds(), p.Wait.Seconds(), p.Run.Seconds(), p.Other.Seconds()}
+}
+
+// Suggestion is a step on the critical path that more agents ("agents") or
+// more parallelism ("parallelism") would speed up.
+type Suggestion struct {
+	Step    string `json:"step" yaml:"step"`
+	Kind    string `json:"kind" yaml:"kind"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// CriticalPath works back from the job that finished last to find the jobs
+// that held up the build.
+//
+// A job is taken to have been waiting for whichever of the jobs it could
+// have depended on finished last before it: the jobs of the steps its step
+// names in depends_on, or an earlier attempt of the same step, if
+// SetDependencies recorded any. Failing those, it's the job that finished
+// most recently before it became runnable, preferring jobs before the last
+// wait step ahead of it; so a retried job is found to be waiting for its
+// failed attempt.
+func (t Timeline) CriticalPath() CriticalPath {
+	p := CriticalPath{Build: t.Build, Start: t.Start, Duration: t.Duration()}
+
+	var jobs []Job
+	for _, s := range t.Steps {
+		jobs = append(jobs, s.Jobs...)
+	}
+	if len(jobs) == 0 {
+		p.Other = p.Duration
+		return p
+	}
+
+	last := jobs[0]
+	for _, j := range jobs[1:] {
+		if jobEnd(j).After(jobEnd(last)) {
+			last = j
+		}
+	}
+
+	path := []Job{last}
+	for cur := last; ; {
+		prev, ok := blockingJob(jobs, cur)
+		if !ok {
+			break
+		}
+		path = append(path, prev)
+		cur = prev
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	p.Jobs = path
+	for _, j := range path {
+		p.Wait += j.Wait
+		p.Run += j.Run
+	}
+	p.Other = max(p.Duration-p.Wait-p.Run, 0)
+	p.Suggestions = suggest(p)
+	return p
+}
+
+// blockingJob returns the job that cur was waiting for, if any. Each job it
+// returns finished before cur did, so following it back always ends.
+func blockingJob(jobs []Job, cur Job) (Job, bool) {
+	if len(cur.DependsOn) > 0 {
+		// An explicit dependency finished before cur started, whether or not
+		// the API said when cur became runnable.
+		started := cur.RunnableAt.Add(cur.Wait + dependencyTolerance)
+		var best Job
+		found := false
+		for _, j := range jobs {
+			if j.ID == cur.ID || !jobEnd(j).Before(jobEnd(cur)) || jobEnd(j).After(started) {
+				continue
+			}
+			if !dependsOn(cur, j) && !(j.Retried && sameStep(cur, j)) {
+				continue
+			}
+			if !found || jobEnd(j).After(jobEnd(best)) {
+				best, found = j, true
+			}
+		}
+		if found {
+			return best, true
+		}
+	}
+
+	var best Job
+	found, bestEarlier := false, false
+	for _, j := range jobs {
+		if j.ID == cur.ID || j.Stage > cur.Stage || !jobEnd(j).Before(jobEnd(cur)) {
+			continue
+		}
+		if jobEnd(j).After(cur.RunnableAt.Add(dependencyTolerance)) {
+			continue
+		}
+		earlier := j.Stage < cur.Stage
+		if !found || (earlier && !bestEarlier) || (earlier == bestEarlier && jobEnd(j).After(jobEnd(best))) {
+			best, found, bestEarlier = j, true, earlier
+		}
+	}
+	return best, found
+}
+
+// dependsOn reports whether cur's step names j's step in its depends_on.
+func dependsOn(cur, j Job) bool {
+	for _, dep := range cur.DependsOn {
+		if dep != "" && (dep == j.StepKey || dep == j.StepID) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameStep(a, b Job) bool {
+	if a.StepID != "" && b.StepID != "" {
+		return a.StepID == b.StepID
+	}
+	return a.StepKey != "" && a.StepKey == b.StepKey
+}
+
+func jobEnd(j Job) time.Time {
+	return j.RunnableAt.Add(j.Wait + j.Run)
+}
+
+// suggest flags the steps on p where more agents or parallelism would
+// shorten the build.
+func suggest(p CriticalPath) []Suggestion {
+	if p.Duration <= 0 {
+		return nil
+	}
+	share := func(d time.Duration) int { return int(100 * d.Seconds() / p.Duration.Seconds()) }
+
+	var suggestions []Suggestion
+	for _, j := range p.Jobs {
+		if j.Wait >= minSuggestedWait && j.Wait.Seconds() >= minSuggestedWaitShare*p.Duration.Seconds() {
+			where := "its queue"
+			if j.Queue != "" {
+				where = "the " + j.Queue + " queue"
+			}
+			suggestions = append(suggestions, Suggestion{
+				Step: j.Label,
+				Kind: "agents",
+				Message: fmt.Sprintf("waited %s for an agent (%d%% of the build); more agents on %s would save up to %s",
+					FormatDuration(j.Wait), share(j.Wait), where, FormatDuration(j.Wait)),
+			})
+		}
+
+		if j.Run.Seconds() >= minSuggestedRunShare*p.Duration.Seconds() {
+			advice := "splitting it into parallel jobs could shorten the build"
+			if j.Parallelism > 1 {
+				advice = fmt.Sprintf("it already runs %d in parallel; raising that could shorten the build", j.Parallelism)
+			}
+			suggestions = append(suggestions, Suggestion{
+				Step:    j.Label,
+				Kind:    "parallelism",
+				Message: fmt.Sprintf("ran for %s (%d%% of the build); %s", FormatDuration(j.Run), share(j.Run), advice),
+			})
+		}
+	}
+	return suggestions
+}
+
+// WriteCriticalPath writes p as a table of its jobs, followed by any
+// suggestions.
+func WriteCriticalPath(w io.Writer, p CriticalPath) error {
+	fmt.Fprintf(w, "Critical path of build #%d: %s\n", p.Build, FormatDuration(p.Duration))
+	if p.Duration > 0 {
+		fmt.Fprintf(w, "%s waiting for agents (%s), %s running (%s), %s outside jobs (%s)\n\n",
+			FormatDuration(p.Wait), percent(p.Wait, p.Duration),
+			FormatDuration(p.Run), percent(p.Run, p.Duration),
+			FormatDuration(p.Other), percent(p.Other, p.Duration))
+	}
+
+	if len(p.Jobs) == 0 {
+		fmt.Fprintln(w, "No jobs have been scheduled.")
+		return nil
+	}
+
+	var rows [][]string
+	for _, j := range p.Jobs {
+		label := emoji.Render(j.Label)
+		if j.Retried {
+			label += " (retried)"
+		}
+		rows = append(rows, []string{
+			"+" + FormatDuration(j.RunnableAt.Sub(p.Start)),
+			label,
+			FormatDuration(j.Wait),
+			FormatDuration(j.Run),
+			output.ValueOrDash(agentLabel(j)),
+		})
+	}
+	fmt.Fprint(w, output.Table([]string{"Runnable", "Job", "Wait", "Run", "Agent"}, rows, map[string]string{
+		"runnable": "dim",
+		"job":      "bold",
+		"agent":    "dim",
+	}))
+
+	if len(p.Suggestions) > 0 {
+		fmt.Fprintln(w, "\nTo shorten the build:")
+		for _, s := range p.Suggestions {
+			fmt.Fprintf(w, "  • %s %s\n", emoji.Render(s.Step), s.Message)
+		}
+	}
+	return nil
+}
+
+// StepFrequency is how often a step was on the critical path across several
+// builds, and its average wait and run time when it was.
+type StepFrequency struct {
+	Step     string        `json:"step" yaml:"step"`
+	Critical int           `json:"critical" yaml:"critical"`
+	Builds   int           `json:"builds" yaml:"builds"`
+	Wait     time.Duration `json:"-" yaml:"-"`
+	Run      time.Duration `json:"-" yaml:"-"`
+}
+
+// MarshalJSON adds the step's average durations in seconds.
+func (s StepFrequency) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.withSeconds())
+}
+
+// MarshalYAML adds the step's average durations in seconds, as MarshalJSON
+// does.
+func (s StepFrequency) MarshalYAML() (any, error) {
+	return s.withSeconds(), nil
+}
+
+func (s StepFrequency) withSeconds() any {
+	type freq StepFrequency
+	return struct {
+		freq        `yaml:",inline"`
+		WaitSeconds float64 `json:"avg_wait_seconds" yaml:"avg_wait_seconds"`
+		RunSeconds  float64 `json:"avg_run_seconds" yaml:"avg_run_seconds"`
+	}{freq(s), s.Wait.Seconds(), s.Run.Seconds()}
+}
+
+// CriticalSteps counts how often each step was on the critical paths,
+// most often first.
+func CriticalSteps(paths []CriticalPath) []StepFrequency {
+	byStep := make(map[string]*StepFrequency)
+	var order []string
+	for _, p := range paths {
+		seen := make(map[string]bool)
+		for _, j := range p.Jobs {
+			key := j.StepKey
+			if key == "" {
+				key = j.Label
+			}
+			s, ok := byStep[key]
+			if !ok {
+				s = &StepFrequency{Step: j.Label, Builds: len(paths)}
+				byStep[key] = s
+				order = append(order, key)
+			}
+			if !seen[key] {
+				seen[key] = true
+				s.Critical++
+			}
+			s.Wait += j.Wait
+			s.Run += j.Run
+		}
+	}
+
+	steps := make([]StepFrequency, 0, len(order))
+	for _, key := range order {
+		s := *byStep[key]
+		s.Wait /= time.Duration(s.Critical)
+		s.Run /= time.Duration(s.Critical)
+		steps = append(steps, s)
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		if steps[i].Critical != steps[j].Critical {
+			return steps[i].Critical > steps[j].Critical
+		}
+		return steps[i].Wait+steps[i].Run > steps[j].Wait+steps[j].Run
+	})
+	return steps
+}
+
+// WriteCriticalSteps writes steps as a table.
+func WriteCriticalSteps(w io.Writer, steps []StepFrequency) error {
+	if len(steps) == 0 {
+		fmt.Fprintln(w, "No finished builds found.")
+		return nil
+	}
+
+	fmt.Fprintf(w, "Steps on the critical path of the last %d builds:\n\n", steps[0].Builds)
+	var rows [][]string
+	for _, s := range steps {
+		rows = append(rows, []string{
+			emoji.Render(s.Step),
+			fmt.Sprintf("%d/%d", s.Critical, s.Builds),
+			FormatDuration(s.Wait),
+			FormatDuration(s.Run),
+		})
+	}
+	fmt.Fprint(w, output.Table([]string{"Step", "Critical", "Avg wait", "Avg run"}, rows, map[string]string{
+		"step":     "bold",
+		"critical": "bold",
+	}))
+	return nil
+}
+
+func percent(d, of time.Duration) string {
+	return fmt.Sprintf("%d%%", int(100*d.Seconds()/of.Seconds()+0.5))
+}
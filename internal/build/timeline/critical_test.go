@@ -0,0 +1,208 @@
+package timeline
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func pathLabels(p CriticalPath) []string {
+	var labels []string
+	for _, j := range p.Jobs {
+		labels = append(labels, j.Label)
+	}
+	return labels
+}
+
+func stagedBuild() buildkite.Build {
+	return buildkite.Build{
+		Number:     9,
+		CreatedAt:  at(0),
+		FinishedAt: at(40),
+		Jobs: []buildkite.Job{
+			{ID: "upload", Type: "script", Label: "Upload", State: "passed", ScheduledAt: at(0), StartedAt: at(0), FinishedAt: at(1)},
+			{ID: "w1", Type: "waiter"},
+			{ID: "lint", Type: "script", Label: "Lint", State: "passed", ScheduledAt: at(1), StartedAt: at(2), FinishedAt: at(4)},
+			{ID: "test", Type: "script", Label: "Test", StepKey: "test", State: "passed", ScheduledAt: at(1), StartedAt: at(6), FinishedAt: at(30), AgentQueryRules: []string{"queue=large"}},
+			{ID: "w2", Type: "waiter"},
+			{ID: "deploy", Type: "script", Label: "Deploy", State: "passed", ScheduledAt: at(30), StartedAt: at(31), FinishedAt: at(40)},
+		},
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	t.Parallel()
+
+	p := New(stagedBuild(), start).CriticalPath()
+
+	if got, want := strings.Join(pathLabels(p), ","), "Upload,Test,Deploy"; got != want {
+		t.Fatalf("CriticalPath() jobs = %s, want %s", got, want)
+	}
+	if p.Duration != 40*time.Minute || p.Wait != 6*time.Minute || p.Run != 34*time.Minute || p.Other != 0 {
+		t.Errorf("CriticalPath() = %s long, %s waiting, %s running, %s other; want 40m, 6m, 34m, 0s", p.Duration, p.Wait, p.Run, p.Other)
+	}
+
+	if len(p.Suggestions) != 2 {
+		t.Fatalf("Suggestions = %+v, want agents and parallelism for Test", p.Suggestions)
+	}
+	if s := p.Suggestions[0]; s.Step != "Test" || s.Kind != "agents" || !strings.Contains(s.Message, "the large queue") {
+		t.Errorf("Suggestions[0] = %+v, want more agents on the large queue for Test", s)
+	}
+	if s := p.Suggestions[1]; s.Step != "Test" || s.Kind != "parallelism" || !strings.Contains(s.Message, "60% of the build") {
+		t.Errorf("Suggestions[1] = %+v, want parallelism for Test", s)
+	}
+}
+
+func TestCriticalPathRetry(t *testing.T) {
+	t.Parallel()
+
+	b := buildkite.Build{
+		Number:     10,
+		CreatedAt:  at(0),
+		FinishedAt: at(20),
+		Jobs: []buildkite.Job{
+			{ID: "flaky-1", Type: "script", Label: "Flaky", State: "failed", Retried: true, ScheduledAt: at(0), StartedAt: at(0), FinishedAt: at(10)},
+			{ID: "quick", Type: "script", Label: "Quick", State: "passed", ScheduledAt: at(0), StartedAt: at(0), FinishedAt: at(2)},
+			{ID: "flaky-2", Type: "script", Label: "Flaky", State: "passed", ScheduledAt: at(10), StartedAt: at(10), FinishedAt: at(20)},
+		},
+	}
+	p := New(b, start).CriticalPath()
+
+	if len(p.Jobs) != 2 || p.Jobs[0].ID != "flaky-1" || p.Jobs[1].ID != "flaky-2" {
+		t.Errorf("CriticalPath() jobs = %v, want both attempts of Flaky", pathLabels(p))
+	}
+}
+
+// scheduledAtCreation is stagedBuild as the API reports it: every job is
+// scheduled when the build is created, and becomes runnable later.
+func scheduledAtCreation() buildkite.Build {
+	b := stagedBuild()
+	runnable := map[string]int{"upload": 0, "lint": 1, "test": 1, "deploy": 30}
+	for i := range b.Jobs {
+		if r, ok := runnable[b.Jobs[i].ID]; ok {
+			b.Jobs[i].ScheduledAt = b.CreatedAt
+			b.Jobs[i].RunnableAt = at(r)
+		}
+	}
+	return b
+}
+
+func TestCriticalPathRunnable(t *testing.T) {
+	t.Parallel()
+
+	p := New(scheduledAtCreation(), start).CriticalPath()
+
+	if got, want := strings.Join(pathLabels(p), ","), "Upload,Test,Deploy"; got != want {
+		t.Fatalf("CriticalPath() jobs = %s, want %s", got, want)
+	}
+	if p.Wait != 6*time.Minute || p.Run != 34*time.Minute {
+		t.Errorf("CriticalPath() = %s waiting, %s running; want 6m, 34m", p.Wait, p.Run)
+	}
+}
+
+func TestCriticalPathDependsOn(t *testing.T) {
+	t.Parallel()
+
+	// No wait steps and no runnable times: only depends_on says that test
+	// waited for compile, and deploy for test and lint.
+	b := buildkite.Build{
+		Number:     11,
+		CreatedAt:  at(0),
+		FinishedAt: at(25),
+		Jobs: []buildkite.Job{
+			{ID: "compile", Type: "script", Label: "Compile", StepKey: "compile", State: "passed", ScheduledAt: at(0), StartedAt: at(0), FinishedAt: at(10)},
+			{ID: "lint", Type: "script", Label: "Lint", StepKey: "lint", State: "passed", ScheduledAt: at(0), StartedAt: at(0), FinishedAt: at(3)},
+			{ID: "test", Type: "script", Label: "Test", StepKey: "test", State: "passed", ScheduledAt: at(0), StartedAt: at(12), FinishedAt: at(20)},
+			{ID: "deploy", Type: "script", Label: "Deploy", State: "passed", ScheduledAt: at(0), StartedAt: at(20), FinishedAt: at(25)},
+		},
+	}
+
+	tl := New(b, start)
+	if got := strings.Join(pathLabels(tl.CriticalPath()), ","); got != "Deploy" {
+		t.Fatalf("CriticalPath() without dependencies = %s, want only Deploy", got)
+	}
+
+	tl.SetDependencies(map[string]Dependencies{
+		"test":   {Step: "step-test", DependsOn: []string{"compile"}},
+		"deploy": {Step: "step-deploy", DependsOn: []string{"step-test", "lint"}},
+	})
+	if got, want := strings.Join(pathLabels(tl.CriticalPath()), ","), "Compile,Test,Deploy"; got != want {
+		t.Errorf("CriticalPath() = %s, want %s", got, want)
+	}
+}
+
+func TestCriticalPathNoJobs(t *testing.T) {
+	t.Parallel()
+
+	p := New(buildkite.Build{Number: 1, CreatedAt: at(0), FinishedAt: at(1)}, start).CriticalPath()
+	if len(p.Jobs) != 0 || p.Other != time.Minute || len(p.Suggestions) != 0 {
+		t.Errorf("CriticalPath() = %+v, want no jobs and a minute outside jobs", p)
+	}
+
+	var out strings.Builder
+	if err := WriteCriticalPath(&out, p); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No jobs have been scheduled.") {
+		t.Errorf("WriteCriticalPath() = %q, want a no jobs message", out.String())
+	}
+}
+
+func TestWriteCriticalPath(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	if err := WriteCriticalPath(&out, New(stagedBuild(), start).CriticalPath()); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"Critical path of build #9: 40m0s\n",
+		"6m0s waiting for agents (15%), 34m0s running (85%), 0s outside jobs (0%)\n",
+		"RUNNABLE",
+		"+1m0s",
+		"queue large",
+		"To shorten the build:\n",
+		"  • Test waited 5m0s for an agent (12% of the build); more agents on the large queue would save up to 5m0s\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteCriticalPath() =\n%s\nwant it to contain %q", got, want)
+		}
+	}
+}
+
+func TestCriticalSteps(t *testing.T) {
+	t.Parallel()
+
+	retry := buildkite.Build{
+		Number:     10,
+		CreatedAt:  at(0),
+		FinishedAt: at(20),
+		Jobs: []buildkite.Job{
+			{ID: "t1", Type: "script", Label: "Test", StepKey: "test", State: "failed", ScheduledAt: at(0), StartedAt: at(0), FinishedAt: at(8)},
+			{ID: "t2", Type: "script", Label: "Test", StepKey: "test", State: "passed", ScheduledAt: at(8), StartedAt: at(10), FinishedAt: at(20)},
+		},
+	}
+	steps := CriticalSteps([]CriticalPath{
+		New(stagedBuild(), start).CriticalPath(),
+		New(retry, start).CriticalPath(),
+	})
+
+	if len(steps) != 3 {
+		t.Fatalf("CriticalSteps() = %+v, want Test, Upload and Deploy", steps)
+	}
+	test := steps[0]
+	if test.Step != "Test" || test.Critical != 2 || test.Builds != 2 {
+		t.Errorf("steps[0] = %+v, want Test critical in 2 of 2 builds", test)
+	}
+	// Test waited 5m in the first build and 2m in the second, and ran 24m
+	// and 8m+10m.
+	if test.Wait != 3*time.Minute+30*time.Second || test.Run != 21*time.Minute {
+		t.Errorf("steps[0] averages = %s wait, %s run, want 3m30s, 21m0s", test.Wait, test.Run)
+	}
+	if steps[1].Step != "Deploy" || steps[1].Critical != 1 {
+		t.Errorf("steps[1] = %+v, want Deploy critical in 1 build", steps[1])
+	}
+}
@@ -0,0 +1,69 @@
+package timeline
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// mermaidTimeFormat matches the dateFormat declared in WriteMermaid.
+const mermaidTimeFormat = "2006-01-02T15:04:05"
+
+// mermaidUnsafe is replaced in task and section names, where Mermaid gives
+// these characters a meaning.
+var mermaidUnsafe = strings.NewReplacer(":", " ", "#", " ", ";", " ", "\n", " ")
+
+// WriteMermaid writes t as a Mermaid Gantt chart, with a section per step.
+// Waits are drawn as completed tasks, and the runs of failed jobs as
+// critical ones.
+func WriteMermaid(w io.Writer, t Timeline) error {
+	var b strings.Builder
+	b.WriteString("gantt\n")
+	fmt.Fprintf(&b, "    title Build %d\n", t.Build)
+	b.WriteString("    dateFormat YYYY-MM-DDTHH:mm:ss\n")
+	b.WriteString("    axisFormat %H:%M:%S\n")
+
+	n := 0
+	for _, s := range t.Steps {
+		fmt.Fprintf(&b, "    section %s\n", mermaidName(s.Label))
+		for _, j := range s.Jobs {
+			n++
+			name := mermaidName(j.Label)
+			if j.Wait > 0 {
+				fmt.Fprintf(&b, "    %s (waiting) :done, j%dw, %s, %s\n",
+					name, n, mermaidTime(j.RunnableAt), mermaidTime(j.RunnableAt.Add(j.Wait)))
+			}
+			if j.StartedAt != nil {
+				tag := "active"
+				if isFailed(j.State) {
+					tag = "crit"
+				}
+				fmt.Fprintf(&b, "    %s :%s, j%dr, %s, %s\n",
+					name, tag, n, mermaidTime(*j.StartedAt), mermaidTime(j.StartedAt.Add(j.Run)))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func mermaidName(s string) string {
+	if s = strings.Join(strings.Fields(mermaidUnsafe.Replace(s)), " "); s == "" {
+		return "-"
+	}
+	return s
+}
+
+func mermaidTime(t time.Time) string {
+	return t.UTC().Format(mermaidTimeFormat)
+}
+
+func isFailed(state string) bool {
+	switch state {
+	case "failed", "timed_out", "broken":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package timeline
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/buildkite/cli/v3/internal/emoji"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	waitCell = '░'
+	runCell  = '█'
+
+	// maxLabelWidth caps the width of the job label column.
+	maxLabelWidth = 40
+)
+
+// Write renders t as a Gantt chart, with bars width cells wide. Each job's
+// bar shows the time it waited for an agent and the time it ran.
+func Write(w io.Writer, t Timeline, width int) error {
+	wait, run := t.Totals()
+	fmt.Fprintf(w, "Build #%d took %s. Jobs waited %s for agents and ran for %s in total.\n",
+		t.Build, FormatDuration(t.Duration()), FormatDuration(wait), FormatDuration(run))
+	fmt.Fprintf(w, "%c waiting for an agent  %c running\n", waitCell, runCell)
+
+	if len(t.Steps) == 0 {
+		fmt.Fprintln(w, "\nNo jobs have been scheduled.")
+		return nil
+	}
+
+	labels := make(map[string]string)
+	labelWidth := 0
+	for _, s := range t.Steps {
+		for _, j := range s.Jobs {
+			label := emoji.Render(truncate(j.Label, maxLabelWidth))
+			if j.Retried {
+				label += " (retried)"
+			}
+			labels[j.ID] = label
+			labelWidth = max(labelWidth, lipgloss.Width(label))
+		}
+	}
+
+	axis := fmt.Sprintf("%-*s", width, "0s")
+	if end := FormatDuration(t.Duration()); len(end) < width-2 {
+		axis = axis[:width-len(end)] + end
+	}
+	fmt.Fprintf(w, "\n  %s  %s\n", strings.Repeat(" ", labelWidth), axis)
+
+	for _, s := range t.Steps {
+		title := emoji.Render(s.Label)
+		if s.Key != "" && s.Key != s.Label {
+			title += " (" + s.Key + ")"
+		}
+		fmt.Fprintf(w, "%s\n", title)
+		for _, j := range s.Jobs {
+			label := labels[j.ID]
+			pad := strings.Repeat(" ", labelWidth-lipgloss.Width(label))
+			fmt.Fprintf(w, "  %s%s  %s  wait %-7s run %-7s %s\n",
+				label, pad, bar(t, j, width), FormatDuration(j.Wait), runLabel(j), agentLabel(j))
+		}
+	}
+	return nil
+}
+
+// bar draws a job's wait and run times on a scale of width cells spanning
+// the timeline.
+func bar(t Timeline, j Job, width int) string {
+	total := t.Duration()
+	cell := func(at time.Time) int {
+		if total <= 0 {
+			return 0
+		}
+		return min(int(float64(at.Sub(t.Start))/float64(total)*float64(width)), width)
+	}
+
+	waitFrom := cell(j.RunnableAt)
+	runFrom := cell(j.RunnableAt.Add(j.Wait))
+	runTo := runFrom
+	if j.StartedAt != nil {
+		runTo = cell(j.StartedAt.Add(j.Run))
+		// A job that ran at all gets at least one cell.
+		if runTo == runFrom {
+			if runTo < width {
+				runTo++
+			} else {
+				runFrom--
+			}
+		}
+	}
+	waitFrom = min(waitFrom, runFrom)
+
+	return strings.Repeat(" ", waitFrom) +
+		strings.Repeat(string(waitCell), runFrom-waitFrom) +
+		strings.Repeat(string(runCell), runTo-runFrom) +
+		strings.Repeat(" ", width-runTo)
+}
+
+func runLabel(j Job) string {
+	switch {
+	case j.StartedAt == nil:
+		return "-"
+	case j.FinishedAt == nil:
+		return FormatDuration(j.Run) + "+"
+	default:
+		return FormatDuration(j.Run)
+	}
+}
+
+func agentLabel(j Job) string {
+	var parts []string
+	if j.Agent != "" {
+		parts = append(parts, j.Agent)
+	}
+	if j.Queue != "" {
+		parts = append(parts, "queue "+j.Queue)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FormatDuration formats d to the second, e.g. 1h2m3s, 4m5s or 6s.
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%dm%ds", d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm%ds", d/time.Minute, d%time.Minute/time.Second)
+	default:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,267 @@
+// Package timeline lays out when each job in a build waited for an agent and
+// when it ran.
+package timeline
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+// Timeline is the jobs of a build in time order, grouped by step.
+type Timeline struct {
+	Build int       `json:"build" yaml:"build"`
+	Start time.Time `json:"start" yaml:"start"`
+	End   time.Time `json:"end" yaml:"end"`
+	Steps []Step    `json:"steps" yaml:"steps"`
+}
+
+// Step is the jobs of one step, including parallel jobs and retries.
+type Step struct {
+	Key   string `json:"key,omitempty" yaml:"key,omitempty"`
+	Label string `json:"label" yaml:"label"`
+	Jobs  []Job  `json:"jobs" yaml:"jobs"`
+}
+
+// Job is when a job was scheduled, became runnable, started and finished.
+// A job is scheduled when its build is created, and runnable once the steps
+// it depends on have finished; RunnableAt is ScheduledAt if the API didn't
+// say. StartedAt is nil for a job still waiting for an agent, and FinishedAt
+// for one still running. Stage counts the wait steps before the job, and
+// DependsOn lists the keys or UUIDs of the steps its step explicitly depends
+// on, once SetDependencies has recorded them.
+type Job struct {
+	ID          string     `json:"id" yaml:"id"`
+	Label       string     `json:"label" yaml:"label"`
+	StepKey     string     `json:"step_key,omitempty" yaml:"step_key,omitempty"`
+	StepID      string     `json:"-" yaml:"-"`
+	DependsOn   []string   `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	State       string     `json:"state" yaml:"state"`
+	Retried     bool       `json:"retried,omitempty" yaml:"retried,omitempty"`
+	Parallelism int        `json:"parallelism,omitempty" yaml:"parallelism,omitempty"`
+	Agent       string     `json:"agent,omitempty" yaml:"agent,omitempty"`
+	Queue       string     `json:"queue,omitempty" yaml:"queue,omitempty"`
+	Stage       int        `json:"stage" yaml:"stage"`
+	ScheduledAt time.Time  `json:"scheduled_at" yaml:"scheduled_at"`
+	RunnableAt  time.Time  `json:"runnable_at" yaml:"runnable_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty" yaml:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty" yaml:"finished_at,omitempty"`
+
+	// Wait is the time from becoming runnable to starting on an agent, and
+	// Run the time from starting to finishing. Both are measured up to the
+	// end of the timeline for unfinished jobs.
+	Wait time.Duration `json:"-" yaml:"-"`
+	Run  time.Duration `json:"-" yaml:"-"`
+}
+
+// MarshalJSON adds the job's wait and run times in seconds.
+func (j Job) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.withSeconds())
+}
+
+// MarshalYAML adds the job's wait and run times in seconds, as MarshalJSON
+// does.
+func (j Job) MarshalYAML() (any, error) {
+	return j.withSeconds(), nil
+}
+
+func (j Job) withSeconds() any {
+	type job Job
+	return struct {
+		job         `yaml:",inline"`
+		WaitSeconds float64 `json:"wait_seconds" yaml:"wait_seconds"`
+		RunSeconds  float64 `json:"run_seconds" yaml:"run_seconds"`
+	}{job(j), j.Wait.Seconds(), j.Run.Seconds()}
+}
+
+// New returns the timeline of b's jobs. Jobs that never got as far as being
+// scheduled, such as wait steps, are left out. The unfinished jobs of a
+// running build are measured up to now.
+func New(b buildkite.Build, now time.Time) Timeline {
+	t := Timeline{Build: b.Number}
+
+	var jobs []Job
+	var keys []string
+	stage := 0
+	for _, j := range b.Jobs {
+		if j.Type == "waiter" {
+			stage++
+			continue
+		}
+		scheduled := firstTimestamp(j.ScheduledAt, j.RunnableAt, j.CreatedAt, j.StartedAt)
+		if scheduled == nil {
+			continue
+		}
+		runnable := ReadyAt(j)
+		if runnable == nil {
+			runnable = scheduled
+		}
+		jobs = append(jobs, Job{
+			ID:          j.ID,
+			Label:       JobLabel(j),
+			StepKey:     j.StepKey,
+			State:       j.State,
+			Retried:     j.Retried,
+			Agent:       j.Agent.Name,
+			Queue:       JobQueue(j.AgentQueryRules),
+			Stage:       stage,
+			ScheduledAt: *scheduled,
+			RunnableAt:  *runnable,
+			StartedAt:   timestampTime(j.StartedAt),
+			FinishedAt:  timestampTime(j.FinishedAt),
+		})
+		if j.ParallelGroupTotal != nil {
+			jobs[len(jobs)-1].Parallelism = *j.ParallelGroupTotal
+		}
+		keys = append(keys, StepKey(j))
+	}
+
+	switch {
+	case b.CreatedAt != nil:
+		t.Start = b.CreatedAt.Time
+	case len(jobs) > 0:
+		t.Start = jobs[0].ScheduledAt
+	}
+	if b.FinishedAt != nil {
+		t.End = b.FinishedAt.Time
+	}
+	for _, j := range jobs {
+		if j.ScheduledAt.Before(t.Start) {
+			t.Start = j.ScheduledAt
+		}
+		switch {
+		case j.FinishedAt != nil:
+			if j.FinishedAt.After(t.End) {
+				t.End = *j.FinishedAt
+			}
+		case b.FinishedAt == nil && now.After(t.End):
+			t.End = now
+		}
+	}
+	if t.End.Before(t.Start) {
+		t.End = t.Start
+	}
+
+	steps := make(map[string]int)
+	for n, j := range jobs {
+		finished := t.End
+		if j.FinishedAt != nil {
+			finished = *j.FinishedAt
+		}
+		started := finished
+		if j.StartedAt != nil {
+			started = *j.StartedAt
+		}
+		j.Wait = max(started.Sub(j.RunnableAt), 0)
+		if j.StartedAt != nil {
+			j.Run = max(finished.Sub(started), 0)
+		}
+
+		key := keys[n]
+		i, ok := steps[key]
+		if !ok {
+			i = len(t.Steps)
+			steps[key] = i
+			t.Steps = append(t.Steps, Step{Label: j.Label})
+			if !strings.HasPrefix(key, "label:") {
+				t.Steps[i].Key = key
+			}
+		}
+		t.Steps[i].Jobs = append(t.Steps[i].Jobs, j)
+	}
+
+	return t
+}
+
+// Dependencies is the UUID of the step a job belongs to and the keys or
+// UUIDs of the steps that step depends on, as reported by the GraphQL API.
+type Dependencies struct {
+	Step      string
+	DependsOn []string
+}
+
+// SetDependencies records the explicit dependencies of t's jobs, given by
+// job ID, for CriticalPath to follow.
+func (t *Timeline) SetDependencies(deps map[string]Dependencies) {
+	for i := range t.Steps {
+		for n := range t.Steps[i].Jobs {
+			j := &t.Steps[i].Jobs[n]
+			if d, ok := deps[j.ID]; ok {
+				j.StepID = d.Step
+				j.DependsOn = d.DependsOn
+			}
+		}
+	}
+}
+
+// Duration is how long the build took, or has taken so far.
+func (t Timeline) Duration() time.Duration {
+	return t.End.Sub(t.Start)
+}
+
+// Totals returns the time all jobs spent waiting for an agent and running.
+func (t Timeline) Totals() (wait, run time.Duration) {
+	for _, s := range t.Steps {
+		for _, j := range s.Jobs {
+			wait += j.Wait
+			run += j.Run
+		}
+	}
+	return wait, run
+}
+
+// ReadyAt returns when a job was ready for an agent: when it became
+// runnable, or failing that when it was scheduled. It's nil if the job was
+// neither, e.g. a job that was never run.
+func ReadyAt(j buildkite.Job) *time.Time {
+	return firstTimestamp(j.RunnableAt, j.ScheduledAt)
+}
+
+// StepKey identifies a job's step by its step key or, failing that, its
+// label.
+func StepKey(j buildkite.Job) string {
+	if j.StepKey != "" {
+		return j.StepKey
+	}
+	return "label:" + JobLabel(j)
+}
+
+// JobLabel returns a job's label, or failing that its name or type.
+func JobLabel(j buildkite.Job) string {
+	if j.Label != "" {
+		return j.Label
+	}
+	if j.Name != "" {
+		return j.Name
+	}
+	return j.Type
+}
+
+// JobQueue returns the queue in a job's agent query rules, or "" if it
+// doesn't target one.
+func JobQueue(rules []string) string {
+	for _, rule := range rules {
+		if queue, ok := strings.CutPrefix(rule, "queue="); ok {
+			return queue
+		}
+	}
+	return ""
+}
+
+func firstTimestamp(timestamps ...*buildkite.Timestamp) *time.Time {
+	for _, ts := range timestamps {
+		if ts != nil {
+			return &ts.Time
+		}
+	}
+	return nil
+}
+
+func timestampTime(ts *buildkite.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	return &ts.Time
+}
@@ -0,0 +1,198 @@
+package timeline
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"gopkg.in/yaml.v3"
+)
+
+var start = time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func at(minutes int) *buildkite.Timestamp {
+	return buildkite.NewTimestamp(start.Add(time.Duration(minutes) * time.Minute))
+}
+
+func testBuild() buildkite.Build {
+	return buildkite.Build{
+		Number:     42,
+		CreatedAt:  at(0),
+		FinishedAt: at(40),
+		Jobs: []buildkite.Job{
+			{ID: "lint", Type: "script", Label: "Lint: fast", StepKey: "lint", State: "passed", ScheduledAt: at(0), StartedAt: at(5), FinishedAt: at(10), Agent: buildkite.Agent{Name: "agent-1"}, AgentQueryRules: []string{"queue=default"}},
+			{ID: "wait", Type: "waiter"},
+			{ID: "test-1", Type: "script", Label: "Test", StepKey: "test", State: "failed", Retried: true, ScheduledAt: at(10), StartedAt: at(30), FinishedAt: at(35), Agent: buildkite.Agent{Name: "agent-2"}, AgentQueryRules: []string{"os=linux", "queue=large"}},
+			{ID: "test-2", Type: "script", Label: "Test", StepKey: "test", State: "passed", ScheduledAt: at(35), StartedAt: at(35), FinishedAt: at(40), Agent: buildkite.Agent{Name: "agent-3"}, AgentQueryRules: []string{"queue=large"}},
+			{ID: "deploy", Type: "script", Label: "Deploy", State: "skipped"},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tl := New(testBuild(), start.Add(time.Hour))
+
+	if tl.Build != 42 || !tl.Start.Equal(start) || tl.Duration() != 40*time.Minute {
+		t.Fatalf("New() = build %d from %s for %s, want build 42 from %s for 40m", tl.Build, tl.Start, tl.Duration(), start)
+	}
+	if len(tl.Steps) != 2 {
+		t.Fatalf("len(Steps) = %d, want 2", len(tl.Steps))
+	}
+	if s := tl.Steps[1]; s.Key != "test" || s.Label != "Test" || len(s.Jobs) != 2 {
+		t.Errorf("Steps[1] = %+v, want the two jobs of the test step", s)
+	}
+
+	j := tl.Steps[1].Jobs[0]
+	if j.Wait != 20*time.Minute || j.Run != 5*time.Minute || j.Queue != "large" || j.Agent != "agent-2" || !j.Retried {
+		t.Errorf("Steps[1].Jobs[0] = %+v, want 20m wait and 5m run on agent-2 in queue large", j)
+	}
+
+	wait, run := tl.Totals()
+	if wait != 25*time.Minute || run != 15*time.Minute {
+		t.Errorf("Totals() = %s, %s, want 25m0s, 15m0s", wait, run)
+	}
+}
+
+func TestNewRunningBuild(t *testing.T) {
+	t.Parallel()
+
+	b := buildkite.Build{
+		Number:    7,
+		CreatedAt: at(0),
+		Jobs: []buildkite.Job{
+			{ID: "running", Type: "script", Label: "Running", State: "running", ScheduledAt: at(0), StartedAt: at(2)},
+			{ID: "waiting", Type: "script", Label: "Waiting", State: "scheduled", ScheduledAt: at(1)},
+		},
+	}
+	tl := New(b, start.Add(10*time.Minute))
+
+	if tl.Duration() != 10*time.Minute {
+		t.Errorf("Duration() = %s, want 10m0s", tl.Duration())
+	}
+	if j := tl.Steps[0].Jobs[0]; j.Run != 8*time.Minute {
+		t.Errorf("running job Run = %s, want 8m0s", j.Run)
+	}
+	if j := tl.Steps[1].Jobs[0]; j.Wait != 9*time.Minute || j.Run != 0 {
+		t.Errorf("waiting job Wait, Run = %s, %s, want 9m0s, 0s", j.Wait, j.Run)
+	}
+}
+
+func TestNewWaitsFromRunnable(t *testing.T) {
+	t.Parallel()
+
+	// Every job is scheduled when the build is created; deploy only became
+	// runnable once test finished.
+	b := buildkite.Build{
+		Number:     8,
+		CreatedAt:  at(0),
+		FinishedAt: at(25),
+		Jobs: []buildkite.Job{
+			{ID: "test", Type: "script", Label: "Test", State: "passed", ScheduledAt: at(0), RunnableAt: at(0), StartedAt: at(1), FinishedAt: at(20)},
+			{ID: "deploy", Type: "script", Label: "Deploy", State: "passed", ScheduledAt: at(0), RunnableAt: at(20), StartedAt: at(22), FinishedAt: at(25)},
+		},
+	}
+	tl := New(b, start.Add(time.Hour))
+
+	if j := tl.Steps[1].Jobs[0]; j.Wait != 2*time.Minute || !j.RunnableAt.Equal(at(20).Time) {
+		t.Errorf("deploy Wait = %s from %s, want 2m0s from when it became runnable", j.Wait, j.RunnableAt)
+	}
+	if wait, _ := tl.Totals(); wait != 3*time.Minute {
+		t.Errorf("Totals() wait = %s, want 3m0s", wait)
+	}
+}
+
+func TestJobMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(Job{ID: "a", Wait: 90 * time.Second, Run: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"id":"a"`, `"wait_seconds":90`, `"run_seconds":60`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("MarshalJSON() = %s, want it to contain %s", data, want)
+		}
+	}
+}
+
+func TestJobMarshalYAML(t *testing.T) {
+	t.Parallel()
+
+	data, err := yaml.Marshal(Job{ID: "a", StepKey: "test", Wait: 90 * time.Second, Run: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"id: a\n", "step_key: test\n", "wait_seconds: 90\n", "run_seconds: 60\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("MarshalYAML() =\n%s\nwant it to contain %q", data, want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	if err := Write(&out, New(testBuild(), start), 40); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `Build #42 took 40m0s. Jobs waited 25m0s for agents and ran for 15m0s in total.
+░ waiting for an agent  █ running
+
+                  0s                                 40m0s
+Lint: fast (lint)
+  Lint: fast      ░░░░░█████                                wait 5m0s    run 5m0s    agent-1, queue default
+Test (test)
+  Test (retried)            ░░░░░░░░░░░░░░░░░░░░█████       wait 20m0s   run 5m0s    agent-2, queue large
+  Test                                               █████  wait 0s      run 5m0s    agent-3, queue large
+`
+	if got := out.String(); got != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	if err := WriteMermaid(&out, New(testBuild(), start)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `gantt
+    title Build 42
+    dateFormat YYYY-MM-DDTHH:mm:ss
+    axisFormat %H:%M:%S
+    section Lint fast
+    Lint fast (waiting) :done, j1w, 2025-03-01T10:00:00, 2025-03-01T10:05:00
+    Lint fast :active, j1r, 2025-03-01T10:05:00, 2025-03-01T10:10:00
+    section Test
+    Test (waiting) :done, j2w, 2025-03-01T10:10:00, 2025-03-01T10:30:00
+    Test :crit, j2r, 2025-03-01T10:30:00, 2025-03-01T10:35:00
+    Test :active, j3r, 2025-03-01T10:35:00, 2025-03-01T10:40:00
+`
+	if got := out.String(); got != want {
+		t.Errorf("WriteMermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := map[time.Duration]string{
+		0:                             "0s",
+		1500 * time.Millisecond:       "2s",
+		4*time.Minute + 5*time.Second: "4m5s",
+		time.Hour + 2*time.Minute + 3*time.Second: "1h2m3s",
+	}
+	for d, want := range tests {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
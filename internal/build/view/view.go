@@ -6,12 +6,18 @@ import (
 	"time"
 
 	"github.com/buildkite/cli/v3/internal/artifact"
+	"github.com/buildkite/cli/v3/internal/build/watch"
 	"github.com/buildkite/cli/v3/internal/emoji"
+	"github.com/buildkite/cli/v3/internal/job/classify"
 	"github.com/buildkite/cli/v3/internal/validation"
 	"github.com/buildkite/cli/v3/pkg/output"
 	buildkite "github.com/buildkite/go-buildkite/v5"
 )
 
+// maxCauseEvidence caps the evidence shown for each failure cause; bk job log
+// --classify shows the rest.
+const maxCauseEvidence = 3
+
 // ViewOptions represents options for viewing a build
 type ViewOptions struct {
 	Organization string
@@ -40,6 +46,14 @@ type BuildView struct {
 	Annotations  []buildkite.Annotation
 	Organization string
 	Pipeline     string
+
+	// TriggeredBuilds are the builds started by the build's trigger steps,
+	// when they have been followed.
+	TriggeredBuilds []*watch.BuildTree
+
+	// FailureCauses are the likely causes of the build's failed jobs, by job
+	// ID, when they have been classified.
+	FailureCauses map[string]classify.Result
 }
 
 // NewBuildView creates a new BuildView instance
@@ -103,9 +117,105 @@ func (v *BuildView) Render() string {
 		sb.WriteString(annotations)
 	}
 
+	if causes := renderFailureCauses(v.Build.Jobs, v.FailureCauses); causes != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(causes)
+	}
+
+	if triggered := RenderTriggeredBuilds(v.Build, v.TriggeredBuilds); triggered != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(triggered)
+	}
+
 	return sb.String()
 }
 
+// RenderTriggeredBuilds renders the builds started by b's trigger steps as a
+// tree, one line per build, labelled with the trigger step that started it.
+func RenderTriggeredBuilds(b *buildkite.Build, trees []*watch.BuildTree) string {
+	if b == nil || len(trees) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Triggered builds (%d)\n\n", countBuildTrees(trees))
+	renderBuildTrees(&sb, b.Jobs, trees, "")
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func renderBuildTrees(sb *strings.Builder, jobs []buildkite.Job, trees []*watch.BuildTree, indent string) {
+	for i, t := range trees {
+		branch, nested := "├─ ", "│  "
+		if i == len(trees)-1 {
+			branch, nested = "└─ ", "   "
+		}
+
+		line := fmt.Sprintf("%s/%s #%d %s", t.Organization, t.Pipeline, t.Build.Number, output.ValueOrDash(t.Build.State))
+		if t.RollupState != "" && t.RollupState != t.Build.State {
+			line += fmt.Sprintf(" (%s with triggered builds)", t.RollupState)
+		}
+		if t.Err != nil {
+			line += fmt.Sprintf(" (could not fetch: %v)", t.Err)
+		}
+		if label := jobLabelByID(jobs, t.TriggerJobID); label != "" {
+			line = emoji.Render(truncateText(label, 72)) + " → " + line
+		}
+
+		sb.WriteString(indent + branch + line + "\n")
+		renderBuildTrees(sb, t.Build.Jobs, t.Children, indent+nested)
+	}
+}
+
+// renderFailureCauses lists the likely cause of each classified job's
+// failure, in job order, with the first evidence for it.
+func renderFailureCauses(jobs []buildkite.Job, causes map[string]classify.Result) string {
+	if len(causes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Failure causes (%d)\n", len(causes))
+	for _, j := range jobs {
+		cause, ok := causes[j.ID]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s: %s\n", emoji.Render(truncateText(output.ValueOrDash(jobLabelByID(jobs, j.ID)), 72)), cause.Label)
+		for i, e := range cause.Evidence {
+			if i == maxCauseEvidence {
+				break
+			}
+			if e.Line > 0 {
+				fmt.Fprintf(&sb, "  %d: %s\n", e.Line, truncateText(strings.TrimSpace(e.Text), 100))
+			} else {
+				fmt.Fprintf(&sb, "  %s\n", e.Text)
+			}
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func jobLabelByID(jobs []buildkite.Job, id string) string {
+	for _, j := range jobs {
+		if j.ID != id {
+			continue
+		}
+		if j.Name != "" {
+			return j.Name
+		}
+		return j.Label
+	}
+	return ""
+}
+
+func countBuildTrees(trees []*watch.BuildTree) int {
+	n := len(trees)
+	for _, t := range trees {
+		n += countBuildTrees(t.Children)
+	}
+	return n
+}
+
 func buildSummary(b *buildkite.Build, organization, pipeline string) string {
 	if b == nil {
 		return fmt.Sprintf("Build %s/%s (no data available)\n", output.ValueOrDash(organization), output.ValueOrDash(pipeline))
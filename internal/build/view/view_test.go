@@ -4,6 +4,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/buildkite/cli/v3/internal/build/watch"
+	"github.com/buildkite/cli/v3/internal/job/classify"
 	buildkite "github.com/buildkite/go-buildkite/v5"
 )
 
@@ -162,3 +164,72 @@ func TestCreatorName_NoCreatorOrAuthor(t *testing.T) {
 		t.Errorf("Expected 'Unknown', got: %s", result)
 	}
 }
+
+func TestRenderTriggeredBuilds(t *testing.T) {
+	build := &buildkite.Build{
+		Jobs: []buildkite.Job{{ID: "trigger-deploy", Type: "trigger", Label: "Deploy"}},
+	}
+	trees := []*watch.BuildTree{{
+		Organization: "acme",
+		Pipeline:     "deploy",
+		TriggerJobID: "trigger-deploy",
+		Build: buildkite.Build{
+			Number: 12,
+			State:  "passed",
+			Jobs:   []buildkite.Job{{ID: "trigger-infra", Type: "trigger", Name: "Infra"}},
+		},
+		RollupState: "failed",
+		Children: []*watch.BuildTree{{
+			Organization: "acme",
+			Pipeline:     "infra",
+			TriggerJobID: "trigger-infra",
+			Build:        buildkite.Build{Number: 4, State: "failed"},
+			RollupState:  "failed",
+		}},
+	}}
+
+	result := RenderTriggeredBuilds(build, trees)
+
+	for _, want := range []string{
+		"Triggered builds (2)",
+		"└─ Deploy → acme/deploy #12 passed (failed with triggered builds)",
+		"   └─ Infra → acme/infra #4 failed",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected result to contain %q, got:\n%s", want, result)
+		}
+	}
+
+	if RenderTriggeredBuilds(build, nil) != "" {
+		t.Error("Expected no output without triggered builds")
+	}
+}
+
+func TestRenderFailureCauses(t *testing.T) {
+	jobs := []buildkite.Job{
+		{ID: "lint", Type: "script", Name: "Lint", State: "passed"},
+		{ID: "test", Type: "script", Name: ":go: Test", State: "failed"},
+	}
+	causes := map[string]classify.Result{
+		"test": {Category: "test", Label: "test assertion failure", Evidence: []classify.Evidence{
+			{Rule: "test-go", Line: 12, Text: "--- FAIL: TestThing (0.00s)"},
+			{Rule: "test-go", Line: 13, Text: "FAIL"},
+			{Rule: "test-go", Line: 14, Text: "FAIL\tgithub.com/acme/app"},
+			{Rule: "test-go", Line: 15, Text: "not shown"},
+		}},
+	}
+
+	got := renderFailureCauses(jobs, causes)
+	if !strings.HasPrefix(got, "Failure causes (1)") {
+		t.Errorf("expected a heading, got:\n%s", got)
+	}
+	if !strings.Contains(got, "Test: test assertion failure") || !strings.Contains(got, "  12: --- FAIL: TestThing (0.00s)") {
+		t.Errorf("expected the cause and its evidence, got:\n%s", got)
+	}
+	if strings.Contains(got, "not shown") {
+		t.Errorf("expected evidence to be capped at %d lines, got:\n%s", maxCauseEvidence, got)
+	}
+	if renderFailureCauses(jobs, nil) != "" {
+		t.Error("expected nothing without causes")
+	}
+}
@@ -67,3 +67,15 @@ func (j FormattedJob) IsRunning() bool {
 func (j FormattedJob) HasPromisedFailure() bool {
 	return j.PromisedExitStatus != nil && *j.PromisedExitStatus != 0
 }
+
+// HasRunningScriptJob reports whether the build has at least one still-running
+// script job. Used to avoid the extra jobs-index request when nothing is
+// running and so no promised failure could be declared yet.
+func HasRunningScriptJob(b buildkite.Build) bool {
+	for _, j := range b.Jobs {
+		if j.Type == "script" && isActiveState(j.State) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package watch
+
+import (
+	"fmt"
+	"time"
+
+	buildstate "github.com/buildkite/cli/v3/internal/build/state"
+	bkErrors "github.com/buildkite/cli/v3/internal/errors"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+// OutcomeError maps the final state of a watched build to a categorised error
+// so that the command's exit code reflects how the build finished:
+//
+//	passed                       nil (exit 0)
+//	failed                       ErrBuildFailed (exit 13)
+//	canceled, skipped, not_run   ErrBuildCanceled (exit 14)
+//	blocked                      ErrBuildBlocked (exit 15)
+//
+// Any other state means watching stopped before the build finished, which is
+// reported as ErrBuildTimedOut (exit 16).
+func OutcomeError(b buildkite.Build) error {
+	details := fmt.Sprintf("build #%d is %s", b.Number, b.State)
+
+	var suggestions []string
+	if b.WebURL != "" {
+		suggestions = append(suggestions, "View the build at "+b.WebURL)
+	}
+
+	switch buildstate.State(b.State) {
+	case buildstate.Passed:
+		return nil
+	case buildstate.Failed:
+		return bkErrors.NewBuildFailedError(nil, details, suggestions...)
+	case buildstate.Canceled, buildstate.Skipped, buildstate.NotRun:
+		return bkErrors.NewBuildCanceledError(nil, details, suggestions...)
+	case buildstate.Blocked:
+		return bkErrors.NewBuildBlockedError(nil, details, append([]string{"Unblock it with 'bk job unblock'"}, suggestions...)...)
+	default:
+		return bkErrors.NewBuildTimedOutError(nil, details, suggestions...)
+	}
+}
+
+// TimedOutError reports that a build was still in progress when the wait
+// timeout elapsed.
+func TimedOutError(b buildkite.Build, timeout time.Duration) error {
+	return bkErrors.NewBuildTimedOutError(
+		nil,
+		fmt.Sprintf("build #%d is still %s after %s", b.Number, b.State, timeout),
+		"Increase --timeout, or keep waiting with 'bk build watch'",
+	)
+}
@@ -0,0 +1,49 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	bkErrors "github.com/buildkite/cli/v3/internal/errors"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestOutcomeError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		state    string
+		wantCode int
+	}{
+		{state: "passed", wantCode: bkErrors.ExitCodeSuccess},
+		{state: "failed", wantCode: bkErrors.ExitCodeBuildFailed},
+		{state: "canceled", wantCode: bkErrors.ExitCodeBuildCanceled},
+		{state: "skipped", wantCode: bkErrors.ExitCodeBuildCanceled},
+		{state: "not_run", wantCode: bkErrors.ExitCodeBuildCanceled},
+		{state: "blocked", wantCode: bkErrors.ExitCodeBuildBlocked},
+		{state: "running", wantCode: bkErrors.ExitCodeBuildTimedOut},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			t.Parallel()
+
+			err := OutcomeError(buildkite.Build{Number: 42, State: tt.state})
+			if got := bkErrors.GetExitCodeForError(err); got != tt.wantCode {
+				t.Fatalf("exit code for %s = %d, want %d (err: %v)", tt.state, got, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestTimedOutError(t *testing.T) {
+	t.Parallel()
+
+	err := TimedOutError(buildkite.Build{Number: 42, State: "running"}, 5*time.Minute)
+	if !bkErrors.IsBuildTimedOut(err) {
+		t.Fatalf("expected timed out error, got %v", err)
+	}
+	if want := "build timed out: build #42 is still running after 5m0s"; err.Error() != want {
+		t.Fatalf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 
+	buildstate "github.com/buildkite/cli/v3/internal/build/state"
 	buildkite "github.com/buildkite/go-buildkite/v5"
 )
 
@@ -63,6 +64,10 @@ type BuildStatus struct {
 	TotalRunning         int
 	Summary              JobSummary
 	Build                buildkite.Build
+
+	// Triggered holds the builds started by trigger steps, as registered with
+	// SetTriggeredBuilds. Nil unless triggers are being followed.
+	Triggered []*BuildTree
 }
 
 // JobTracker tracks job state changes across polls.
@@ -75,6 +80,11 @@ type JobTracker struct {
 	// back to the client-side declaration heuristic. When non-nil (even empty),
 	// the server's verdict is trusted exclusively.
 	serverClassified map[string]bool
+
+	// triggered maps trigger job IDs to the builds they started. Trigger jobs
+	// with a known build are tracked like script jobs, with their state taken
+	// from the triggered build's rolled-up state.
+	triggered []*BuildTree
 }
 
 // NewJobTracker creates a new JobTracker.
@@ -97,11 +107,77 @@ func (t *JobTracker) SetServerClassifiedFailures(set map[string]bool) {
 	t.serverClassified = set
 }
 
+// SetTriggeredBuilds records the builds started by the build's trigger steps,
+// as returned by TriggerFollower. Call this before Update on each poll. The
+// matching trigger jobs are then tracked with their triggered build's state,
+// so a failure anywhere downstream is reported as a failure of the trigger
+// job — even for asynchronous triggers, whose own job state passes as soon as
+// the build is created.
+func (t *JobTracker) SetTriggeredBuilds(trees []*BuildTree) {
+	t.triggered = trees
+}
+
+// trackedJobs returns the jobs the tracker reports on: script jobs, plus
+// trigger jobs whose triggered build is known, with the state of each
+// trigger job replaced by that of its triggered build.
+func (t *JobTracker) trackedJobs(b buildkite.Build) []buildkite.Job {
+	byJob := make(map[string]*BuildTree, len(t.triggered))
+	for _, tree := range t.triggered {
+		byJob[tree.TriggerJobID] = tree
+	}
+
+	jobs := make([]buildkite.Job, 0, len(b.Jobs))
+	for _, j := range b.Jobs {
+		if tree, ok := byJob[j.ID]; ok && j.Type == "trigger" {
+			j.State = triggerJobState(tree.RollupState)
+			jobs = append(jobs, j)
+			continue
+		}
+		if j.Type == "script" {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs
+}
+
+// triggerJobState maps a triggered build's state onto the job state the
+// tracker classifies. A failing build maps to running, and is surfaced as a
+// promised failure of the trigger job.
+func triggerJobState(buildState string) string {
+	switch buildstate.State(buildState) {
+	case buildstate.Failing, buildstate.Canceling:
+		return "running"
+	case buildstate.Skipped, buildstate.NotRun:
+		return "skipped"
+	default:
+		// passed, failed, canceled, running, scheduled and blocked are
+		// shared between builds and jobs.
+		return buildState
+	}
+}
+
+// triggerFailing reports whether j is a trigger job whose triggered build is
+// failing but still in progress.
+func (t *JobTracker) triggerFailing(j buildkite.Job) bool {
+	if j.Type != "trigger" {
+		return false
+	}
+	for _, tree := range t.triggered {
+		if tree.TriggerJobID == j.ID {
+			return tree.RollupState == string(buildstate.Failing)
+		}
+	}
+	return false
+}
+
 // promisedFailing reports whether a still-running job should be surfaced as an
 // early (promised) failure. When server classification is available it is
 // trusted exclusively; otherwise it falls back to the raw client-side
 // declaration on the build payload.
 func (t *JobTracker) promisedFailing(job FormattedJob) bool {
+	if t.triggerFailing(job.Job) {
+		return true
+	}
 	if t.serverClassified != nil {
 		return t.serverClassified[job.ID]
 	}
@@ -112,11 +188,12 @@ func (t *JobTracker) promisedFailing(job FormattedJob) bool {
 func (t *JobTracker) Update(b buildkite.Build) BuildStatus {
 	var status BuildStatus
 	status.Build = b
+	status.Triggered = t.triggered
 
 	var running []buildkite.Job
 
-	for _, j := range b.Jobs {
-		if j.Type != "script" || j.State == "broken" {
+	for _, j := range t.trackedJobs(b) {
+		if j.State == "broken" {
 			continue
 		}
 		job := NewFormattedJob(j)
@@ -219,8 +296,8 @@ func sortJobsByStartTime(jobs []buildkite.Job) {
 
 func (t *JobTracker) summarize(b buildkite.Build) JobSummary {
 	var s JobSummary
-	for _, j := range b.Jobs {
-		if j.Type != "script" || j.Retried {
+	for _, j := range t.trackedJobs(b) {
+		if j.Retried {
 			continue
 		}
 		job := NewFormattedJob(j)
@@ -0,0 +1,199 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	buildstate "github.com/buildkite/cli/v3/internal/build/state"
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+// BuildTree is a build started by a trigger step, together with the builds its
+// own trigger steps started in turn.
+type BuildTree struct {
+	Organization string `json:"organization"`
+	Pipeline     string `json:"pipeline"`
+
+	// TriggerJobID is the ID of the trigger job, in the parent build, that
+	// started this build.
+	TriggerJobID string `json:"trigger_job_id"`
+
+	Build buildkite.Build `json:"build"`
+
+	// RollupState is the build's state combined with those of its triggered
+	// builds (see RollupState).
+	RollupState string `json:"rollup_state"`
+
+	Children []*BuildTree `json:"children,omitempty"`
+
+	// Err is set when the triggered build could not be fetched.
+	Err error `json:"-"`
+}
+
+// Finished reports whether the build and every build it triggered have
+// finished. Builds that could not be fetched count as finished so that
+// watching does not wait on them forever.
+func (t *BuildTree) Finished() bool {
+	if t.Err == nil && t.Build.FinishedAt == nil && !buildstate.IsTerminal(buildstate.State(t.Build.State)) {
+		return false
+	}
+	return TriggeredBuildsFinished(t.Children)
+}
+
+// TriggeredBuildsFinished reports whether every build in trees has finished.
+func TriggeredBuildsFinished(trees []*BuildTree) bool {
+	for _, c := range trees {
+		if !c.Finished() {
+			return false
+		}
+	}
+	return true
+}
+
+// RollupState combines a build's state with the rolled-up states of the
+// builds it triggered. A failed or canceled triggered build fails the parent:
+// "failed" once everything has finished, "failing" while anything is still in
+// progress. A parent that finished while its (asynchronously) triggered builds
+// are still in progress is reported as "running".
+func RollupState(state string, children []*BuildTree) string {
+	var failed, incomplete bool
+	for _, c := range children {
+		cs := buildstate.State(c.RollupState)
+		switch cs {
+		case buildstate.Failed, buildstate.Canceled:
+			failed = true
+		case buildstate.Failing:
+			failed, incomplete = true, true
+		default:
+			if buildstate.IsIncomplete(cs) && cs != buildstate.Blocked {
+				incomplete = true
+			}
+		}
+	}
+
+	own := buildstate.State(state)
+	parentTerminal := buildstate.IsTerminal(own)
+	switch {
+	case failed && parentTerminal && !incomplete:
+		return string(buildstate.Failed)
+	case failed:
+		return string(buildstate.Failing)
+	case incomplete && parentTerminal:
+		return string(buildstate.Running)
+	default:
+		return state
+	}
+}
+
+// TriggerFollower fetches the builds started by a build's trigger steps,
+// recursing up to a maximum depth. Finished builds are cached, so following
+// triggers on every poll only re-fetches builds that are still in progress.
+type TriggerFollower struct {
+	client   *buildkite.Client
+	depth    int
+	finished map[string]buildkite.Build
+}
+
+// NewTriggerFollower returns a TriggerFollower that follows triggers up to
+// depth levels below the starting build.
+func NewTriggerFollower(client *buildkite.Client, depth int) *TriggerFollower {
+	return &TriggerFollower{
+		client:   client,
+		depth:    depth,
+		finished: make(map[string]buildkite.Build),
+	}
+}
+
+// Triggered returns the builds started by b's trigger steps, in job order. A
+// nil TriggerFollower follows nothing.
+func (f *TriggerFollower) Triggered(ctx context.Context, b buildkite.Build) []*BuildTree {
+	if f == nil {
+		return nil
+	}
+	return f.children(ctx, b, 1)
+}
+
+func (f *TriggerFollower) children(ctx context.Context, b buildkite.Build, level int) []*BuildTree {
+	if level > f.depth {
+		return nil
+	}
+
+	var trees []*BuildTree
+	for _, j := range b.Jobs {
+		if j.Type != "trigger" || j.TriggeredBuild == nil || j.Retried {
+			continue
+		}
+		org, pipeline, number, ok := ParseTriggeredBuild(j.TriggeredBuild)
+		if !ok {
+			continue
+		}
+
+		tree := &BuildTree{Organization: org, Pipeline: pipeline, TriggerJobID: j.ID}
+		tree.Build, tree.Err = f.fetch(ctx, org, pipeline, number, j.TriggeredBuild.ID)
+		if tree.Err != nil {
+			tree.Build = buildkite.Build{ID: j.TriggeredBuild.ID, Number: number, WebURL: j.TriggeredBuild.WebURL}
+		} else {
+			tree.Children = f.children(ctx, tree.Build, level+1)
+		}
+		tree.RollupState = RollupState(tree.Build.State, tree.Children)
+		trees = append(trees, tree)
+	}
+	return trees
+}
+
+func (f *TriggerFollower) fetch(ctx context.Context, org, pipeline string, number int, id string) (buildkite.Build, error) {
+	if b, ok := f.finished[id]; ok && id != "" {
+		return b, nil
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, DefaultRequestTimeout)
+	defer cancel()
+	b, _, err := f.client.Builds.Get(reqCtx, org, pipeline, fmt.Sprint(number), &buildkite.BuildGetOptions{
+		BuildsListOptions: buildkite.BuildsListOptions{ExcludePipeline: true},
+	})
+	if err != nil {
+		return buildkite.Build{}, err
+	}
+	if b.FinishedAt != nil && b.ID != "" {
+		f.finished[b.ID] = b
+	}
+	return b, nil
+}
+
+// ParseTriggeredBuild extracts the organization, pipeline and build number of
+// a triggered build from its API URL, falling back to its web URL.
+func ParseTriggeredBuild(tb *buildkite.TriggeredBuild) (org, pipeline string, number int, ok bool) {
+	if tb == nil {
+		return "", "", 0, false
+	}
+
+	// https://api.buildkite.com/v2/organizations/{org}/pipelines/{pipeline}/builds/{number}
+	if parts := urlPathParts(tb.URL); len(parts) >= 7 && parts[1] == "organizations" && parts[3] == "pipelines" && parts[5] == "builds" {
+		if n, err := strconv.Atoi(parts[6]); err == nil {
+			return parts[2], parts[4], n, true
+		}
+	}
+
+	// https://buildkite.com/{org}/{pipeline}/builds/{number}
+	if parts := urlPathParts(tb.WebURL); len(parts) >= 4 && parts[2] == "builds" {
+		if n, err := strconv.Atoi(parts[3]); err == nil {
+			return parts[0], parts[1], n, true
+		}
+	}
+
+	return "", "", 0, false
+}
+
+func urlPathParts(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.Trim(u.Path, "/"), "/")
+}
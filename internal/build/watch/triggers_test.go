@@ -0,0 +1,213 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+func TestParseTriggeredBuild(t *testing.T) {
+	tests := []struct {
+		name         string
+		tb           *buildkite.TriggeredBuild
+		wantOrg      string
+		wantPipeline string
+		wantNumber   int
+		wantOK       bool
+	}{
+		{
+			name:         "api url",
+			tb:           &buildkite.TriggeredBuild{URL: "https://api.buildkite.com/v2/organizations/acme/pipelines/deploy/builds/12"},
+			wantOrg:      "acme",
+			wantPipeline: "deploy",
+			wantNumber:   12,
+			wantOK:       true,
+		},
+		{
+			name:         "web url fallback",
+			tb:           &buildkite.TriggeredBuild{WebURL: "https://buildkite.com/acme/deploy/builds/13"},
+			wantOrg:      "acme",
+			wantPipeline: "deploy",
+			wantNumber:   13,
+			wantOK:       true,
+		},
+		{name: "nil", tb: nil},
+		{name: "unrecognised url", tb: &buildkite.TriggeredBuild{WebURL: "https://buildkite.com/acme"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org, pipeline, number, ok := ParseTriggeredBuild(tt.tb)
+			if ok != tt.wantOK || org != tt.wantOrg || pipeline != tt.wantPipeline || number != tt.wantNumber {
+				t.Errorf("ParseTriggeredBuild() = %q, %q, %d, %v", org, pipeline, number, ok)
+			}
+		})
+	}
+}
+
+func TestRollupState(t *testing.T) {
+	child := func(state string) *BuildTree { return &BuildTree{RollupState: state} }
+
+	tests := []struct {
+		name     string
+		state    string
+		children []*BuildTree
+		want     string
+	}{
+		{name: "no children", state: "passed", want: "passed"},
+		{name: "passed children", state: "passed", children: []*BuildTree{child("passed")}, want: "passed"},
+		{name: "failed child of finished parent", state: "passed", children: []*BuildTree{child("failed")}, want: "failed"},
+		{name: "canceled child", state: "passed", children: []*BuildTree{child("canceled")}, want: "failed"},
+		{name: "failed child of running parent", state: "running", children: []*BuildTree{child("failed")}, want: "failing"},
+		{name: "failing child", state: "passed", children: []*BuildTree{child("failing")}, want: "failing"},
+		{name: "async child still running", state: "passed", children: []*BuildTree{child("running")}, want: "running"},
+		{name: "parent failure kept", state: "failed", children: []*BuildTree{child("passed")}, want: "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RollupState(tt.state, tt.children); got != tt.want {
+				t.Errorf("RollupState(%q) = %q, want %q", tt.state, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTriggerFollower(t *testing.T) {
+	finished := &buildkite.Timestamp{Time: time.Now()}
+	builds := map[string]buildkite.Build{
+		"/v2/organizations/acme/pipelines/deploy/builds/12": {
+			ID: "deploy-12", Number: 12, State: "passed", FinishedAt: finished,
+			Jobs: []buildkite.Job{{
+				ID:             "trigger-infra",
+				Type:           "trigger",
+				TriggeredBuild: &buildkite.TriggeredBuild{ID: "infra-4", WebURL: "https://buildkite.com/acme/infra/builds/4"},
+			}},
+		},
+		"/v2/organizations/acme/pipelines/infra/builds/4": {ID: "infra-4", Number: 4, State: "failed", FinishedAt: finished},
+	}
+	requests := make(map[string]int)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		b, ok := builds[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(b)
+	}))
+	defer s.Close()
+
+	root := buildkite.Build{
+		Number: 1,
+		State:  "running",
+		Jobs: []buildkite.Job{
+			{ID: "script", Type: "script", State: "passed"},
+			{ID: "trigger-deploy", Type: "trigger", State: "passed", TriggeredBuild: &buildkite.TriggeredBuild{
+				ID:  "deploy-12",
+				URL: "https://api.buildkite.com/v2/organizations/acme/pipelines/deploy/builds/12",
+			}},
+			{ID: "trigger-missing", Type: "trigger", State: "passed", TriggeredBuild: &buildkite.TriggeredBuild{
+				ID:     "missing",
+				WebURL: "https://buildkite.com/acme/missing/builds/1",
+			}},
+		},
+	}
+
+	t.Run("follows to depth and rolls up", func(t *testing.T) {
+		follower := NewTriggerFollower(newTestClient(t, s.URL), 2)
+		trees := follower.Triggered(context.Background(), root)
+		if len(trees) != 2 {
+			t.Fatalf("expected 2 triggered builds, got %d", len(trees))
+		}
+
+		deploy := trees[0]
+		if deploy.Pipeline != "deploy" || deploy.TriggerJobID != "trigger-deploy" || len(deploy.Children) != 1 {
+			t.Fatalf("unexpected deploy tree: %+v", deploy)
+		}
+		if deploy.Children[0].Pipeline != "infra" || deploy.Children[0].RollupState != "failed" {
+			t.Errorf("unexpected infra tree: %+v", deploy.Children[0])
+		}
+		if deploy.RollupState != "failed" {
+			t.Errorf("expected infra failure to roll up into deploy, got %q", deploy.RollupState)
+		}
+		if trees[1].Err == nil || !trees[1].Finished() {
+			t.Errorf("expected unfetchable build to carry an error and count as finished: %+v", trees[1])
+		}
+
+		// Finished builds are cached between polls.
+		follower.Triggered(context.Background(), root)
+		if n := requests["/v2/organizations/acme/pipelines/deploy/builds/12"]; n != 1 {
+			t.Errorf("expected finished build to be fetched once, got %d", n)
+		}
+	})
+
+	t.Run("stops at depth", func(t *testing.T) {
+		trees := NewTriggerFollower(newTestClient(t, s.URL), 1).Triggered(context.Background(), root)
+		if len(trees) == 0 || len(trees[0].Children) != 0 {
+			t.Fatalf("expected no grandchildren at depth 1, got %+v", trees)
+		}
+		if trees[0].RollupState != "passed" {
+			t.Errorf("expected unfollowed failures not to roll up, got %q", trees[0].RollupState)
+		}
+	})
+}
+
+func TestJobTracker_TriggeredBuilds(t *testing.T) {
+	build := buildkite.Build{
+		State: "passed",
+		Jobs: []buildkite.Job{
+			{ID: "script", Type: "script", State: "passed"},
+			{ID: "trigger", Type: "trigger", State: "passed"},
+			{ID: "untracked-trigger", Type: "trigger", State: "passed"},
+		},
+	}
+
+	tracker := NewJobTracker()
+	tracker.SetTriggeredBuilds([]*BuildTree{{TriggerJobID: "trigger", RollupState: "failing"}})
+	status := tracker.Update(build)
+	if len(status.NewlyPromisedFailure) != 1 || status.NewlyPromisedFailure[0].ID != "trigger" {
+		t.Fatalf("expected failing triggered build to surface as a promised failure, got %+v", status.NewlyPromisedFailure)
+	}
+	if status.Summary.Running != 1 || status.Summary.Passed != 1 {
+		t.Errorf("expected trigger job counted as running, got %+v", status.Summary)
+	}
+	if len(status.Triggered) != 1 {
+		t.Errorf("expected triggered builds on status, got %+v", status.Triggered)
+	}
+
+	tracker.SetTriggeredBuilds([]*BuildTree{{TriggerJobID: "trigger", RollupState: "failed"}})
+	status = tracker.Update(build)
+	if len(status.NewlyFailed) != 1 || status.NewlyFailed[0].ID != "trigger" || status.NewlyFailed[0].State != "failed" {
+		t.Fatalf("expected trigger job to fail with its triggered build, got %+v", status.NewlyFailed)
+	}
+	if failed := tracker.FailedJobs(); len(failed) != 1 || failed[0].ID != "trigger" {
+		t.Errorf("expected trigger job in FailedJobs, got %+v", failed)
+	}
+}
+
+func TestWatchBuild_WithUntil(t *testing.T) {
+	polls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(buildkite.Build{Number: 1, State: "passed", FinishedAt: &buildkite.Timestamp{Time: time.Now()}})
+	}))
+	defer s.Close()
+
+	_, err := WatchBuild(context.Background(), newTestClient(t, s.URL), "org", "pipe", 1, time.Millisecond, nil, WithUntil(func(buildkite.Build) bool {
+		return polls >= 3
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if polls != 3 {
+		t.Errorf("expected polling to continue until done, got %d polls", polls)
+	}
+}
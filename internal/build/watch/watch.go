@@ -27,6 +27,7 @@ type WatchOpt func(*watchConfig)
 
 type watchConfig struct {
 	includeRetriedJobs bool
+	until              func(buildkite.Build) bool
 }
 
 // WithRetriedJobs includes retried (superseded) jobs in each poll so the
@@ -37,6 +38,15 @@ func WithRetriedJobs() WatchOpt {
 	}
 }
 
+// WithUntil keeps polling after the build itself has finished until done
+// returns true, e.g. while builds started by its trigger steps are still
+// running. done is called after onStatus for each poll of a finished build.
+func WithUntil(done func(b buildkite.Build) bool) WatchOpt {
+	return func(c *watchConfig) {
+		c.until = done
+	}
+}
+
 // WatchBuild polls a build until it reaches a terminal state (FinishedAt != nil).
 // It calls onStatus after each successful poll so callers can render progress.
 func WatchBuild(
@@ -89,7 +99,8 @@ func WatchBuild(
 				}
 			}
 
-			if b.FinishedAt != nil || buildstate.IsTerminal(buildstate.State(b.State)) {
+			finished := b.FinishedAt != nil || buildstate.IsTerminal(buildstate.State(b.State))
+			if finished && (cfg.until == nil || cfg.until(b)) {
 				return b, nil
 			}
 		}
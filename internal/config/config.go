@@ -65,6 +65,7 @@ type fileConfig struct {
 	Telemetry       *bool                `yaml:"telemetry,omitempty"`
 	Experiments     string               `yaml:"experiments,omitempty"`
 	CredentialStore string               `yaml:"credential_store,omitempty"`
+	RedactPatterns  []string             `yaml:"redact_patterns,omitempty"`
 }
 
 // Config contains the configuration for the currently selected organization
@@ -485,6 +486,12 @@ func (conf *Config) PreferredPipelines() []pipeline.Pipeline {
 	return pipelines
 }
 
+// RedactPatterns returns the extra regular expressions to mask when log
+// output is redacted with --redact, from both local and user config.
+func (conf *Config) RedactPatterns() []string {
+	return slices.Concat(conf.local.RedactPatterns, conf.user.RedactPatterns)
+}
+
 // SetPreferredPipelines will write the provided list of pipelines to local configuration
 func (conf *Config) SetPreferredPipelines(pipelines []pipeline.Pipeline) error {
 	// only save pipelines if they are present
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -324,6 +325,20 @@ func TestConfig(t *testing.T) {
 			}
 		})
 	})
+
+	t.Run("RedactPatterns reads local config", func(t *testing.T) {
+		t.Parallel()
+
+		fs := afero.NewMemMapFs()
+		if err := afero.WriteFile(fs, localConfigFilePath, []byte("redact_patterns:\n  - acme_[0-9a-f]{32}\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		conf := New(fs, nil)
+
+		if got := conf.RedactPatterns(); !slices.Equal(got, []string{"acme_[0-9a-f]{32}"}) {
+			t.Errorf("RedactPatterns() = %v, want the local pattern", got)
+		}
+	})
 }
 
 func TestAPITokenForOrgNoKeyring(t *testing.T) {
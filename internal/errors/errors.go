@@ -46,6 +46,18 @@ var (
 
 	// ErrPreflightUnknown indicates a preflight build returned an unknown result.
 	ErrPreflightUnknown = errors.New("preflight result unknown")
+
+	// ErrBuildFailed indicates a watched build finished with a failed state.
+	ErrBuildFailed = errors.New("build failed")
+
+	// ErrBuildCanceled indicates a watched build was canceled or did not run.
+	ErrBuildCanceled = errors.New("build canceled")
+
+	// ErrBuildBlocked indicates a watched build stopped at a block step.
+	ErrBuildBlocked = errors.New("build blocked")
+
+	// ErrBuildTimedOut indicates a build did not finish before the wait timeout elapsed.
+	ErrBuildTimedOut = errors.New("build timed out")
 )
 
 // Error represents a CLI error with context
@@ -241,6 +253,26 @@ func NewPreflightUnknownError(err error, details string, suggestions ...string)
 	return NewError(err, ErrPreflightUnknown, details, suggestions...)
 }
 
+// NewBuildFailedError creates a new failed build error.
+func NewBuildFailedError(err error, details string, suggestions ...string) error {
+	return NewError(err, ErrBuildFailed, details, suggestions...)
+}
+
+// NewBuildCanceledError creates a new canceled build error.
+func NewBuildCanceledError(err error, details string, suggestions ...string) error {
+	return NewError(err, ErrBuildCanceled, details, suggestions...)
+}
+
+// NewBuildBlockedError creates a new blocked build error.
+func NewBuildBlockedError(err error, details string, suggestions ...string) error {
+	return NewError(err, ErrBuildBlocked, details, suggestions...)
+}
+
+// NewBuildTimedOutError creates a new build wait timeout error.
+func NewBuildTimedOutError(err error, details string, suggestions ...string) error {
+	return NewError(err, ErrBuildTimedOut, details, suggestions...)
+}
+
 // IsNotFound returns true if the error indicates a resource was not found
 func IsNotFound(err error) bool {
 	return errors.Is(err, ErrResourceNotFound)
@@ -291,6 +323,26 @@ func IsPreflightUnknown(err error) bool {
 	return errors.Is(err, ErrPreflightUnknown)
 }
 
+// IsBuildFailed returns true if the error indicates a watched build failed.
+func IsBuildFailed(err error) bool {
+	return errors.Is(err, ErrBuildFailed)
+}
+
+// IsBuildCanceled returns true if the error indicates a watched build was canceled.
+func IsBuildCanceled(err error) bool {
+	return errors.Is(err, ErrBuildCanceled)
+}
+
+// IsBuildBlocked returns true if the error indicates a watched build is blocked.
+func IsBuildBlocked(err error) bool {
+	return errors.Is(err, ErrBuildBlocked)
+}
+
+// IsBuildTimedOut returns true if the error indicates waiting for a build timed out.
+func IsBuildTimedOut(err error) bool {
+	return errors.Is(err, ErrBuildTimedOut)
+}
+
 // IsUserAborted returns true if the error indicates the user aborted the operation
 func IsUserAborted(err error) bool {
 	return errors.Is(err, ErrUserAborted)
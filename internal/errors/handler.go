@@ -24,6 +24,10 @@ const (
 	ExitCodePreflightIncompleteFailure = 10
 	ExitCodePreflightIncomplete        = 11
 	ExitCodePreflightUnknown           = 12
+	ExitCodeBuildFailed                = 13
+	ExitCodeBuildCanceled              = 14
+	ExitCodeBuildBlocked               = 15
+	ExitCodeBuildTimedOut              = 16
 	ExitCodeUserAbortedError           = 130 // Same as Ctrl+C in bash
 )
 
@@ -108,6 +112,14 @@ func (h *Handler) getExitCode(err error) int {
 		return ExitCodePreflightIncomplete
 	case IsPreflightUnknown(err):
 		return ExitCodePreflightUnknown
+	case IsBuildFailed(err):
+		return ExitCodeBuildFailed
+	case IsBuildCanceled(err):
+		return ExitCodeBuildCanceled
+	case IsBuildBlocked(err):
+		return ExitCodeBuildBlocked
+	case IsBuildTimedOut(err):
+		return ExitCodeBuildTimedOut
 	case IsUserAborted(err):
 		return ExitCodeUserAbortedError
 	case errors.Is(err, ErrInternal):
@@ -171,6 +183,14 @@ func (h *Handler) getCategoryPrefix(category error) string {
 		return "Preflight Incomplete:"
 	case ErrPreflightUnknown:
 		return "Preflight Unknown Result:"
+	case ErrBuildFailed:
+		return "Build Failed:"
+	case ErrBuildCanceled:
+		return "Build Canceled:"
+	case ErrBuildBlocked:
+		return "Build Blocked:"
+	case ErrBuildTimedOut:
+		return "Build Timed Out:"
 	case ErrUserAborted:
 		return "Aborted:"
 	case ErrInternal:
@@ -81,6 +81,30 @@ func TestHandler(t *testing.T) {
 				expectedPrefix: "Not Found:",
 				expectedCode:   ExitCodeNotFoundError,
 			},
+			{
+				name:           "build failed error",
+				err:            NewBuildFailedError(nil, "build #42 failed"),
+				expectedPrefix: "Build Failed:",
+				expectedCode:   ExitCodeBuildFailed,
+			},
+			{
+				name:           "build canceled error",
+				err:            NewBuildCanceledError(nil, "build #42 canceled"),
+				expectedPrefix: "Build Canceled:",
+				expectedCode:   ExitCodeBuildCanceled,
+			},
+			{
+				name:           "build blocked error",
+				err:            NewBuildBlockedError(nil, "build #42 blocked"),
+				expectedPrefix: "Build Blocked:",
+				expectedCode:   ExitCodeBuildBlocked,
+			},
+			{
+				name:           "build timed out error",
+				err:            NewBuildTimedOutError(nil, "build #42 still running"),
+				expectedPrefix: "Build Timed Out:",
+				expectedCode:   ExitCodeBuildTimedOut,
+			},
 			{
 				name:           "simple error",
 				err:            fmt.Errorf("simple error"),
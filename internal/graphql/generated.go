@@ -2085,6 +2085,371 @@ func (v *FindUserByEmailResponse) GetOrganization() *FindUserByEmailOrganization
 	return v.Organization
 }
 
+// GetBuildStepDependenciesBuild includes the requested fields of the GraphQL type Build.
+// The GraphQL type's documentation follows.
+//
+// A build from a pipeline
+type GetBuildStepDependenciesBuild struct {
+	Jobs *GetBuildStepDependenciesBuildJobsJobConnection `json:"jobs"`
+}
+
+// GetJobs returns GetBuildStepDependenciesBuild.Jobs, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuild) GetJobs() *GetBuildStepDependenciesBuildJobsJobConnection {
+	return v.Jobs
+}
+
+// GetBuildStepDependenciesBuildJobsJobConnection includes the requested fields of the GraphQL type JobConnection.
+type GetBuildStepDependenciesBuildJobsJobConnection struct {
+	Edges    []*GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge `json:"edges"`
+	PageInfo *GetBuildStepDependenciesBuildJobsJobConnectionPageInfo       `json:"pageInfo"`
+}
+
+// GetEdges returns GetBuildStepDependenciesBuildJobsJobConnection.Edges, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnection) GetEdges() []*GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge {
+	return v.Edges
+}
+
+// GetPageInfo returns GetBuildStepDependenciesBuildJobsJobConnection.PageInfo, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnection) GetPageInfo() *GetBuildStepDependenciesBuildJobsJobConnectionPageInfo {
+	return v.PageInfo
+}
+
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge includes the requested fields of the GraphQL type JobEdge.
+type GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge struct {
+	Node *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob `json:"-"`
+}
+
+// GetNode returns GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge.Node, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge) GetNode() *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob {
+	return v.Node
+}
+
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge) UnmarshalJSON(b []byte) error {
+
+	if string(b) == "null" {
+		return nil
+	}
+
+	var firstPass struct {
+		*GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge
+		Node json.RawMessage `json:"node"`
+		graphql.NoUnmarshalJSON
+	}
+	firstPass.GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge = v
+
+	err := json.Unmarshal(b, &firstPass)
+	if err != nil {
+		return err
+	}
+
+	{
+		dst := &v.Node
+		src := firstPass.Node
+		if len(src) != 0 && string(src) != "null" {
+			*dst = new(GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob)
+			err = __unmarshalGetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob(
+				src, *dst)
+			if err != nil {
+				return fmt.Errorf(
+					"unable to unmarshal GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge.Node: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+type __premarshalGetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge struct {
+	Node json.RawMessage `json:"node"`
+}
+
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge) MarshalJSON() ([]byte, error) {
+	premarshaled, err := v.__premarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(premarshaled)
+}
+
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge) __premarshalJSON() (*__premarshalGetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge, error) {
+	var retval __premarshalGetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge
+
+	{
+
+		dst := &retval.Node
+		src := v.Node
+		if src != nil {
+			var err error
+			*dst, err = __marshalGetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob(
+				src)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"unable to marshal GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdge.Node: %w", err)
+			}
+		}
+	}
+	return &retval, nil
+}
+
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob includes the requested fields of the GraphQL interface Job.
+//
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob is implemented by the following types:
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeBlock
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeTrigger
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeWait
+// The GraphQL type's documentation follows.
+//
+// Kinds of jobs that can exist on a build
+type GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob interface {
+	implementsGraphQLInterfaceGetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob()
+	// GetTypename returns the receiver's concrete GraphQL type-name (see interface doc for possible values).
+	GetTypename() *string
+}
+
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeBlock) implementsGraphQLInterfaceGetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob() {
+}
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand) implementsGraphQLInterfaceGetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob() {
+}
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeTrigger) implementsGraphQLInterfaceGetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob() {
+}
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeWait) implementsGraphQLInterfaceGetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob() {
+}
+
+func __unmarshalGetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob(b []byte, v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob) error {
+	if string(b) == "null" {
+		return nil
+	}
+
+	var tn struct {
+		TypeName string `json:"__typename"`
+	}
+	err := json.Unmarshal(b, &tn)
+	if err != nil {
+		return err
+	}
+
+	switch tn.TypeName {
+	case "JobTypeBlock":
+		*v = new(GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeBlock)
+		return json.Unmarshal(b, *v)
+	case "JobTypeCommand":
+		*v = new(GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand)
+		return json.Unmarshal(b, *v)
+	case "JobTypeTrigger":
+		*v = new(GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeTrigger)
+		return json.Unmarshal(b, *v)
+	case "JobTypeWait":
+		*v = new(GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeWait)
+		return json.Unmarshal(b, *v)
+	case "":
+		return fmt.Errorf(
+			"response was missing Job.__typename")
+	default:
+		return fmt.Errorf(
+			`unexpected concrete type for GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob: "%v"`, tn.TypeName)
+	}
+}
+
+func __marshalGetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob(v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob) ([]byte, error) {
+
+	var typename string
+	switch v := (*v).(type) {
+	case *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeBlock:
+		typename = "JobTypeBlock"
+
+		result := struct {
+			TypeName string `json:"__typename"`
+			*GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeBlock
+		}{typename, v}
+		return json.Marshal(result)
+	case *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand:
+		typename = "JobTypeCommand"
+
+		result := struct {
+			TypeName string `json:"__typename"`
+			*GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand
+		}{typename, v}
+		return json.Marshal(result)
+	case *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeTrigger:
+		typename = "JobTypeTrigger"
+
+		result := struct {
+			TypeName string `json:"__typename"`
+			*GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeTrigger
+		}{typename, v}
+		return json.Marshal(result)
+	case *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeWait:
+		typename = "JobTypeWait"
+
+		result := struct {
+			TypeName string `json:"__typename"`
+			*GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeWait
+		}{typename, v}
+		return json.Marshal(result)
+	case nil:
+		return []byte("null"), nil
+	default:
+		return nil, fmt.Errorf(
+			`unexpected concrete type for GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJob: "%T"`, v)
+	}
+}
+
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeBlock includes the requested fields of the GraphQL type JobTypeBlock.
+// The GraphQL type's documentation follows.
+//
+// A type of job that requires a user to unblock it before proceeding in a build pipeline
+type GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeBlock struct {
+	Typename *string `json:"__typename"`
+}
+
+// GetTypename returns GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeBlock.Typename, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeBlock) GetTypename() *string {
+	return v.Typename
+}
+
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand includes the requested fields of the GraphQL type JobTypeCommand.
+// The GraphQL type's documentation follows.
+//
+// A type of job that runs a command on an agent
+type GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand struct {
+	Typename *string `json:"__typename"`
+	// The UUID for this job
+	Uuid string `json:"uuid"`
+	// The step that defined this job
+	Step *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommand `json:"step"`
+}
+
+// GetTypename returns GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand.Typename, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand) GetTypename() *string {
+	return v.Typename
+}
+
+// GetUuid returns GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand.Uuid, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand) GetUuid() string {
+	return v.Uuid
+}
+
+// GetStep returns GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand.Step, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommand) GetStep() *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommand {
+	return v.Step
+}
+
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommand includes the requested fields of the GraphQL type StepCommand.
+// The GraphQL type's documentation follows.
+//
+// A step in a build that runs a command on an agent
+type GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommand struct {
+	// The UUID for this step
+	Uuid string `json:"uuid"`
+	// Dependencies of this job
+	Dependencies *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnection `json:"dependencies"`
+}
+
+// GetUuid returns GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommand.Uuid, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommand) GetUuid() string {
+	return v.Uuid
+}
+
+// GetDependencies returns GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommand.Dependencies, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommand) GetDependencies() *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnection {
+	return v.Dependencies
+}
+
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnection includes the requested fields of the GraphQL type DependencyConnection.
+// The GraphQL type's documentation follows.
+//
+// The connection type for Dependency.
+type GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnection struct {
+	// A list of edges.
+	Edges []*GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnectionEdgesDependencyEdge `json:"edges"`
+}
+
+// GetEdges returns GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnection.Edges, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnection) GetEdges() []*GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnectionEdgesDependencyEdge {
+	return v.Edges
+}
+
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnectionEdgesDependencyEdge includes the requested fields of the GraphQL type DependencyEdge.
+// The GraphQL type's documentation follows.
+//
+// An edge in a connection.
+type GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnectionEdgesDependencyEdge struct {
+	// The item at the end of the edge.
+	Node *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnectionEdgesDependencyEdgeNodeDependency `json:"node"`
+}
+
+// GetNode returns GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnectionEdgesDependencyEdge.Node, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnectionEdgesDependencyEdge) GetNode() *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnectionEdgesDependencyEdgeNodeDependency {
+	return v.Node
+}
+
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnectionEdgesDependencyEdgeNodeDependency includes the requested fields of the GraphQL type Dependency.
+type GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnectionEdgesDependencyEdgeNodeDependency struct {
+	// The step key or step identifier that this step depends on
+	Key *string `json:"key"`
+}
+
+// GetKey returns GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnectionEdgesDependencyEdgeNodeDependency.Key, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeCommandStepStepCommandDependenciesDependencyConnectionEdgesDependencyEdgeNodeDependency) GetKey() *string {
+	return v.Key
+}
+
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeTrigger includes the requested fields of the GraphQL type JobTypeTrigger.
+// The GraphQL type's documentation follows.
+//
+// A type of job that triggers another build on a pipeline
+type GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeTrigger struct {
+	Typename *string `json:"__typename"`
+}
+
+// GetTypename returns GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeTrigger.Typename, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeTrigger) GetTypename() *string {
+	return v.Typename
+}
+
+// GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeWait includes the requested fields of the GraphQL type JobTypeWait.
+// The GraphQL type's documentation follows.
+//
+// A type of job that waits for all previous jobs to pass before proceeding the build pipeline
+type GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeWait struct {
+	Typename *string `json:"__typename"`
+}
+
+// GetTypename returns GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeWait.Typename, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionEdgesJobEdgeNodeJobTypeWait) GetTypename() *string {
+	return v.Typename
+}
+
+// GetBuildStepDependenciesBuildJobsJobConnectionPageInfo includes the requested fields of the GraphQL type PageInfo.
+// The GraphQL type's documentation follows.
+//
+// Information about pagination in a connection.
+type GetBuildStepDependenciesBuildJobsJobConnectionPageInfo struct {
+	// When paginating forwards, are there more items?
+	HasNextPage bool `json:"hasNextPage"`
+	// When paginating forwards, the cursor to continue.
+	EndCursor *string `json:"endCursor"`
+}
+
+// GetHasNextPage returns GetBuildStepDependenciesBuildJobsJobConnectionPageInfo.HasNextPage, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionPageInfo) GetHasNextPage() bool {
+	return v.HasNextPage
+}
+
+// GetEndCursor returns GetBuildStepDependenciesBuildJobsJobConnectionPageInfo.EndCursor, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesBuildJobsJobConnectionPageInfo) GetEndCursor() *string {
+	return v.EndCursor
+}
+
+// GetBuildStepDependenciesResponse is returned by GetBuildStepDependencies on success.
+type GetBuildStepDependenciesResponse struct {
+	// Find a build
+	Build *GetBuildStepDependenciesBuild `json:"build"`
+}
+
+// GetBuild returns GetBuildStepDependenciesResponse.Build, and is useful for accessing the field via an interface.
+func (v *GetBuildStepDependenciesResponse) GetBuild() *GetBuildStepDependenciesBuild { return v.Build }
+
 // GetClusterQueueAgentOrganization includes the requested fields of the GraphQL type Organization.
 // The GraphQL type's documentation follows.
 //
@@ -3867,6 +4232,18 @@ func (v *__FindUserByEmailInput) GetOrganization() string { return v.Organizatio
 // GetEmail returns __FindUserByEmailInput.Email, and is useful for accessing the field via an interface.
 func (v *__FindUserByEmailInput) GetEmail() string { return v.Email }
 
+// __GetBuildStepDependenciesInput is used internally by genqlient
+type __GetBuildStepDependenciesInput struct {
+	Slug   string  `json:"slug"`
+	Cursor *string `json:"cursor"`
+}
+
+// GetSlug returns __GetBuildStepDependenciesInput.Slug, and is useful for accessing the field via an interface.
+func (v *__GetBuildStepDependenciesInput) GetSlug() string { return v.Slug }
+
+// GetCursor returns __GetBuildStepDependenciesInput.Cursor, and is useful for accessing the field via an interface.
+func (v *__GetBuildStepDependenciesInput) GetCursor() *string { return v.Cursor }
+
 // __GetClusterQueueAgentInput is used internally by genqlient
 type __GetClusterQueueAgentInput struct {
 	OrgSlug string   `json:"orgSlug"`
@@ -4182,6 +4559,65 @@ func FindUserByEmail(
 	return data_, err_
 }
 
+// The query executed by GetBuildStepDependencies.
+const GetBuildStepDependencies_Operation = `
+query GetBuildStepDependencies ($slug: ID!, $cursor: String) {
+	build(slug: $slug) {
+		jobs(first: 100, after: $cursor, type: [COMMAND]) {
+			edges {
+				node {
+					__typename
+					... on JobTypeCommand {
+						uuid
+						step {
+							uuid
+							dependencies(first: 100) {
+								edges {
+									node {
+										key
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+			pageInfo {
+				hasNextPage
+				endCursor
+			}
+		}
+	}
+}
+`
+
+func GetBuildStepDependencies(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	slug string,
+	cursor *string,
+) (data_ *GetBuildStepDependenciesResponse, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "GetBuildStepDependencies",
+		Query:  GetBuildStepDependencies_Operation,
+		Variables: &__GetBuildStepDependenciesInput{
+			Slug:   slug,
+			Cursor: cursor,
+		},
+	}
+
+	data_ = &GetBuildStepDependenciesResponse{}
+	resp_ := &graphql.Response{Data: data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return data_, err_
+}
+
 // The query executed by GetClusterQueueAgent.
 const GetClusterQueueAgent_Operation = `
 query GetClusterQueueAgent ($orgSlug: ID!, $queueId: [ID!]) {
@@ -0,0 +1,151 @@
+package io
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Batch describes doing the same thing to many items, a few at a time, such
+// as stopping agents or retrying jobs.
+type Batch struct {
+	// Label is shown before the progress bar, e.g. "Stopping agents".
+	Label string
+	// Noun is what each item is, e.g. "agent".
+	Noun string
+	// Verb is what is done to each item, e.g. "stop", and Done its past
+	// tense, e.g. "stopped".
+	Verb string
+	Done string
+	// Limit is how many items are worked on at once.
+	Limit int
+	// Out receives the progress line, and the summary if nothing failed.
+	// ErrOut receives the failures and the summary if anything did.
+	Out    io.Writer
+	ErrOut io.Writer
+	// TTY redraws the progress line in place rather than writing a line for
+	// each update.
+	TTY bool
+	// Quiet leaves out the progress line and summary, but not failures.
+	Quiet bool
+}
+
+type batchResult struct {
+	name string
+	err  error
+}
+
+// RunBatch calls do for each of items, with at most b.Limit calls at once,
+// drawing a progress line as each finishes and a summary once they all have.
+// name describes an item in failure messages. Once ctx is done no more items
+// are started, and it returns how many were.
+//
+// It returns an error if any item that was started failed.
+func RunBatch[T any](ctx context.Context, b Batch, items []T, name func(T) string, do func(context.Context, T) error) (int, error) {
+	total := len(items)
+	workerCount := min(max(b.Limit, 1), total)
+
+	work := make(chan T, workerCount)
+	updates := make(chan batchResult, workerCount)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range work {
+				if ctx.Err() != nil {
+					updates <- batchResult{name: name(item), err: ctx.Err()}
+					continue
+				}
+				updates <- batchResult{name: name(item), err: do(ctx, item)}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(work)
+		for _, item := range items {
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case work <- item:
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(updates)
+	}()
+
+	printProgress := func(completed, succeeded, failed int) {
+		if b.Quiet {
+			return
+		}
+		line := ProgressLine(b.Label, completed, total, succeeded, failed, 24)
+		switch {
+		case !b.TTY:
+			fmt.Fprintln(b.Out, line)
+		case completed == 0:
+			fmt.Fprint(b.Out, line)
+		default:
+			fmt.Fprintf(b.Out, "\r%s", line)
+		}
+	}
+
+	succeeded, failed, completed := 0, 0, 0
+	var errorDetails []string
+	printProgress(completed, succeeded, failed)
+	for update := range updates {
+		completed++
+		if update.err != nil {
+			failed++
+			errorDetails = append(errorDetails, fmt.Sprintf("FAILED %s: %v", update.name, update.err))
+		} else {
+			succeeded++
+		}
+		printProgress(completed, succeeded, failed)
+	}
+	if !b.Quiet && b.TTY {
+		fmt.Fprintln(b.Out)
+	}
+
+	summaryWriter := b.Out
+	if failed > 0 {
+		summaryWriter = b.ErrOut
+	}
+	if len(errorDetails) > 0 {
+		fmt.Fprintln(summaryWriter)
+		for _, detail := range errorDetails {
+			fmt.Fprintln(summaryWriter, detail)
+		}
+	}
+
+	if !b.Quiet {
+		noun := Pluralize(b.Noun, total)
+		if failed > 0 {
+			fmt.Fprintf(summaryWriter, "\n%s %d of %d %s (%d %s failed)\n", strings.ToUpper(b.Done[:1])+b.Done[1:], succeeded, total, noun, failed, Pluralize(b.Noun, failed))
+		} else {
+			fmt.Fprintf(summaryWriter, "\nSuccessfully %s %d of %d %s\n", b.Done, succeeded, total, noun)
+		}
+	}
+
+	if failed > 0 {
+		return completed, fmt.Errorf("failed to %s %d of %d %s (see above for details)", b.Verb, failed, total, Pluralize(b.Noun, total))
+	}
+	return completed, nil
+}
+
+// Pluralize returns word, with an "s" on the end unless count is one.
+func Pluralize(word string, count int) string {
+	if count == 1 {
+		return word
+	}
+	return word + "s"
+}
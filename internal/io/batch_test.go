@@ -0,0 +1,123 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func testBatch(out, errOut *strings.Builder) Batch {
+	return Batch{
+		Label:  "Stopping agents",
+		Noun:   "agent",
+		Verb:   "stop",
+		Done:   "stopped",
+		Limit:  2,
+		Out:    out,
+		ErrOut: errOut,
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	t.Parallel()
+
+	var out, errOut strings.Builder
+	var inFlight, most atomic.Int32
+	started, err := RunBatch(context.Background(), testBatch(&out, &errOut), []string{"a", "b", "c", "d"}, func(s string) string { return s }, func(_ context.Context, s string) error {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := most.Load()
+			if n <= m || most.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		if s == "c" {
+			return errors.New("not found")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "failed to stop 1 of 4 agents (see above for details)" {
+		t.Fatalf("RunBatch() error = %v, want 1 of 4 failed", err)
+	}
+	if started != 4 {
+		t.Errorf("RunBatch() started %d, want 4", started)
+	}
+	if most.Load() > 2 {
+		t.Errorf("%d calls at once, want at most 2", most.Load())
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || lines[4] != "Stopping agents [████████████████████████] 100% 4/4 succeeded:3 failed:1" {
+		t.Errorf("progress =\n%s\nwant 5 lines ending at 4/4", out.String())
+	}
+	if want := "\nFAILED c: not found\n\nStopped 3 of 4 agents (1 agent failed)\n"; errOut.String() != want {
+		t.Errorf("summary = %q, want %q", errOut.String(), want)
+	}
+}
+
+func TestRunBatchSucceeded(t *testing.T) {
+	t.Parallel()
+
+	var out, errOut strings.Builder
+	b := testBatch(&out, &errOut)
+	b.TTY = true
+	if _, err := RunBatch(context.Background(), b, []string{"a"}, func(s string) string { return s }, func(context.Context, string) error { return nil }); err != nil {
+		t.Fatalf("RunBatch() error = %v", err)
+	}
+
+	want := "Stopping agents [░░░░░░░░░░░░░░░░░░░░░░░░]   0% 0/1 succeeded:0 failed:0" +
+		"\rStopping agents [████████████████████████] 100% 1/1 succeeded:1 failed:0\n" +
+		"\nSuccessfully stopped 1 of 1 agent\n"
+	if out.String() != want {
+		t.Errorf("RunBatch() output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestRunBatchCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out, errOut strings.Builder
+	b := testBatch(&out, &errOut)
+	b.Quiet = true
+
+	var calls atomic.Int32
+	started, err := RunBatch(ctx, b, []string{"a", "b", "c"}, func(s string) string { return s }, func(context.Context, string) error {
+		calls.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunBatch() error = %v", err)
+	}
+	if started != 0 || calls.Load() != 0 {
+		t.Errorf("RunBatch() started %d and made %d calls, want none once canceled", started, calls.Load())
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		count int
+		want  string
+	}{
+		{count: 1, want: "agent"},
+		{count: 0, want: "agents"},
+		{count: 2, want: "agents"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("count_%d", tt.count), func(t *testing.T) {
+			t.Parallel()
+			if got := Pluralize("agent", tt.count); got != tt.want {
+				t.Fatalf("Pluralize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package job
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// ansiStyle is the text style selected by ANSI SGR escape sequences.
+type ansiStyle struct {
+	bold, faint, italic, underline bool
+	fg, bg                         string // a class ("c1", "bc4") or a colour ("#rrggbb"); empty for the default
+}
+
+func (s ansiStyle) isZero() bool {
+	return s == ansiStyle{}
+}
+
+// span returns the opening tag for text in this style.
+func (s ansiStyle) span() string {
+	var classes, styles []string
+	if s.bold {
+		classes = append(classes, "b")
+	}
+	if s.faint {
+		classes = append(classes, "f")
+	}
+	if s.italic {
+		classes = append(classes, "i")
+	}
+	if s.underline {
+		classes = append(classes, "u")
+	}
+	for _, c := range []struct{ value, prop string }{{s.fg, "color"}, {s.bg, "background-color"}} {
+		switch {
+		case c.value == "":
+		case strings.HasPrefix(c.value, "#"):
+			styles = append(styles, c.prop+":"+c.value)
+		default:
+			classes = append(classes, c.value)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("<span")
+	if len(classes) > 0 {
+		fmt.Fprintf(&b, ` class="%s"`, strings.Join(classes, " "))
+	}
+	if len(styles) > 0 {
+		fmt.Fprintf(&b, ` style="%s"`, strings.Join(styles, ";"))
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// ANSIToHTML converts text containing ANSI escape sequences to escaped HTML,
+// turning colour and text style sequences into styled spans and dropping
+// every other escape sequence. The 16 standard colours use the classes c0-c15
+// (and bc0-bc15 for backgrounds); 256-colour and true colour values are
+// written inline.
+func ANSIToHTML(text string) string {
+	var b strings.Builder
+	var style ansiStyle
+	open := false
+
+	setStyle := func(next ansiStyle) {
+		if next == style {
+			return
+		}
+		if open {
+			b.WriteString("</span>")
+			open = false
+		}
+		style = next
+		if !style.isZero() {
+			b.WriteString(style.span())
+			open = true
+		}
+	}
+
+	for i := 0; i < len(text); {
+		if text[i] != 0x1b {
+			j := strings.IndexByte(text[i:], 0x1b)
+			if j < 0 {
+				j = len(text) - i
+			}
+			b.WriteString(html.EscapeString(text[i : i+j]))
+			i += j
+			continue
+		}
+
+		seq, params, final := parseEscape(text[i:])
+		i += seq
+		if final == 'm' {
+			setStyle(applySGR(style, params))
+		}
+	}
+	if open {
+		b.WriteString("</span>")
+	}
+	return b.String()
+}
+
+// parseEscape parses the escape sequence at the start of s, returning its
+// length and, for a CSI sequence, its parameters and final byte. Other
+// sequences (OSC, APC and two-byte escapes) have a zero final byte.
+func parseEscape(s string) (n int, params string, final byte) {
+	if len(s) < 2 {
+		return len(s), "", 0
+	}
+	switch s[1] {
+	case '[':
+		for j := 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j + 1, s[2:j], s[j]
+			}
+		}
+		return len(s), "", 0
+	case ']', '_', 'P', '^':
+		// String sequences end with BEL or ESC \.
+		for j := 2; j < len(s); j++ {
+			if s[j] == 0x07 {
+				return j + 1, "", 0
+			}
+			if s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2, "", 0
+			}
+		}
+		return len(s), "", 0
+	default:
+		return 2, "", 0
+	}
+}
+
+// applySGR returns style updated by the parameters of an SGR sequence.
+func applySGR(style ansiStyle, params string) ansiStyle {
+	if params == "" {
+		return ansiStyle{}
+	}
+	codes := strings.Split(strings.ReplaceAll(params, ":", ";"), ";")
+	for k := 0; k < len(codes); k++ {
+		code, err := strconv.Atoi(codes[k])
+		if err != nil {
+			code = 0
+		}
+		switch {
+		case code == 0:
+			style = ansiStyle{}
+		case code == 1:
+			style.bold = true
+		case code == 2:
+			style.faint = true
+		case code == 3:
+			style.italic = true
+		case code == 4:
+			style.underline = true
+		case code == 22:
+			style.bold, style.faint = false, false
+		case code == 23:
+			style.italic = false
+		case code == 24:
+			style.underline = false
+		case code >= 30 && code <= 37:
+			style.fg = fmt.Sprintf("c%d", code-30)
+		case code >= 90 && code <= 97:
+			style.fg = fmt.Sprintf("c%d", code-90+8)
+		case code == 39:
+			style.fg = ""
+		case code >= 40 && code <= 47:
+			style.bg = fmt.Sprintf("bc%d", code-40)
+		case code >= 100 && code <= 107:
+			style.bg = fmt.Sprintf("bc%d", code-100+8)
+		case code == 49:
+			style.bg = ""
+		case code == 38 || code == 48:
+			if code == 38 {
+				colour, used := extendedColour(codes[k+1:], "c")
+				style.fg = colour
+				k += used
+			} else {
+				colour, used := extendedColour(codes[k+1:], "bc")
+				style.bg = colour
+				k += used
+			}
+		}
+	}
+	return style
+}
+
+// extendedColour parses the arguments of a 38 or 48 SGR code: 5;n for the
+// 256-colour palette or 2;r;g;b for true colour. It returns the colour and
+// how many arguments it used. The first 16 palette entries are returned as
+// the standard colour classes with the given prefix.
+func extendedColour(args []string, class string) (string, int) {
+	num := func(i int) int {
+		if i >= len(args) {
+			return 0
+		}
+		n, _ := strconv.Atoi(args[i])
+		return min(max(n, 0), 255)
+	}
+	if len(args) == 0 {
+		return "", 0
+	}
+	switch args[0] {
+	case "5":
+		n := num(1)
+		if n < 16 {
+			return fmt.Sprintf("%s%d", class, n), 2
+		}
+		return palette256(n), 2
+	case "2":
+		return fmt.Sprintf("#%02x%02x%02x", num(1), num(2), num(3)), 4
+	default:
+		return "", 1
+	}
+}
+
+// palette256 returns the colour of an entry above 15 in the xterm 256-colour
+// palette: a 6x6x6 colour cube followed by a greyscale ramp.
+func palette256(n int) string {
+	if n >= 232 {
+		v := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+	n -= 16
+	level := func(v int) int {
+		if v == 0 {
+			return 0
+		}
+		return 55 + v*40
+	}
+	return fmt.Sprintf("#%02x%02x%02x", level(n/36), level(n/6%6), level(n%6))
+}
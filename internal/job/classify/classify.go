@@ -0,0 +1,278 @@
+// Package classify labels the likely cause of a failed job from its log and
+// exit status, using a ruleset of regular expressions.
+package classify
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/charmbracelet/x/ansi"
+	git "github.com/go-git/go-git/v5"
+	"github.com/goccy/go-yaml"
+)
+
+// RepositoryRulesFile is where a repository's own rules are read from,
+// relative to its root.
+const RepositoryRulesFile = ".buildkite/classify.yaml"
+
+const (
+	// maxEvidencePerRule caps how many matching lines of a single rule count
+	// towards a score, so one noisy pattern can't drown out the others.
+	maxEvidencePerRule = 5
+
+	// maxEvidence caps the evidence lines kept for each result.
+	maxEvidence = 8
+
+	// jobMatchWeight multiplies a rule's weight when it matches the job's
+	// exit status or state, which is stronger evidence than a log line.
+	jobMatchWeight = 3
+)
+
+//go:embed rules.yaml
+var defaultRules []byte
+
+// Rule matches a job when any of its patterns matches a log line, or the job
+// finished with one of its exit statuses or states.
+type Rule struct {
+	Name         string   `yaml:"name"`
+	Category     string   `yaml:"category"`
+	Weight       int      `yaml:"weight"`
+	Patterns     []string `yaml:"patterns"`
+	ExitStatuses []int    `yaml:"exit_statuses"`
+	States       []string `yaml:"states"`
+
+	patterns []*regexp.Regexp
+}
+
+// Ruleset is a set of rules and the labels of their categories.
+type Ruleset struct {
+	Categories map[string]string `yaml:"categories"`
+	Rules      []Rule            `yaml:"rules"`
+}
+
+// Job is what a ruleset needs to know about a job besides its log.
+type Job struct {
+	State      string
+	ExitStatus *int
+}
+
+// Evidence is a reason a rule matched. Line is the 1-based log line number,
+// or 0 when the rule matched the job's exit status or state.
+type Evidence struct {
+	Rule string `json:"rule"`
+	Line int    `json:"line,omitempty"`
+	Text string `json:"text"`
+}
+
+// Result is a category that matched a job, with its score and the evidence
+// for it.
+type Result struct {
+	Category string     `json:"category"`
+	Label    string     `json:"label"`
+	Score    int        `json:"score"`
+	Evidence []Evidence `json:"evidence"`
+}
+
+// Default returns the built-in ruleset.
+func Default() *Ruleset {
+	rs, err := Parse(defaultRules)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in classify rules: %v", err))
+	}
+	return rs
+}
+
+// Parse parses and compiles a ruleset.
+func Parse(data []byte) (*Ruleset, error) {
+	var rs Ruleset
+	if err := yaml.Unmarshal(data, &rs); err != nil {
+		return nil, err
+	}
+	for i := range rs.Rules {
+		r := &rs.Rules[i]
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %d has no name", i+1)
+		}
+		if r.Category == "" {
+			return nil, fmt.Errorf("rule %q has no category", r.Name)
+		}
+		if len(r.Patterns) == 0 && len(r.ExitStatuses) == 0 && len(r.States) == 0 {
+			return nil, fmt.Errorf("rule %q has no patterns, exit statuses or states", r.Name)
+		}
+		if r.Weight <= 0 {
+			r.Weight = 1
+		}
+		for _, p := range r.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: invalid pattern: %w", r.Name, err)
+			}
+			r.patterns = append(r.patterns, re)
+		}
+	}
+	return &rs, nil
+}
+
+// Load reads a ruleset file.
+func Load(path string) (*Ruleset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rs, nil
+}
+
+// LoadForRepository returns the built-in rules, extended with the
+// repository's RepositoryRulesFile if it has one, then with each of paths.
+func LoadForRepository(repo *git.Repository, paths ...string) (*Ruleset, error) {
+	rs := Default()
+
+	if repo != nil {
+		if wt, _ := repo.Worktree(); wt != nil {
+			repoRules, err := Load(filepath.Join(wt.Filesystem.Root(), RepositoryRulesFile))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			if repoRules != nil {
+				rs = rs.Merge(repoRules)
+			}
+		}
+	}
+
+	for _, p := range paths {
+		extra, err := Load(p)
+		if err != nil {
+			return nil, err
+		}
+		rs = rs.Merge(extra)
+	}
+	return rs, nil
+}
+
+// Merge returns a ruleset with other's rules added to rs's. Rules and
+// category labels in other replace those in rs with the same name.
+func (rs *Ruleset) Merge(other *Ruleset) *Ruleset {
+	merged := &Ruleset{
+		Categories: make(map[string]string, len(rs.Categories)+len(other.Categories)),
+		Rules:      slices.Clone(rs.Rules),
+	}
+	for k, v := range rs.Categories {
+		merged.Categories[k] = v
+	}
+	for k, v := range other.Categories {
+		merged.Categories[k] = v
+	}
+	for _, r := range other.Rules {
+		i := slices.IndexFunc(merged.Rules, func(existing Rule) bool { return existing.Name == r.Name })
+		if i >= 0 {
+			merged.Rules[i] = r
+		} else {
+			merged.Rules = append(merged.Rules, r)
+		}
+	}
+	return merged
+}
+
+// Label returns the human readable label of a category.
+func (rs *Ruleset) Label(category string) string {
+	if label, ok := rs.Categories[category]; ok && label != "" {
+		return label
+	}
+	return category
+}
+
+// Classify matches the rules against a job and its log lines, and returns
+// the matching categories, most likely first. It returns nil when no rule
+// matches.
+func (rs *Ruleset) Classify(lines []string, job Job) []Result {
+	byCategory := make(map[string]*Result)
+	result := func(category string) *Result {
+		r, ok := byCategory[category]
+		if !ok {
+			r = &Result{Category: category, Label: rs.Label(category)}
+			byCategory[category] = r
+		}
+		return r
+	}
+
+	for _, rule := range rs.Rules {
+		if job.ExitStatus != nil && slices.Contains(rule.ExitStatuses, *job.ExitStatus) {
+			r := result(rule.Category)
+			r.Score += rule.Weight * jobMatchWeight
+			r.Evidence = append(r.Evidence, Evidence{Rule: rule.Name, Text: fmt.Sprintf("exit status %d", *job.ExitStatus)})
+		}
+		if job.State != "" && slices.Contains(rule.States, job.State) {
+			r := result(rule.Category)
+			r.Score += rule.Weight * jobMatchWeight
+			r.Evidence = append(r.Evidence, Evidence{Rule: rule.Name, Text: "job state " + job.State})
+		}
+	}
+
+	stripped := make([]string, len(lines))
+	for i, l := range lines {
+		stripped[i] = ansi.Strip(l)
+	}
+	for _, rule := range rs.Rules {
+		if len(rule.patterns) == 0 {
+			continue
+		}
+		matched := 0
+		for i, l := range stripped {
+			if !matchesAny(rule.patterns, l) {
+				continue
+			}
+			r := result(rule.Category)
+			r.Score += rule.Weight
+			r.Evidence = append(r.Evidence, Evidence{Rule: rule.Name, Line: i + 1, Text: l})
+			if matched++; matched == maxEvidencePerRule {
+				break
+			}
+		}
+	}
+
+	if len(byCategory) == 0 {
+		return nil
+	}
+
+	results := make([]Result, 0, len(byCategory))
+	for _, r := range byCategory {
+		// Job matches first, then log lines in the order they were logged.
+		sort.SliceStable(r.Evidence, func(i, j int) bool { return r.Evidence[i].Line < r.Evidence[j].Line })
+		if len(r.Evidence) > maxEvidence {
+			r.Evidence = r.Evidence[:maxEvidence]
+		}
+		results = append(results, *r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Category < results[j].Category
+	})
+	return results
+}
+
+// ClassifyJob classifies a Buildkite job from its log lines.
+func (rs *Ruleset) ClassifyJob(j buildkite.Job, lines []string) []Result {
+	return rs.Classify(lines, Job{State: j.State, ExitStatus: j.ExitStatus})
+}
+
+func matchesAny(patterns []*regexp.Regexp, line string) bool {
+	for _, p := range patterns {
+		if p.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package classify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultRulesetClassifies(t *testing.T) {
+	t.Parallel()
+
+	exit := func(n int) *int { return &n }
+
+	tests := []struct {
+		name  string
+		log   string
+		job   Job
+		want  string
+		proof string
+	}{
+		{
+			name:  "oom",
+			log:   "running\nKilled process 1234 (node) total-vm:1234kB\n",
+			job:   Job{State: "failed", ExitStatus: exit(137)},
+			want:  "oom",
+			proof: "exit status 137",
+		},
+		{
+			name:  "agent lost",
+			log:   "step output\n",
+			job:   Job{State: "failed", ExitStatus: exit(-1)},
+			want:  "agent_lost",
+			proof: "exit status -1",
+		},
+		{
+			name:  "timeout",
+			log:   "still going\n",
+			job:   Job{State: "timed_out"},
+			want:  "timeout",
+			proof: "job state timed_out",
+		},
+		{
+			name:  "dependency",
+			log:   "npm ERR! code ETIMEDOUT\nnpm ERR! network request failed\n",
+			job:   Job{State: "failed", ExitStatus: exit(1)},
+			want:  "dependency",
+			proof: "npm ERR! code ETIMEDOUT",
+		},
+		{
+			name:  "compile",
+			log:   "# github.com/acme/app\n\x1b[31mmain.go:12:2: undefined: foo\x1b[0m\n",
+			job:   Job{State: "failed", ExitStatus: exit(1)},
+			want:  "compile",
+			proof: "main.go:12:2: undefined: foo",
+		},
+		{
+			name:  "test",
+			log:   "=== RUN   TestThing\n    thing_test.go:12: expected 1, got 2\n--- FAIL: TestThing (0.00s)\nFAIL\tgithub.com/acme/app\t0.1s\n",
+			job:   Job{State: "failed", ExitStatus: exit(1)},
+			want:  "test",
+			proof: "--- FAIL: TestThing (0.00s)",
+		},
+		{
+			name:  "lint",
+			log:   "Inspecting 40 files\n3 offenses detected\n",
+			job:   Job{State: "failed", ExitStatus: exit(1)},
+			want:  "lint",
+			proof: "3 offenses detected",
+		},
+	}
+
+	rs := Default()
+	for _, tt := range tests {
+		results := rs.Classify(strings.Split(tt.log, "\n"), tt.job)
+		if len(results) == 0 {
+			t.Errorf("%s: expected a classification, got none", tt.name)
+			continue
+		}
+		if results[0].Category != tt.want {
+			t.Errorf("%s: expected %q first, got %+v", tt.name, tt.want, results)
+			continue
+		}
+		found := false
+		for _, e := range results[0].Evidence {
+			if e.Text == tt.proof {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected evidence %q, got %+v", tt.name, tt.proof, results[0].Evidence)
+		}
+	}
+}
+
+func TestClassifyNoMatch(t *testing.T) {
+	t.Parallel()
+
+	if got := Default().Classify([]string{"all good"}, Job{State: "failed"}); got != nil {
+		t.Errorf("expected no classification, got %+v", got)
+	}
+}
+
+func TestClassifyEvidenceLineNumbers(t *testing.T) {
+	t.Parallel()
+
+	results := Default().Classify([]string{"setup", "--- FAIL: TestA (0.00s)"}, Job{})
+	if len(results) == 0 || len(results[0].Evidence) != 1 {
+		t.Fatalf("expected one piece of evidence, got %+v", results)
+	}
+	if e := results[0].Evidence[0]; e.Line != 2 || e.Rule != "test-go" {
+		t.Errorf("unexpected evidence %+v", e)
+	}
+}
+
+func TestLoadForRepositoryMergesRules(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	rules := `categories:
+  flaky_db: flaky database
+  test: failing test
+rules:
+  - name: db-deadlock
+    category: flaky_db
+    weight: 5
+    patterns: ['(?i)deadlock detected']
+  - name: test-go
+    category: test
+    patterns: ['^NEVER$']
+`
+	if err := os.WriteFile(path, []byte(rules), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := LoadForRepository(nil, path)
+	if err != nil {
+		t.Fatalf("LoadForRepository() error = %v", err)
+	}
+
+	results := rs.Classify([]string{"--- FAIL: TestA", "ERROR: deadlock detected"}, Job{})
+	if len(results) != 1 || results[0].Category != "flaky_db" || results[0].Label != "flaky database" {
+		t.Errorf("expected only the custom rule to match after test-go was replaced, got %+v", results)
+	}
+	if rs.Label("test") != "failing test" || rs.Label("lint") != "lint failure" {
+		t.Errorf("expected category labels to be merged, got %q and %q", rs.Label("test"), rs.Label("lint"))
+	}
+}
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"no name":     "rules:\n  - category: x\n    patterns: [a]\n",
+		"no category": "rules:\n  - name: x\n    patterns: [a]\n",
+		"no matchers": "rules:\n  - name: x\n    category: x\n",
+		"bad pattern": "rules:\n  - name: x\n    category: x\n    patterns: ['(']\n",
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
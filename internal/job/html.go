@@ -0,0 +1,116 @@
+package job
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+//go:embed log.html.tmpl
+var logHTMLTemplate string
+
+var logHTML = template.Must(template.New("log").Parse(logHTMLTemplate))
+
+// HTMLDocument is a standalone HTML page of one or more job logs. The page
+// has no external resources, so it can be archived or viewed offline.
+type HTMLDocument struct {
+	Title    string
+	Subtitle string
+	Logs     []HTMLLog
+
+	// Timestamps shows the time each line was logged.
+	Timestamps bool
+}
+
+// HTMLLog is one job's log in an HTMLDocument.
+type HTMLLog struct {
+	Title string
+	Meta  string
+
+	// Open shows the log expanded when the page loads.
+	Open     bool
+	Sections []LogSection
+}
+
+type htmlLogData struct {
+	Title, Meta string
+	Open        bool
+	Sections    []htmlSectionData
+}
+
+type htmlSectionData struct {
+	// Header is empty for the preamble, which is not collapsible.
+	Header, Meta string
+	Open, Failed bool
+	Lines        []htmlLineData
+}
+
+type htmlLineData struct {
+	ID             string
+	Number         int
+	Time, FullTime string
+	HTML           template.HTML
+}
+
+// WriteLogHTML writes doc as an HTML page. Sections are collapsible and
+// start expanded or collapsed as Buildkite shows them, ANSI colours are kept,
+// and every line links to itself.
+func WriteLogHTML(w io.Writer, doc HTMLDocument) error {
+	data := struct {
+		Title, Subtitle string
+		Logs            []htmlLogData
+	}{Title: doc.Title, Subtitle: doc.Subtitle}
+
+	for i, l := range doc.Logs {
+		ld := htmlLogData{Title: l.Title, Meta: l.Meta, Open: l.Open}
+		n := 1
+		for _, s := range l.Sections {
+			sd := htmlSectionData{Open: s.Expanded, Failed: s.Failed}
+			if s.Marker != "" {
+				sd.Header = ansi.Strip(s.Title())
+				sd.Meta = sectionMeta(s)
+				n = s.Line + 1
+			}
+			for _, line := range s.Lines {
+				hl := htmlLineData{
+					ID:     fmt.Sprintf("j%d-L%d", i+1, n),
+					Number: n,
+					HTML:   template.HTML(ANSIToHTML(line.Text)),
+				}
+				if doc.Timestamps && !line.Time.IsZero() {
+					hl.Time = line.Time.UTC().Format(time.TimeOnly)
+					hl.FullTime = line.Time.UTC().Format("2006-01-02 15:04:05.000 MST")
+				}
+				sd.Lines = append(sd.Lines, hl)
+				n++
+			}
+			ld.Sections = append(ld.Sections, sd)
+		}
+		data.Logs = append(data.Logs, ld)
+	}
+
+	return logHTML.Execute(w, data)
+}
+
+// sectionMeta describes when a section started and how long it took.
+func sectionMeta(s LogSection) string {
+	if s.Start.IsZero() {
+		return ""
+	}
+	meta := s.Start.UTC().Format(time.TimeOnly)
+	if s.Duration > 0 {
+		meta += " · " + formatHTMLDuration(s.Duration)
+	}
+	return meta
+}
+
+func formatHTMLDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
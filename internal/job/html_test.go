@@ -0,0 +1,90 @@
+package job
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestANSIToHTML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain text is escaped", `<a href="x">&</a>`, "&lt;a href=&#34;x&#34;&gt;&amp;&lt;/a&gt;"},
+		{"foreground colour", "\x1b[31mred\x1b[0m done", `<span class="c1">red</span> done`},
+		{"bright colour and bold", "\x1b[1;92mok\x1b[m", `<span class="b c10">ok</span>`},
+		{"background colour", "\x1b[41;37mx\x1b[49mx", `<span class="c7 bc1">x</span><span class="c7">x</span>`},
+		{"256 colours", "\x1b[38;5;196mx\x1b[48;5;4my", `<span style="color:#ff0000">x</span><span class="bc4" style="color:#ff0000">y</span>`},
+		{"low 256 colours use classes", "\x1b[38;5;9mx\x1b[0m", `<span class="c9">x</span>`},
+		{"true colour", "\x1b[38;2;1;2;3mx", `<span style="color:#010203">x</span>`},
+		{"unclosed style is closed", "\x1b[4mx", `<span class="u">x</span>`},
+		{"other sequences are dropped", "\x1b[2K\x1b]0;title\x07\x1b_bk;t=1\x07text", "text"},
+		{"redundant sequences don't nest", "\x1b[31m\x1b[31mx\x1b[0m\x1b[0m", `<span class="c1">x</span>`},
+	}
+	for _, tt := range tests {
+		if got := ANSIToHTML(tt.in); got != tt.want {
+			t.Errorf("%s: ANSIToHTML(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteLogHTML(t *testing.T) {
+	t.Parallel()
+
+	content := "\x1b_bk;t=1700000000000\x07preamble <b>\n" +
+		"\x1b_bk;t=1700000001000\x07--- :go: Build\n" +
+		"\x1b_bk;t=1700000002000\x07compiling\n" +
+		"\x1b_bk;t=1700000003000\x07+++ Test\n" +
+		"\x1b_bk;t=1700000004000\x07\x1b[31mFAIL\x1b[0m\n"
+
+	var out strings.Builder
+	err := WriteLogHTML(&out, HTMLDocument{
+		Title:      "Test <job>",
+		Timestamps: true,
+		Logs:       []HTMLLog{{Title: "Test", Meta: "failed", Open: true, Sections: ParseLogSections(content)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := out.String()
+
+	for _, want := range []string{
+		"<title>Test &lt;job&gt;</title>",
+		`<details class="job" open>`,
+		`<a class="n" href="#j1-L1">1</a>`,
+		"preamble &lt;b&gt;",
+		"<details>\n<summary>:go: Build<span class=\"meta\">22:13:21 · 2s</span></summary>",
+		`<div class="line" id="j1-L3">`,
+		`<span class="t" title="2023-11-14 22:13:22.000 UTC">22:13:22</span>`,
+		"<details open>\n<summary>Test",
+		`<span class="c1">FAIL</span>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected HTML to contain %q, got:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "bk;t=") || strings.Contains(html, "\x1b") {
+		t.Error("expected escape sequences to be removed")
+	}
+	for _, external := range []string{"<link", "src="} {
+		if strings.Contains(html, external) {
+			t.Errorf("expected a self-contained page, found %q", external)
+		}
+	}
+}
+
+func TestWriteLogHTMLWithoutTimestamps(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	err := WriteLogHTML(&out, HTMLDocument{Logs: []HTMLLog{{Sections: ParseLogSections("\x1b_bk;t=1700000000000\x07hello\n")}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), `class="t"`) {
+		t.Errorf("expected no timestamps, got:\n%s", out.String())
+	}
+}
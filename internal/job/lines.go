@@ -0,0 +1,63 @@
+package job
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampValueRegex captures the milliseconds since the epoch from a
+// Buildkite timestamp marker.
+var timestampValueRegex = regexp.MustCompile(`bk;t=(\d+)\x07`)
+
+// LogLine is a single line of a job log together with the time Buildkite
+// recorded for it.
+type LogLine struct {
+	Time time.Time
+	Text string
+}
+
+// SplitLogLines splits job log content into lines, taking each line's time
+// from its bk;t= timestamp marker and removing the markers from the text.
+// Lines without a marker inherit the time of the line before them. Output
+// rewritten with carriage returns (e.g. progress bars) is collapsed to what a
+// terminal would finally show.
+func SplitLogLines(content string) []LogLine {
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return nil
+	}
+
+	raw := strings.Split(content, "\n")
+	lines := make([]LogLine, 0, len(raw))
+	var last time.Time
+	for _, l := range raw {
+		if m := timestampValueRegex.FindStringSubmatch(l); m != nil {
+			if ms, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+				last = time.UnixMilli(ms)
+			}
+		}
+		lines = append(lines, LogLine{Time: last, Text: lineText(l)})
+	}
+	return lines
+}
+
+// lineText returns what a terminal shows for a raw log line: its text
+// without timestamp markers, and only what follows the last carriage return.
+func lineText(raw string) string {
+	text := strings.TrimRight(StripTimestamps(raw), "\r")
+	if i := strings.LastIndexByte(text, '\r'); i >= 0 {
+		text = text[i+1:]
+	}
+	return text
+}
+
+// LogLineTexts returns the text of each line.
+func LogLineTexts(lines []LogLine) []string {
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.Text
+	}
+	return texts
+}
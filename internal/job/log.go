@@ -0,0 +1,77 @@
+package job
+
+import (
+	"regexp"
+	"strings"
+)
+
+// timestampRegex matches Buildkite's inline timestamp markers, including the
+// optional APC introducer (`\x1b_`) so the whole sequence is removed rather than
+// leaving a dangling escape byte behind.
+var timestampRegex = regexp.MustCompile(`(?:\x1b_)?bk;t=\d+\x07`)
+
+// StripTimestamps removes Buildkite's inline timestamp markers from log content.
+func StripTimestamps(content string) string {
+	return timestampRegex.ReplaceAllString(content, "")
+}
+
+// isSectionHeader reports whether a log line opens a collapsed (---) or
+// expanded (+++) section. Quiet (~~~) headers are left out, as the agent
+// writes them for its own hooks after the command has run.
+func isSectionHeader(line string) bool {
+	marker, _, ok := sectionHeader(lineText(line))
+	return ok && marker != SectionQuiet
+}
+
+// LastSectionOffset returns the byte offset of the start of the last section
+// header line in content, or 0 when content has no section headers.
+func LastSectionOffset(content string) int {
+	last := 0
+	for start := 0; start < len(content); {
+		end := strings.IndexByte(content[start:], '\n')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += start
+		}
+		if isSectionHeader(content[start:end]) {
+			last = start
+		}
+		start = end + 1
+	}
+	return last
+}
+
+// LogTail tracks how much of a growing job log has already been output, so
+// that polling the full log repeatedly yields only the new content.
+type LogTail struct {
+	offset int
+}
+
+// NewLogTail returns a LogTail that starts output at offset bytes into the
+// log.
+func NewLogTail(offset int) *LogTail {
+	return &LogTail{offset: offset}
+}
+
+// Next returns the complete lines appended to content since the previous
+// call. A trailing partial line is held back until it is completed, so that
+// markers split across polls are never output in halves, unless final is set
+// because the log will not grow any further.
+func (t *LogTail) Next(content string, final bool) string {
+	if len(content) <= t.offset {
+		return ""
+	}
+
+	end := len(content)
+	if !final {
+		end = strings.LastIndexByte(content, '\n') + 1
+		if end <= t.offset {
+			return ""
+		}
+	}
+
+	chunk := content[t.offset:end]
+	t.offset = end
+	return chunk
+}
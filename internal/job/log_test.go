@@ -0,0 +1,115 @@
+package job
+
+import "testing"
+
+func TestStripTimestamps(t *testing.T) {
+	t.Parallel()
+
+	in := "\x1b_bk;t=1700000000000\x07hello"
+	if got := StripTimestamps(in); got != "hello" {
+		t.Errorf("StripTimestamps(%q) = %q, want %q", in, got, "hello")
+	}
+}
+
+func TestLastSectionOffset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "no sections",
+			content: "hello\nworld\n",
+			want:    "hello\nworld\n",
+		},
+		{
+			name:    "last of several sections",
+			content: "--- Setup\nsetup\n+++ Running tests\nok\n",
+			want:    "+++ Running tests\nok\n",
+		},
+		{
+			name:    "timestamped headers and CRLF",
+			content: "\x1b_bk;t=1\x07--- Setup\r\nsetup\r\n\x1b_bk;t=2\x07--- Build\r\nbuild\r\n",
+			want:    "\x1b_bk;t=2\x07--- Build\r\nbuild\r\n",
+		},
+		{
+			name:    "quiet sections written after the command are skipped",
+			content: "--- Setup\nsetup\n+++ Tests\nok\n~~~ Running post-command hook\ndone\n",
+			want:    "+++ Tests\nok\n~~~ Running post-command hook\ndone\n",
+		},
+		{
+			name:    "header redrawn after a carriage return",
+			content: "--- Setup\nsetup\nprogress\r+++ Tests\nok\n",
+			want:    "progress\r+++ Tests\nok\n",
+		},
+		{
+			name:    "dashes that are not headers",
+			content: "--- Setup\n----------\n---no space\n",
+			want:    "--- Setup\n----------\n---no space\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.content[LastSectionOffset(tt.content):]; got != tt.want {
+				t.Errorf("content from LastSectionOffset = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogTail(t *testing.T) {
+	t.Parallel()
+
+	tail := NewLogTail(0)
+	if got := tail.Next("one\ntw", false); got != "one\n" {
+		t.Errorf("Next() = %q, want complete lines only", got)
+	}
+	if got := tail.Next("one\ntw", false); got != "" {
+		t.Errorf("Next() = %q, want nothing new", got)
+	}
+	if got := tail.Next("one\ntwo\nthr", false); got != "two\n" {
+		t.Errorf("Next() = %q, want the completed line", got)
+	}
+	if got := tail.Next("one\ntwo\nthree", true); got != "three" {
+		t.Errorf("Next() = %q, want trailing partial line once final", got)
+	}
+
+	if got := NewLogTail(4).Next("one\ntwo\n", false); got != "two\n" {
+		t.Errorf("Next() = %q, want output from the starting offset", got)
+	}
+}
+
+func TestSplitLogLines(t *testing.T) {
+	t.Parallel()
+
+	content := "\x1b_bk;t=1700000000000\x07first\r\n" +
+		"continued\n" +
+		"\x1b_bk;t=1700000001500\x07progress 10%\rprogress 100%\n"
+
+	lines := SplitLogLines(content)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %+v", len(lines), lines)
+	}
+
+	want := []struct {
+		ms   int64
+		text string
+	}{
+		{1700000000000, "first"},
+		{1700000000000, "continued"},
+		{1700000001500, "progress 100%"},
+	}
+	for i, w := range want {
+		if lines[i].Text != w.text || lines[i].Time.UnixMilli() != w.ms {
+			t.Errorf("line %d = {%v %q}, want {%d %q}", i, lines[i].Time.UnixMilli(), lines[i].Text, w.ms, w.text)
+		}
+	}
+
+	if got := SplitLogLines(""); got != nil {
+		t.Errorf("SplitLogLines(\"\") = %+v, want nil", got)
+	}
+}
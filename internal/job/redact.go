@@ -0,0 +1,198 @@
+package job
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Redacted replaces each secret found by a Redactor.
+const Redacted = "[REDACTED]"
+
+// minSecretEntropy is the Shannon entropy, in bits per character, above which
+// a value assigned to a password-like name may be a secret. Real credentials
+// are well above it; placeholders like "********" are below it.
+const minSecretEntropy = 3.0
+
+// tokenStart matches what may come before a token: the start of the line, a
+// character that can't be part of one, or an ANSI escape sequence, as when a
+// token is printed in colour. It stands in for a leading \b, which never
+// matches after the "m" ending a colour code, and is kept when the token is
+// replaced.
+const tokenStart = `(^|[^A-Za-z0-9_]|\x1b\[[0-?]*[ -/]*[@-~])`
+
+// secretPatterns match common credential shapes. Everything after the first
+// submatch is replaced.
+var secretPatterns = []*regexp.Regexp{
+	// AWS access key IDs.
+	regexp.MustCompile(tokenStart + `(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[A-Z0-9]{16}\b`),
+	// GitHub tokens.
+	regexp.MustCompile(tokenStart + `gh[pousr]_[A-Za-z0-9]{36,}\b`),
+	regexp.MustCompile(tokenStart + `github_pat_[A-Za-z0-9_]{22,}\b`),
+	// Buildkite API, agent and cluster tokens.
+	regexp.MustCompile(tokenStart + `bk(?:ua|at|ct|it)_[A-Za-z0-9]{20,}\b`),
+	// JSON Web Tokens.
+	regexp.MustCompile(tokenStart + `eyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`),
+	// Private keys written on a single line, e.g. inside a JSON string.
+	regexp.MustCompile(`()-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----.*?-----END [A-Z0-9 ]*PRIVATE KEY-----`),
+}
+
+// assignmentPattern matches a value assigned to a name that suggests a
+// secret, e.g. `password=...` or `"api_key": "..."`. The value is only
+// replaced when it looks random enough to be a real credential.
+var assignmentPattern = regexp.MustCompile(`(?i)((?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credentials?|auth)[A-Za-z0-9_-]*["']?\s*[:=]\s*["']?)([^\s"',;]{8,})`)
+
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END [A-Z0-9 ]*PRIVATE KEY-----`)
+
+	// leadingTimestamps matches the timestamp markers at the start of a raw
+	// log line, which are kept when the rest of the line is redacted.
+	leadingTimestamps = regexp.MustCompile(`^(?:(?:\x1b_)?bk;t=\d+\x07)*`)
+)
+
+// Redactor masks credentials in log content: AWS keys, GitHub and Buildkite
+// tokens, JWTs, private key blocks, and random-looking values assigned to
+// names like "password", plus any extra patterns it was created with.
+type Redactor struct {
+	// extra patterns have their whole match replaced.
+	extra []*regexp.Regexp
+}
+
+// NewRedactor returns a Redactor that also masks matches of each of extra.
+func NewRedactor(extra []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, p := range extra {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		r.extra = append(r.extra, re)
+	}
+	return r, nil
+}
+
+// Redact returns content with its secrets masked.
+func (r *Redactor) Redact(content string) string {
+	return r.Stream().Redact(content)
+}
+
+// RedactVar returns the value of a variable such as an environment variable
+// with its secrets masked. The name is taken into account, so a random-looking
+// value of DB_PASSWORD is masked as it would be in "DB_PASSWORD=value".
+func (r *Redactor) RedactVar(name, value string) string {
+	prefix := name + "="
+	redacted, ok := strings.CutPrefix(r.Redact(prefix+value), prefix)
+	if !ok {
+		return Redacted
+	}
+	return redacted
+}
+
+// RedactLines returns a copy of lines with their secrets masked.
+func (r *Redactor) RedactLines(lines []LogLine) []LogLine {
+	s := r.Stream()
+	redacted := make([]LogLine, len(lines))
+	for i, l := range lines {
+		redacted[i] = LogLine{Time: l.Time, Text: s.redactLine(l.Text)}
+	}
+	return redacted
+}
+
+// Stream returns a RedactorStream for redacting a log that arrives in
+// chunks.
+func (r *Redactor) Stream() *RedactorStream {
+	return &RedactorStream{redactor: r}
+}
+
+// RedactorStream redacts successive chunks of one log, remembering when a
+// chunk ends inside a private key block so that the rest of the block is
+// masked too. Chunks should end at line boundaries.
+type RedactorStream struct {
+	redactor     *Redactor
+	inPrivateKey bool
+}
+
+// Redact returns chunk with its secrets masked.
+func (s *RedactorStream) Redact(chunk string) string {
+	lines := strings.SplitAfter(chunk, "\n")
+	for i, l := range lines {
+		text, nl := strings.CutSuffix(l, "\n")
+		lines[i] = s.redactLine(text)
+		if nl {
+			lines[i] += "\n"
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+func (s *RedactorStream) redactLine(line string) string {
+	if s.inPrivateKey {
+		if loc := privateKeyEnd.FindStringIndex(line); loc != nil {
+			s.inPrivateKey = false
+			return leadingTimestamps.FindString(line) + Redacted + s.redactLine(line[loc[0]:])
+		}
+		return leadingTimestamps.FindString(line) + Redacted
+	}
+
+	for _, p := range secretPatterns {
+		line = p.ReplaceAllString(line, "${1}"+Redacted)
+	}
+	for _, p := range s.redactor.extra {
+		line = p.ReplaceAllString(line, Redacted)
+	}
+
+	line = assignmentPattern.ReplaceAllStringFunc(line, func(m string) string {
+		parts := assignmentPattern.FindStringSubmatch(m)
+		if !looksRandom(parts[2]) {
+			return m
+		}
+		return parts[1] + Redacted
+	})
+
+	// A private key block that starts on this line and ends on a later one.
+	if loc := privateKeyBegin.FindStringIndex(line); loc != nil && !privateKeyEnd.MatchString(line[loc[1]:]) {
+		s.inPrivateKey = true
+		return line[:loc[1]]
+	}
+	return line
+}
+
+// looksRandom reports whether a value is random enough to be a credential:
+// high entropy, and mixing at least two of lower case, upper case and digits
+// so that ordinary words like "required-value" are left alone.
+func looksRandom(v string) bool {
+	if shannonEntropy(v) < minSecretEntropy {
+		return false
+	}
+	var lower, upper, digit int
+	for _, r := range v {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		}
+	}
+	return lower+upper+digit >= 2
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
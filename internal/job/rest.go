@@ -0,0 +1,72 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	buildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+type unblockJobOptions struct {
+	Fields map[string]any `json:"fields,omitempty"`
+}
+
+// OrganizationJobPath returns the organization-scoped REST path for a job
+// action, which only needs the job UUID rather than its pipeline and build.
+// An empty action returns the path of the job itself.
+func OrganizationJobPath(organization, jobID, action string) string {
+	path := fmt.Sprintf(
+		"v2/organizations/%s/jobs/%s",
+		url.PathEscape(organization),
+		url.PathEscape(jobID),
+	)
+	if action == "" {
+		return path
+	}
+	return path + "/" + action
+}
+
+// Retry retries a job and returns the newly created job.
+func Retry(ctx context.Context, client *buildkite.Client, organization, jobID string) (buildkite.Job, error) {
+	req, err := client.NewRequest(ctx, "PUT", OrganizationJobPath(organization, jobID, "retry"), nil)
+	if err != nil {
+		return buildkite.Job{}, err
+	}
+
+	var job buildkite.Job
+	if _, err := client.Do(req, &job); err != nil {
+		return buildkite.Job{}, err
+	}
+
+	return job, nil
+}
+
+// Unblock unblocks a block step job, optionally supplying values for its fields.
+func Unblock(ctx context.Context, client *buildkite.Client, organization, jobID string, fields map[string]any) (buildkite.Job, error) {
+	req, err := client.NewRequest(ctx, "PUT", OrganizationJobPath(organization, jobID, "unblock"), &unblockJobOptions{
+		Fields: fields,
+	})
+	if err != nil {
+		return buildkite.Job{}, err
+	}
+
+	var job buildkite.Job
+	if _, err := client.Do(req, &job); err != nil {
+		return buildkite.Job{}, err
+	}
+
+	return job, nil
+}
+
+// IsAlreadyUnblocked reports whether err is the API's response to unblocking
+// a job that is no longer blocked.
+func IsAlreadyUnblocked(err error) bool {
+	var apiErr *buildkite.ErrorResponse
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	return apiErr.Message == "The job's state must be blocked" || apiErr.Message == "The job's state must be blocked."
+}
@@ -0,0 +1,126 @@
+package job
+
+import (
+	"strings"
+	"time"
+)
+
+// Section header markers. Buildkite renders --- sections collapsed, +++
+// sections expanded and ~~~ sections collapsed without their own timing.
+const (
+	SectionCollapsed = "---"
+	SectionExpanded  = "+++"
+	SectionQuiet     = "~~~"
+)
+
+// sectionReopen is the directive that expands the previous section. The
+// agent emits it after a failed command so the failure is visible.
+const sectionReopen = "^^^ +++"
+
+// LogSection is a section of a job log, opened by a header line.
+type LogSection struct {
+	// Name is the header text after the marker. The preamble before the
+	// first header has an empty name and marker.
+	Name   string
+	Marker string
+
+	// Expanded reports whether Buildkite shows the section expanded: a +++
+	// header, or a section reopened with ^^^ +++.
+	Expanded bool
+
+	// Failed is set when the section was reopened with ^^^ +++, which the
+	// agent does when a command fails.
+	Failed bool
+
+	// Line is the 1-based line number of the header in the log (0 for the
+	// preamble), and Lines the section's body.
+	Line  int
+	Lines []LogLine
+
+	// Start is when the header was logged, and Duration how long until the
+	// next section started (or the last line of the log was logged).
+	Start    time.Time
+	Duration time.Duration
+}
+
+// Title returns the section's name, or a placeholder for the preamble.
+func (s LogSection) Title() string {
+	if s.Marker == "" {
+		return "(preamble)"
+	}
+	if s.Name == "" {
+		return "(unnamed section)"
+	}
+	return s.Name
+}
+
+// sectionHeader splits a header line into its marker and name.
+func sectionHeader(text string) (marker, name string, ok bool) {
+	for _, m := range []string{SectionCollapsed, SectionExpanded, SectionQuiet} {
+		if text == m {
+			return m, "", true
+		}
+		if rest, found := strings.CutPrefix(text, m+" "); found {
+			return m, strings.TrimSpace(rest), true
+		}
+	}
+	return "", "", false
+}
+
+// ParseLogSections splits log content into its sections. Lines before the
+// first header form a preamble section, which is omitted when empty.
+func ParseLogSections(content string) []LogSection {
+	return SectionLogLines(SplitLogLines(content))
+}
+
+// SectionLogLines is ParseLogSections for a log that has already been split
+// into lines.
+func SectionLogLines(lines []LogLine) []LogSection {
+	var sections []LogSection
+	current := LogSection{}
+	for i, l := range lines {
+		if strings.TrimSpace(l.Text) == sectionReopen {
+			if len(current.Lines) > 0 || current.Marker != "" {
+				current.Expanded, current.Failed = true, true
+			}
+			continue
+		}
+
+		marker, name, ok := sectionHeader(l.Text)
+		if !ok {
+			current.Lines = append(current.Lines, l)
+			continue
+		}
+
+		if current.Marker != "" || len(current.Lines) > 0 {
+			sections = append(sections, current)
+		}
+		current = LogSection{
+			Name:     name,
+			Marker:   marker,
+			Expanded: marker == SectionExpanded,
+			Line:     i + 1,
+			Start:    l.Time,
+		}
+	}
+	if current.Marker != "" || len(current.Lines) > 0 {
+		sections = append(sections, current)
+	}
+
+	for i := range sections {
+		s := &sections[i]
+		if s.Start.IsZero() && len(s.Lines) > 0 {
+			s.Start = s.Lines[0].Time
+		}
+		end := time.Time{}
+		if i+1 < len(sections) {
+			end = sections[i+1].Start
+		} else if len(lines) > 0 {
+			end = lines[len(lines)-1].Time
+		}
+		if !s.Start.IsZero() && end.After(s.Start) {
+			s.Duration = end.Sub(s.Start)
+		}
+	}
+	return sections
+}
@@ -0,0 +1,58 @@
+package job
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLogSections(t *testing.T) {
+	t.Parallel()
+
+	content := "preamble\n" +
+		"\x1b_bk;t=1700000000000\x07--- :git: Checkout\n" +
+		"\x1b_bk;t=1700000002000\x07cloning\n" +
+		"\x1b_bk;t=1700000005000\x07+++ Running tests\n" +
+		"\x1b_bk;t=1700000006000\x07FAIL\n" +
+		"\x1b_bk;t=1700000007000\x07^^^ +++\n" +
+		"\x1b_bk;t=1700000008000\x07~~~ Cleanup\n" +
+		"\x1b_bk;t=1700000010000\x07done\n"
+
+	sections := ParseLogSections(content)
+	if len(sections) != 4 {
+		t.Fatalf("expected 4 sections, got %d: %+v", len(sections), sections)
+	}
+
+	want := []struct {
+		title    string
+		marker   string
+		expanded bool
+		failed   bool
+		line     int
+		lines    int
+		duration time.Duration
+	}{
+		{"(preamble)", "", false, false, 0, 1, 0},
+		{":git: Checkout", SectionCollapsed, false, false, 2, 1, 5 * time.Second},
+		{"Running tests", SectionExpanded, true, true, 4, 1, 3 * time.Second},
+		{"Cleanup", SectionQuiet, false, false, 7, 1, 2 * time.Second},
+	}
+	for i, w := range want {
+		s := sections[i]
+		if s.Title() != w.title || s.Marker != w.marker || s.Expanded != w.expanded || s.Failed != w.failed || s.Line != w.line || len(s.Lines) != w.lines || s.Duration != w.duration {
+			t.Errorf("section %d = {%q %q expanded=%v failed=%v line=%d lines=%d %v}, want %+v",
+				i, s.Title(), s.Marker, s.Expanded, s.Failed, s.Line, len(s.Lines), s.Duration, w)
+		}
+	}
+}
+
+func TestParseLogSections_NoHeaders(t *testing.T) {
+	t.Parallel()
+
+	sections := ParseLogSections("just\nlines\n")
+	if len(sections) != 1 || sections[0].Marker != "" || len(sections[0].Lines) != 2 {
+		t.Errorf("expected a single preamble section, got %+v", sections)
+	}
+	if got := ParseLogSections(""); len(got) != 0 {
+		t.Errorf("expected no sections for an empty log, got %+v", got)
+	}
+}
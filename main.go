@@ -16,6 +16,7 @@ import (
 	"github.com/buildkite/cli/v3/cmd/browse"
 	"github.com/buildkite/cli/v3/cmd/build"
 	"github.com/buildkite/cli/v3/cmd/cluster"
+	"github.com/buildkite/cli/v3/cmd/commit"
 	bkConfig "github.com/buildkite/cli/v3/cmd/config"
 	"github.com/buildkite/cli/v3/cmd/configure"
 	bkInit "github.com/buildkite/cli/v3/cmd/init"
@@ -56,6 +57,7 @@ type CLI struct {
 	Browse       BrowseCmd           `cmd:"" help:"Open Buildkite resources in a web browser"`
 	Build        BuildCmd            `cmd:"" help:"Manage pipeline builds"`
 	Cluster      ClusterCmd          `cmd:"" help:"Manage organization clusters"`
+	Commit       CommitCmd           `cmd:"" help:"Inspect builds for a commit"`
 	Maintainer   MaintainerCmd       `cmd:"" help:"Manage cluster maintainers"`
 	Queue        QueueCmd            `cmd:"" help:"Manage cluster queues"`
 	Secret       SecretCmd           `cmd:"" help:"Manage cluster secrets"`
@@ -103,17 +105,29 @@ type (
 		browse.BrowseCmd `cmd:"" help:"Open Buildkite resources in a web browser"`
 	}
 	ArtifactsCmd struct {
+		Cat      artifacts.CatCmd      `cmd:"" help:"Print the content of artifacts to standard output."`
+		Diff     artifacts.DiffCmd     `cmd:"" help:"Compare the artifacts of two builds."`
 		Download artifacts.DownloadCmd `cmd:"" help:"Download artifacts from a build."`
 		List     artifacts.ListCmd     `cmd:"" help:"List artifacts for a build or a job in a build." aliases:"ls"`
 	}
 	BuildCmd struct {
-		Create   build.CreateCmd   `cmd:"" aliases:"new" help:"Create a new build."` // Aliasing "new" because we've renamed this to "create", but we need to support backwards compatibility
-		Cancel   build.CancelCmd   `cmd:"" help:"Cancel a build."`
-		View     build.ViewCmd     `cmd:"" help:"View build information."`
-		List     build.ListCmd     `cmd:"" help:"List builds." aliases:"ls"`
-		Download build.DownloadCmd `cmd:"" help:"Download resources for a build."`
-		Rebuild  build.RebuildCmd  `cmd:"" help:"Rebuild a build."`
-		Watch    build.WatchCmd    `cmd:"" help:"Watch a build's progress in real-time."`
+		Create       build.CreateCmd       `cmd:"" aliases:"new" help:"Create a new build."` // Aliasing "new" because we've renamed this to "create", but we need to support backwards compatibility
+		Cancel       build.CancelCmd       `cmd:"" help:"Cancel a build, or every build matching a set of filters."`
+		Bisect       build.BisectCmd       `cmd:"" help:"Find the commit that broke a pipeline by building the commits between a good and a bad one."`
+		View         build.ViewCmd         `cmd:"" help:"View build information."`
+		Diff         build.DiffCmd         `cmd:"" help:"Compare two builds side by side."`
+		List         build.ListCmd         `cmd:"" help:"List builds." aliases:"ls"`
+		Download     build.DownloadCmd     `cmd:"" help:"Download resources for a build."`
+		Export       build.ExportCmd       `cmd:"" help:"Export a build to an archive that can be viewed offline."`
+		Failures     build.FailuresCmd     `cmd:"" help:"Report a build's failed jobs and tests."`
+		Grep         build.GrepCmd         `cmd:"" help:"Search a build's job logs."`
+		Timeline     build.TimelineCmd     `cmd:"" help:"Chart when each job in a build waited and ran."`
+		CriticalPath build.CriticalPathCmd `cmd:"" name:"critical-path" help:"Find the chain of jobs that determined how long a build took."`
+		Logs         build.LogsCmd         `cmd:"" help:"Print the merged, timestamp-ordered logs of a build's jobs."`
+		Rebuild      build.RebuildCmd      `cmd:"" help:"Rebuild a build."`
+		RetryFailed  build.RetryFailedCmd  `cmd:"" name:"retry-failed" help:"Retry every failed job in a build."`
+		Stats        build.StatsCmd        `cmd:"" help:"Report pass rates, durations, queue waits and failing steps over a time window."`
+		Watch        build.WatchCmd        `cmd:"" help:"Watch a build's progress in real-time."`
 	}
 	ClusterCmd struct {
 		List   cluster.ListCmd   `cmd:"" help:"List clusters." aliases:"ls"`
@@ -122,6 +136,9 @@ type (
 		Update cluster.UpdateCmd `cmd:"" help:"Update a cluster."`
 		Delete cluster.DeleteCmd `cmd:"" help:"Delete a cluster." aliases:"rm"`
 	}
+	CommitCmd struct {
+		Status commit.StatusCmd `cmd:"" help:"Watch every build for a commit across pipelines."`
+	}
 	ConfigureCmd struct {
 		configure.ConfigureCmd `cmd:"" help:"Configure Buildkite API token"`
 	}
@@ -132,6 +149,7 @@ type (
 		Reprioritize job.ReprioritizeCmd `cmd:"" help:"Reprioritize a job." aliases:"priority"`
 		Retry        job.RetryCmd        `cmd:"" help:"Retry a job."`
 		SSH          job.SSHCmd          `cmd:"" help:"Connect to a running hosted macOS job over SSH."`
+		Stats        job.StatsCmd        `cmd:"" help:"Report job fail rates, durations and queue waits by step over a time window."`
 		Unblock      job.UnblockCmd      `cmd:"" help:"Unblock a job."`
 		VNC          job.VNCCmd          `cmd:"" help:"Connect to a running hosted macOS job over VNC."`
 	}